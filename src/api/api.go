@@ -1,18 +1,47 @@
 package api
 
 import (
+	"fmt"
 	"log"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/eddiarnoldo/my-game-shelf/src/api/handlers"
 	"github.com/eddiarnoldo/my-game-shelf/src/api/middleware"
 	"github.com/eddiarnoldo/my-game-shelf/src/api/router"
 	"github.com/eddiarnoldo/my-game-shelf/src/config"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/backup"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/bgg"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/helpers"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/oidc"
 	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/storage"
 
 	"github.com/gin-gonic/gin"
 )
 
-func InitServer(boardGameRepo repository.BoardGameRepo, imageRepo repository.BoardGameImageRepo) error {
+// Every repository the API needs
+type Repositories struct {
+	BoardGames   repository.BoardGameRepo
+	Images       repository.BoardGameImageRepo
+	PlaySessions repository.PlaySessionRepo
+	Players      repository.PlayerRepo
+	Ratings      repository.RatingRepo
+	Tags         repository.TagRepo
+	Credits      repository.CreditRepo
+	Expansions   repository.ExpansionRepo
+	Imports      repository.ImportRepo
+	Collections  repository.CollectionRepo
+	Backups      repository.BackupRepo
+	Users        repository.UserRepo
+	Households   repository.HouseholdRepo
+	APIKeys      repository.APIKeyRepo
+	Loans        repository.LoanRepo
+	Blobs        storage.BlobStore // Image files, for backups
+}
+
+func InitServer(repos Repositories) error {
 	//Create gin router
 	r := gin.Default()
 
@@ -20,10 +49,71 @@ func InitServer(boardGameRepo repository.BoardGameRepo, imageRepo repository.Boa
 	r.Use(middleware.Cors(allowedOrigins))
 
 	// Initialize handlers
-	boardGameHandler := handlers.NewBoardGameHandler(boardGameRepo, imageRepo)
+	boardGameHandler := handlers.NewBoardGameHandler(repos.BoardGames, repos.Images)
+	playSessionHandler := handlers.NewPlaySessionHandler(repos.PlaySessions)
+	playerHandler := handlers.NewPlayerHandler(repos.Players)
+	leaderboardHandler := handlers.NewLeaderboardHandler(repos.Ratings)
+	tagHandler := handlers.NewTagHandler(repos.Tags)
+	creditHandler := handlers.NewCreditHandler(repos.Credits)
+	expansionHandler := handlers.NewExpansionHandler(repos.Expansions)
+
+	bggImporter := bgg.NewImporter(repos.Imports, repos.Images)
+	importHandler := handlers.NewImportHandler(bggImporter)
+	collectionHandler := handlers.NewCollectionHandler(repos.Collections)
+	backupHandler := handlers.NewBackupHandler(backup.NewArchiver(repos.Backups, repos.Blobs))
+	authHandler := handlers.NewAuthHandler(repos.Users)
+	householdHandler := handlers.NewHouseholdHandler(repos.Households)
+	apiKeyHandler := handlers.NewAPIKeyHandler(repos.APIKeys)
+	loanHandler := handlers.NewLoanHandler(repos.Loans)
+
+	sessionTTL, err := LoadSessionTTL()
+	if err != nil {
+		return err
+	}
+	authHandler.SetSessionTTL(sessionTTL)
+	authHandler.SetSecureCookies(config.GetEnv("COOKIE_SECURE", "false") == "true")
+
+	oidcConfig := LoadOIDCConfig()
+	if oidcConfig.Issuer != "" {
+		provider, err := oidc.NewProvider(oidcConfig)
+		if err != nil {
+			return err
+		}
+		authHandler.SetOIDC(provider, config.GetEnv("OIDC_POST_LOGIN_URL", "/"))
+		log.Printf("Signing in with OpenID Connect provider %s", oidcConfig.Issuer)
+	}
+
+	settings, err := LoadImageSettings()
+	if err != nil {
+		return err
+	}
+	boardGameHandler.SetImageVariantWidths(settings.VariantWidths)
+	boardGameHandler.SetMaxImagePixels(settings.MaxPixels)
+	playerHandler.SetMaxImagePixels(settings.MaxPixels)
+	bggImporter.SetImageVariantWidths(settings.VariantWidths)
+	bggImporter.SetMaxImagePixels(settings.MaxPixels)
 
 	//Setup API routes
-	router.RegisterRoutes(r, boardGameHandler)
+	router.RegisterRoutes(r, router.Handlers{
+		BoardGame:   boardGameHandler,
+		PlaySession: playSessionHandler,
+		Player:      playerHandler,
+		Leaderboard: leaderboardHandler,
+		Tag:         tagHandler,
+		Credit:      creditHandler,
+		Expansion:   expansionHandler,
+		Import:      importHandler,
+		Collection:  collectionHandler,
+		Backup:      backupHandler,
+		Auth:        authHandler,
+		Household:   householdHandler,
+		APIKey:      apiKeyHandler,
+		Loan:        loanHandler,
+
+		Authenticate:           middleware.Authenticate(repos.Users, repos.APIKeys),
+		RequireBoardGame:       middleware.RequireBoardGame(repos.BoardGames),
+		RequireBoardGameEditor: middleware.RequireBoardGameEditor(repos.BoardGames),
+	})
 
 	// Start server
 	port := config.GetEnv("APP_PORT", "8080")
@@ -35,3 +125,61 @@ func InitServer(boardGameRepo repository.BoardGameRepo, imageRepo repository.Boa
 	}
 	return nil
 }
+
+// How uploaded and imported images are processed
+type ImageSettings struct {
+	VariantWidths []int
+	MaxPixels     int
+}
+
+// Reads IMAGE_VARIANT_WIDTHS and IMAGE_MAX_PIXELS, the defaults when unset
+func LoadImageSettings() (ImageSettings, error) {
+	settings := ImageSettings{
+		VariantWidths: helpers.DefaultVariantWidths,
+		MaxPixels:     helpers.DefaultMaxImagePixels,
+	}
+
+	if widths := config.GetEnv("IMAGE_VARIANT_WIDTHS", ""); widths != "" {
+		variantWidths, err := helpers.ParseVariantWidths(widths)
+		if err != nil {
+			return settings, err
+		}
+		settings.VariantWidths = variantWidths
+	}
+
+	if pixels := config.GetEnv("IMAGE_MAX_PIXELS", ""); pixels != "" {
+		maxPixels, err := strconv.Atoi(pixels)
+		if err != nil || maxPixels <= 0 {
+			return settings, fmt.Errorf("invalid IMAGE_MAX_PIXELS %q", pixels)
+		}
+		settings.MaxPixels = maxPixels
+	}
+
+	return settings, nil
+}
+
+// Reads the OIDC_* variables, signing in with a provider is off while
+// OIDC_ISSUER is unset
+func LoadOIDCConfig() oidc.Config {
+	return oidc.Config{
+		Issuer:       config.GetEnv("OIDC_ISSUER", ""),
+		ClientID:     config.GetEnv("OIDC_CLIENT_ID", ""),
+		ClientSecret: config.GetEnv("OIDC_CLIENT_SECRET", ""),
+		RedirectURL:  config.GetEnv("OIDC_REDIRECT_URL", ""),
+		Scopes:       strings.Fields(config.GetEnv("OIDC_SCOPES", "openid email profile")),
+	}
+}
+
+// Reads SESSION_TTL as a Go duration such as 720h, the default when unset
+func LoadSessionTTL() (time.Duration, error) {
+	value := config.GetEnv("SESSION_TTL", "")
+	if value == "" {
+		return handlers.DefaultSessionTTL, nil
+	}
+
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("invalid SESSION_TTL %q", value)
+	}
+	return ttl, nil
+}
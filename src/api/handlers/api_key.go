@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/api/middleware"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/auth"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// API keys of the signed in user
+type APIKeyHandler struct {
+	repo repository.APIKeyRepo
+}
+
+func NewAPIKeyHandler(repo repository.APIKeyRepo) *APIKeyHandler {
+	return &APIKeyHandler{repo: repo}
+}
+
+// The key in the answer is shown once, only its hash is kept
+func (h *APIKeyHandler) HandleCreateAPIKey(c *gin.Context) {
+	var input models.APIKeyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+		return
+	}
+
+	token, tokenHash, hint, err := auth.NewAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	scopes := slices.Clone(input.Scopes)
+	slices.Sort(scopes)
+	key := &models.APIKey{
+		Name:      name,
+		Hint:      hint,
+		Scopes:    slices.Compact(scopes),
+		ExpiresAt: input.ExpiresAt,
+	}
+	if err := h.repo.Create(c.Request.Context(), middleware.UserID(c), key, tokenHash); err != nil {
+		respondAPIKeyError(c, err)
+		return
+	}
+
+	key.Key = token
+	c.JSON(http.StatusCreated, key)
+}
+
+func (h *APIKeyHandler) HandleGetAPIKeys(c *gin.Context) {
+	keys, err := h.repo.GetAll(c.Request.Context(), middleware.UserID(c))
+	if err != nil {
+		respondAPIKeyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+func (h *APIKeyHandler) HandleRevokeAPIKey(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+		return
+	}
+
+	if err := h.repo.Revoke(c.Request.Context(), middleware.UserID(c), id); err != nil {
+		respondAPIKeyError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	c.Writer.WriteHeaderNow()
+}
+
+func respondAPIKeyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
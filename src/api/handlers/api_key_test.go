@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/auth"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandleCreateAPIKey_OK(t *testing.T) {
+	// Arrange
+	repo := &mockAPIKeyRepo{}
+	handler := NewAPIKeyHandler(repo)
+
+	body := `{"name": " Kiosk ", "scopes": ["games:write", "games:read", "games:read"], "expires_at": "2999-01-01T00:00:00Z"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/keys", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleCreateAPIKey(ctx)
+
+	// Assert
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d %s", rec.Code, rec.Body)
+	}
+
+	var key models.APIKey
+	if err := json.Unmarshal(rec.Body.Bytes(), &key); err != nil {
+		t.Fatalf("failed to unmarshal response JSON: %v", err)
+	}
+	if !auth.IsAPIKey(key.Key) || repo.keyHash != auth.HashToken(key.Key) {
+		t.Errorf("expected the key to be stored by its hash, got %q", key.Key)
+	}
+	if !strings.HasPrefix(key.Key, key.Hint) {
+		t.Errorf("expected the hint to be the start of the key, got %q", key.Hint)
+	}
+	if repo.userID != testUser.ID || repo.created.Name != "Kiosk" {
+		t.Errorf("expected a trimmed key for user %d, got %q for %d", testUser.ID, repo.created.Name, repo.userID)
+	}
+	if len(key.Scopes) != 2 || key.Scopes[0] != models.ScopeGamesRead || key.Scopes[1] != models.ScopeGamesWrite {
+		t.Errorf("expected each scope once, got %v", key.Scopes)
+	}
+}
+
+func TestHandleCreateAPIKey_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"no scopes", `{"name": "Kiosk", "scopes": []}`},
+		{"unknown scope", `{"name": "Kiosk", "scopes": ["players:write"]}`},
+		{"blank name", `{"name": "  ", "scopes": ["games:read"]}`},
+		{"expired", `{"name": "Kiosk", "scopes": ["games:read"], "expires_at": "2001-01-01T00:00:00Z"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mockAPIKeyRepo{}
+			handler := NewAPIKeyHandler(repo)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/keys", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			ctx, rec := createTestContext(req)
+
+			// Act
+			handler.HandleCreateAPIKey(ctx)
+
+			// Assert
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", rec.Code)
+			}
+			if repo.created != nil {
+				t.Error("expected no key to be created")
+			}
+		})
+	}
+}
+
+func TestHandleRevokeAPIKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"revoked", nil, http.StatusNoContent},
+		{"key of someone else", repository.ErrAPIKeyNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mockAPIKeyRepo{err: tt.err}
+			handler := NewAPIKeyHandler(repo)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/keys/5", nil)
+			ctx, rec := createTestContext(req)
+			ctx.Params = gin.Params{{Key: "id", Value: "5"}}
+
+			// Act
+			handler.HandleRevokeAPIKey(ctx)
+
+			// Assert
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, rec.Code)
+			}
+			if repo.userID != testUser.ID || repo.revokedID != 5 {
+				t.Errorf("expected key 5 of user %d to be revoked, got %d of %d", testUser.ID, repo.revokedID, repo.userID)
+			}
+		})
+	}
+}
+
+// Records the arguments, every call fails with err when set
+type mockAPIKeyRepo struct {
+	err error
+
+	userID    int64
+	keyHash   string
+	created   *models.APIKey
+	revokedID int64
+}
+
+func (m *mockAPIKeyRepo) Create(ctx context.Context, userID int64, key *models.APIKey, keyHash string) error {
+	m.userID, m.created, m.keyHash = userID, key, keyHash
+	if m.err != nil {
+		return m.err
+	}
+	key.ID = 1
+	return nil
+}
+
+func (m *mockAPIKeyRepo) GetAll(ctx context.Context, userID int64) ([]*models.APIKey, error) {
+	m.userID = userID
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []*models.APIKey{}, nil
+}
+
+func (m *mockAPIKeyRepo) Revoke(ctx context.Context, userID int64, id int64) error {
+	m.userID, m.revokedID = userID, id
+	return m.err
+}
+
+func (m *mockAPIKeyRepo) GetAPIKeyUser(ctx context.Context, keyHash string) (*models.User, *models.APIKey, error) {
+	m.keyHash = keyHash
+	return nil, nil, repository.ErrAPIKeyNotFound
+}
@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/api/middleware"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/auth"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/oidc"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// How long a session lasts when SESSION_TTL is not set
+const DefaultSessionTTL = 30 * 24 * time.Hour
+
+// How long a sign in with the identity provider may take
+const OIDCLoginTTL = 10 * time.Minute
+
+// Ties a sign in with the identity provider to the browser that started it
+const oidcStateCookie = "oidc_state"
+
+// Signs users in with an OpenID Connect provider, *oidc.Provider does
+type OIDCProvider interface {
+	AuthCodeURL(ctx context.Context, state, nonce, challenge string) (string, error)
+	Exchange(ctx context.Context, code, verifier, nonce string) (*models.Identity, error)
+}
+
+// Registration, signing in and out
+type AuthHandler struct {
+	repo           repository.UserRepo
+	sessionTTL     time.Duration
+	secureCookies  bool
+	passwordParams auth.Params
+
+	dummyHashOnce sync.Once
+	dummyHash     string
+
+	oidc         OIDCProvider
+	postLoginURL string
+}
+
+func NewAuthHandler(repo repository.UserRepo) *AuthHandler {
+	return &AuthHandler{repo: repo, sessionTTL: DefaultSessionTTL, passwordParams: auth.DefaultParams}
+}
+
+func (h *AuthHandler) SetSessionTTL(ttl time.Duration) {
+	h.sessionTTL = ttl
+}
+
+// Secure cookies are only sent over HTTPS, turn on behind TLS
+func (h *AuthHandler) SetSecureCookies(secure bool) {
+	h.secureCookies = secure
+}
+
+// Turns on signing in with the provider. Browsers land on postLoginURL once
+// signed in.
+func (h *AuthHandler) SetOIDC(provider OIDCProvider, postLoginURL string) {
+	h.oidc = provider
+	h.postLoginURL = postLoginURL
+}
+
+// The signed in user and the token to send as Authorization: Bearer.
+// Browsers can ignore the token, it is also set as the session cookie.
+type sessionResponse struct {
+	User      *models.User `json:"user"`
+	Token     string       `json:"token"`
+	ExpiresAt time.Time    `json:"expires_at"`
+}
+
+// Creates the account and signs it in. The first account is the admin.
+func (h *AuthHandler) HandleRegister(c *gin.Context) {
+	var registration models.Registration
+	if err := c.ShouldBindJSON(&registration); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hash, err := auth.HashPasswordWith(registration.Password, h.passwordParams)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	user := &models.User{
+		Email:        registration.Email,
+		DisplayName:  strings.TrimSpace(registration.DisplayName),
+		PasswordHash: hash,
+	}
+	if err := h.repo.Create(c.Request.Context(), user); err != nil {
+		respondAuthError(c, err)
+		return
+	}
+
+	h.startSession(c, http.StatusCreated, user)
+}
+
+// Signs in with email and password. Unknown emails and wrong passwords get
+// the same answer, in about the same time.
+func (h *AuthHandler) HandleLogin(c *gin.Context) {
+	var credentials models.Credentials
+	if err := c.ShouldBindJSON(&credentials); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.repo.GetByEmail(c.Request.Context(), strings.TrimSpace(credentials.Email))
+	if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+		respondAuthError(c, err)
+		return
+	}
+
+	hash := h.unknownUserHash()
+	if user != nil {
+		hash = user.PasswordHash
+	}
+	ok, err := auth.VerifyPassword(credentials.Password, hash)
+	if err != nil || !ok || user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		return
+	}
+
+	h.startSession(c, http.StatusOK, user)
+}
+
+// Ends the current session, the token stops working right away
+func (h *AuthHandler) HandleLogout(c *gin.Context) {
+	token := middleware.SessionToken(c)
+	err := h.repo.DeleteSession(c.Request.Context(), auth.HashToken(token))
+	if err != nil && !errors.Is(err, repository.ErrSessionNotFound) {
+		respondAuthError(c, err)
+		return
+	}
+
+	h.setSessionCookie(c, "", -1)
+	c.Status(http.StatusNoContent)
+	c.Writer.WriteHeaderNow()
+}
+
+// The signed in user
+func (h *AuthHandler) HandleMe(c *gin.Context) {
+	c.JSON(http.StatusOK, middleware.CurrentUser(c))
+}
+
+func (h *AuthHandler) startSession(c *gin.Context, status int, user *models.User) {
+	token, session, ok := h.newSession(c, user)
+	if !ok {
+		return
+	}
+
+	c.JSON(status, sessionResponse{User: user, Token: token, ExpiresAt: session.ExpiresAt})
+}
+
+// Creates the session and sets its cookie, answers the error itself when it fails
+func (h *AuthHandler) newSession(c *gin.Context, user *models.User) (string, *models.Session, bool) {
+	token, tokenHash, err := auth.NewToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return "", nil, false
+	}
+
+	session, err := h.repo.CreateSession(c.Request.Context(), user.ID, tokenHash, h.sessionTTL)
+	if err != nil {
+		respondAuthError(c, err)
+		return "", nil, false
+	}
+
+	h.setSessionCookie(c, token, int(h.sessionTTL.Seconds()))
+	return token, session, true
+}
+
+// How users can sign in, for the sign in page to only offer what works
+func (h *AuthHandler) HandleProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"password": true, "oidc": h.oidc != nil})
+}
+
+// Sends the browser to the identity provider, with PKCE. The provider sends
+// it back to HandleOIDCCallback.
+func (h *AuthHandler) HandleOIDCLogin(c *gin.Context) {
+	if h.oidc == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sign in with an identity provider is not configured"})
+		return
+	}
+
+	state, stateHash, err := auth.NewToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	nonce, _, err := auth.NewToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	login := &models.OIDCLogin{StateHash: stateHash, CodeVerifier: verifier, Nonce: nonce}
+	if err := h.repo.CreateOIDCLogin(c.Request.Context(), login, OIDCLoginTTL); err != nil {
+		respondAuthError(c, err)
+		return
+	}
+
+	authURL, err := h.oidc.AuthCodeURL(c.Request.Context(), state, nonce, challenge)
+	if err != nil {
+		log.Printf("OIDC sign in failed: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Identity provider unavailable"})
+		return
+	}
+
+	h.setStateCookie(c, state, int(OIDCLoginTTL.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Where the identity provider sends the browser back. Signs in as the user of
+// the identity, creating them on their first sign in, then goes on to the app.
+func (h *AuthHandler) HandleOIDCCallback(c *gin.Context) {
+	if h.oidc == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sign in with an identity provider is not configured"})
+		return
+	}
+
+	if reason := c.Query("error"); reason != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "The identity provider refused the sign in: " + reason})
+		return
+	}
+
+	// The state must come back to the browser it was given to, no one else's sign in
+	state := c.Query("state")
+	cookie, err := c.Cookie(oidcStateCookie)
+	h.setStateCookie(c, "", -1)
+	if state == "" || err != nil || subtle.ConstantTimeCompare([]byte(state), []byte(cookie)) != 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Sign in was not started from this browser, start again"})
+		return
+	}
+
+	login, err := h.repo.TakeOIDCLogin(c.Request.Context(), auth.HashToken(state))
+	if err != nil {
+		respondAuthError(c, err)
+		return
+	}
+
+	identity, err := h.oidc.Exchange(c.Request.Context(), c.Query("code"), login.CodeVerifier, login.Nonce)
+	if err != nil {
+		log.Printf("OIDC sign in failed: %v", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Sign in with the identity provider failed"})
+		return
+	}
+
+	user, err := h.repo.SignInIdentity(c.Request.Context(), identity)
+	if err != nil {
+		respondAuthError(c, err)
+		return
+	}
+
+	if _, _, ok := h.newSession(c, user); !ok {
+		return
+	}
+	c.Redirect(http.StatusFound, h.postLoginURL)
+}
+
+// HttpOnly, scripts never see the token. Lax still sends it on links to the app.
+func (h *AuthHandler) setSessionCookie(c *gin.Context, token string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(middleware.SessionCookie, token, maxAge, "/", "", h.secureCookies, true)
+}
+
+// Only sent back to the callback. Lax, the provider sends the browser back
+// with a top level navigation.
+func (h *AuthHandler) setStateCookie(c *gin.Context, state string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, maxAge, "/api/auth/oidc", "", h.secureCookies, true)
+}
+
+// Checked against when the email is unknown, so the answer takes as long
+// as for a wrong password
+func (h *AuthHandler) unknownUserHash() string {
+	h.dummyHashOnce.Do(func() {
+		h.dummyHash, _ = auth.HashPasswordWith("not a password", h.passwordParams)
+	})
+	return h.dummyHash
+}
+
+func respondAuthError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrDuplicateEmail), errors.Is(err, repository.ErrIdentityNotLinked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrOIDCLoginNotFound), errors.Is(err, repository.ErrIdentityEmailRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
@@ -0,0 +1,493 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/api/middleware"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/auth"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/oidc"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/oidc/oidctest"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+)
+
+// Cheap hashing, the real cost only slows the tests down
+func newTestAuthHandler(repo *mockUserRepo) *AuthHandler {
+	handler := NewAuthHandler(repo)
+	handler.passwordParams = auth.Params{Memory: 1024, Time: 1, Threads: 1, SaltLen: 16, KeyLen: 32}
+	return handler
+}
+
+func sessionCookie(rec *httptest.ResponseRecorder) *http.Cookie {
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == middleware.SessionCookie {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestHandleRegister_OK(t *testing.T) {
+	// Arrange
+	repo := &mockUserRepo{}
+	handler := newTestAuthHandler(repo)
+
+	body := `{"email": "ada@example.com", "password": "correct horse", "display_name": "Ada"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/register", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleRegister(ctx)
+
+	// Assert
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d %s", rec.Code, rec.Body)
+	}
+
+	if ok, err := auth.VerifyPassword("correct horse", repo.created.PasswordHash); err != nil || !ok {
+		t.Errorf("expected the password to be stored hashed, got %q", repo.created.PasswordHash)
+	}
+
+	var response sessionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response JSON: %v", err)
+	}
+	if response.Token == "" || repo.sessionHash != auth.HashToken(response.Token) {
+		t.Errorf("expected the session to be stored by the hash of the token")
+	}
+	if strings.Contains(rec.Body.String(), "argon2id") {
+		t.Error("expected the password hash to stay out of the response")
+	}
+
+	cookie := sessionCookie(rec)
+	if cookie == nil || cookie.Value != response.Token || !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode {
+		t.Errorf("expected an HttpOnly SameSite=Lax session cookie, got %+v", cookie)
+	}
+}
+
+func TestHandleRegister_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		err    error
+		status int
+	}{
+		{"short password", `{"email": "ada@example.com", "password": "short", "display_name": "Ada"}`, nil, http.StatusBadRequest},
+		{"not an email", `{"email": "ada", "password": "correct horse", "display_name": "Ada"}`, nil, http.StatusBadRequest},
+		{"taken email", `{"email": "ada@example.com", "password": "correct horse", "display_name": "Ada"}`,
+			repository.ErrDuplicateEmail, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mockUserRepo{err: tt.err}
+			handler := newTestAuthHandler(repo)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/register", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			ctx, rec := createTestContext(req)
+
+			// Act
+			handler.HandleRegister(ctx)
+
+			// Assert
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, rec.Code)
+			}
+			if repo.sessionHash != "" {
+				t.Error("expected no session to be created")
+			}
+		})
+	}
+}
+
+func TestHandleLogin(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		status   int
+	}{
+		{"right password", "ADA@example.com", "correct horse", http.StatusOK},
+		{"wrong password", "ada@example.com", "wrong horse", http.StatusUnauthorized},
+		{"unknown email", "bob@example.com", "correct horse", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mockUserRepo{}
+			handler := newTestAuthHandler(repo)
+			hash, _ := auth.HashPasswordWith("correct horse", handler.passwordParams)
+			repo.users = []*models.User{{ID: 7, Email: "ada@example.com", DisplayName: "Ada", PasswordHash: hash}}
+
+			body := `{"email": "` + tt.email + `", "password": "` + tt.password + `"}`
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			ctx, rec := createTestContext(req)
+
+			// Act
+			handler.HandleLogin(ctx)
+
+			// Assert
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d %s", tt.status, rec.Code, rec.Body)
+			}
+
+			signedIn := tt.status == http.StatusOK
+			if (repo.sessionUserID == 7) != signedIn || (sessionCookie(rec) != nil) != signedIn {
+				t.Errorf("expected a session only for the right password, got user %d", repo.sessionUserID)
+			}
+		})
+	}
+}
+
+func TestHandleLogout(t *testing.T) {
+	// Arrange
+	repo := &mockUserRepo{}
+	handler := newTestAuthHandler(repo)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/logout", nil)
+	req.AddCookie(&http.Cookie{Name: middleware.SessionCookie, Value: "some-token"})
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleLogout(ctx)
+
+	// Assert
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", rec.Code)
+	}
+
+	if repo.deletedHash != auth.HashToken("some-token") {
+		t.Errorf("expected the session of the cookie to be deleted, got %q", repo.deletedHash)
+	}
+
+	if cookie := sessionCookie(rec); cookie == nil || cookie.MaxAge >= 0 {
+		t.Errorf("expected the cookie to be cleared, got %+v", cookie)
+	}
+}
+
+func TestHandleMe(t *testing.T) {
+	// Arrange
+	handler := newTestAuthHandler(&mockUserRepo{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/me", nil)
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleMe(ctx)
+
+	// Assert
+	var user models.User
+	if err := json.Unmarshal(rec.Body.Bytes(), &user); err != nil {
+		t.Fatalf("failed to unmarshal response JSON: %v", err)
+	}
+
+	if rec.Code != http.StatusOK || user.ID != testUser.ID {
+		t.Errorf("expected the signed in user, got %d %+v", rec.Code, user)
+	}
+}
+
+func TestHandleProviders(t *testing.T) {
+	tests := []struct {
+		name         string
+		provider     OIDCProvider
+		expectedOIDC bool
+	}{
+		{"password only", nil, false},
+		{"with a provider", rejectingProvider{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := newTestAuthHandler(&mockUserRepo{})
+			if tt.provider != nil {
+				handler.SetOIDC(tt.provider, "/")
+			}
+			ctx, rec := createTestContext(httptest.NewRequest(http.MethodGet, "/api/auth/providers", nil))
+
+			// Act
+			handler.HandleProviders(ctx)
+
+			// Assert
+			var providers struct {
+				Password bool `json:"password"`
+				OIDC     bool `json:"oidc"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &providers); err != nil {
+				t.Fatalf("failed to unmarshal response JSON: %v", err)
+			}
+
+			if rec.Code != http.StatusOK || !providers.Password || providers.OIDC != tt.expectedOIDC {
+				t.Errorf("expected password and oidc %v, got %d %+v", tt.expectedOIDC, rec.Code, providers)
+			}
+		})
+	}
+}
+
+func TestHandleOIDC_SignIn(t *testing.T) {
+	// Arrange
+	idp := oidctest.NewServer(t)
+	provider, err := oidc.NewProvider(oidc.Config{
+		Issuer:       idp.URL,
+		ClientID:     oidctest.ClientID,
+		ClientSecret: oidctest.ClientSecret,
+		RedirectURL:  oidctest.RedirectURL,
+	})
+	if err != nil {
+		t.Fatalf("NewProvider() failed: %v", err)
+	}
+
+	repo := &mockUserRepo{}
+	handler := newTestAuthHandler(repo)
+	handler.SetOIDC(provider, "/shelf")
+
+	// Act: the app sends the browser to the provider...
+	ctx, rec := createTestContext(httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login", nil))
+	handler.HandleOIDCLogin(ctx)
+	if rec.Code != http.StatusFound {
+		t.Fatalf("expected a redirect to the provider, got %d %s", rec.Code, rec.Body)
+	}
+	var stateCookie *http.Cookie
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == oidcStateCookie {
+			stateCookie = cookie
+		}
+	}
+	if stateCookie == nil || !stateCookie.HttpOnly {
+		t.Fatalf("expected an HttpOnly state cookie, got %+v", stateCookie)
+	}
+
+	// ...which signs in and sends it back with a code
+	callback := idp.Authorize(t, rec.Header().Get("Location"))
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/callback?"+callback.RawQuery, nil)
+	req.AddCookie(stateCookie)
+	ctx, rec = createTestContext(req)
+	handler.HandleOIDCCallback(ctx)
+
+	// Assert
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/shelf" {
+		t.Fatalf("expected a redirect to /shelf, got %d %s", rec.Code, rec.Body)
+	}
+	if repo.identity == nil || repo.identity.Subject != "user-1" || repo.identity.Email != "ada@example.com" {
+		t.Errorf("expected user-1 of the provider to sign in, got %+v", repo.identity)
+	}
+	if cookie := sessionCookie(rec); cookie == nil || repo.sessionHash != auth.HashToken(cookie.Value) {
+		t.Errorf("expected a session cookie for the new session, got %+v", cookie)
+	}
+	if len(repo.logins) != 0 {
+		t.Errorf("expected the sign in to be used up, %d left", len(repo.logins))
+	}
+}
+
+func TestHandleOIDC_PasswordAccountNotLinked(t *testing.T) {
+	// Arrange: someone registered the provider's email with a password first
+	idp := oidctest.NewServer(t)
+	provider, err := oidc.NewProvider(oidc.Config{
+		Issuer:       idp.URL,
+		ClientID:     oidctest.ClientID,
+		ClientSecret: oidctest.ClientSecret,
+		RedirectURL:  oidctest.RedirectURL,
+	})
+	if err != nil {
+		t.Fatalf("NewProvider() failed: %v", err)
+	}
+
+	repo := &mockUserRepo{users: []*models.User{{ID: 1, Email: "ada@example.com", PasswordHash: "hash"}}}
+	handler := newTestAuthHandler(repo)
+	handler.SetOIDC(provider, "/shelf")
+
+	ctx, rec := createTestContext(httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login", nil))
+	handler.HandleOIDCLogin(ctx)
+	var stateCookie *http.Cookie
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == oidcStateCookie {
+			stateCookie = cookie
+		}
+	}
+	if stateCookie == nil {
+		t.Fatal("expected a state cookie")
+	}
+
+	callback := idp.Authorize(t, rec.Header().Get("Location"))
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/callback?"+callback.RawQuery, nil)
+	req.AddCookie(stateCookie)
+	ctx, rec = createTestContext(req)
+
+	// Act
+	handler.HandleOIDCCallback(ctx)
+
+	// Assert
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d %s", rec.Code, rec.Body)
+	}
+	if sessionCookie(rec) != nil || repo.sessionUserID != 0 {
+		t.Error("expected nobody to be signed in")
+	}
+}
+
+func TestHandleOIDCCallback_Rejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		cookie string
+		status int
+	}{
+		{"refused by the provider", "error=access_denied&state=s1", "s1", http.StatusUnauthorized},
+		{"no state cookie", "code=c&state=s1", "", http.StatusBadRequest},
+		{"state of another browser", "code=c&state=s1", "s2", http.StatusBadRequest},
+		{"expired or used sign in", "code=c&state=s2", "s2", http.StatusBadRequest},
+		{"code the provider rejects", "code=bad&state=s1", "s1", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mockUserRepo{logins: map[string]*models.OIDCLogin{
+				auth.HashToken("s1"): {CodeVerifier: "v", Nonce: "n"},
+			}}
+			handler := newTestAuthHandler(repo)
+			handler.SetOIDC(rejectingProvider{}, "/")
+
+			req := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/callback?"+tt.query, nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: tt.cookie})
+			}
+			ctx, rec := createTestContext(req)
+
+			// Act
+			handler.HandleOIDCCallback(ctx)
+
+			// Assert
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d %s", tt.status, rec.Code, rec.Body)
+			}
+			if repo.identity != nil || sessionCookie(rec) != nil {
+				t.Error("expected nobody to be signed in")
+			}
+		})
+	}
+}
+
+func TestHandleOIDCLogin_NotConfigured(t *testing.T) {
+	// Arrange
+	handler := newTestAuthHandler(&mockUserRepo{})
+	ctx, rec := createTestContext(httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login", nil))
+
+	// Act
+	handler.HandleOIDCLogin(ctx)
+
+	// Assert
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+}
+
+// Rejects every code, like a provider that never issued it
+type rejectingProvider struct{}
+
+func (rejectingProvider) AuthCodeURL(ctx context.Context, state, nonce, challenge string) (string, error) {
+	return "https://idp.example.com/authorize", nil
+}
+
+func (rejectingProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*models.Identity, error) {
+	return nil, oidc.ErrExchange
+}
+
+// Users by email, sessions are only recorded
+type mockUserRepo struct {
+	err   error
+	users []*models.User
+
+	created       *models.User
+	sessionUserID int64
+	sessionHash   string
+	deletedHash   string
+
+	logins   map[string]*models.OIDCLogin // By state hash
+	identity *models.Identity
+}
+
+func (m *mockUserRepo) Create(ctx context.Context, user *models.User) error {
+	if m.err != nil {
+		return m.err
+	}
+	user.ID = int64(len(m.users) + 1)
+	m.created = user
+	m.users = append(m.users, user)
+	return nil
+}
+
+func (m *mockUserRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	for _, user := range m.users {
+		if strings.EqualFold(user.Email, email) {
+			return user, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (m *mockUserRepo) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	for _, user := range m.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (m *mockUserRepo) CreateSession(ctx context.Context, userID int64, tokenHash string, ttl time.Duration) (*models.Session, error) {
+	m.sessionUserID = userID
+	m.sessionHash = tokenHash
+	return &models.Session{UserID: userID, TokenHash: tokenHash, ExpiresAt: time.Now().Add(ttl)}, nil
+}
+
+func (m *mockUserRepo) GetSessionUser(ctx context.Context, tokenHash string) (*models.User, error) {
+	return nil, repository.ErrSessionNotFound
+}
+
+func (m *mockUserRepo) DeleteSession(ctx context.Context, tokenHash string) error {
+	m.deletedHash = tokenHash
+	return nil
+}
+
+func (m *mockUserRepo) SignInIdentity(ctx context.Context, identity *models.Identity) (*models.User, error) {
+	m.identity = identity
+	if user, err := m.GetByEmail(ctx, identity.Email); err == nil {
+		// Accounts registered with a password are never linked
+		if user.PasswordHash != "" {
+			return nil, repository.ErrIdentityNotLinked
+		}
+		return user, nil
+	}
+	user := &models.User{Email: identity.Email, DisplayName: identity.Name}
+	return user, m.Create(ctx, user)
+}
+
+func (m *mockUserRepo) CreateOIDCLogin(ctx context.Context, login *models.OIDCLogin, ttl time.Duration) error {
+	if m.logins == nil {
+		m.logins = map[string]*models.OIDCLogin{}
+	}
+	login.ExpiresAt = time.Now().Add(ttl)
+	m.logins[login.StateHash] = login
+	return nil
+}
+
+func (m *mockUserRepo) TakeOIDCLogin(ctx context.Context, stateHash string) (*models.OIDCLogin, error) {
+	login, ok := m.logins[stateHash]
+	if !ok {
+		return nil, repository.ErrOIDCLoginNotFound
+	}
+	delete(m.logins, stateHash)
+	return login, nil
+}
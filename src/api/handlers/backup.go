@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/backup"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+type BackupHandler struct {
+	archiver *backup.Archiver
+}
+
+func NewBackupHandler(archiver *backup.Archiver) *BackupHandler {
+	return &BackupHandler{archiver: archiver}
+}
+
+// Streams a tar.gz of every table and image, restored with the restore command
+func (h *BackupHandler) HandleBackup(c *gin.Context) {
+	filename := fmt.Sprintf("my-game-shelf-backup-%s.tar.gz", time.Now().Format("2006-01-02"))
+	c.Header("Content-Type", "application/gzip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	if _, err := h.archiver.Write(c.Request.Context(), c.Writer); err != nil {
+		// Once the archive started the status is sent, cutting it short is all that is left
+		if c.Writer.Written() {
+			log.Printf("Backup failed mid-stream: %v", err)
+			c.Abort()
+			return
+		}
+
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		if errors.Is(err, repository.ErrLegacyImages) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/backup"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/storage"
+)
+
+func TestHandleBackup(t *testing.T) {
+	// Arrange
+	store, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	repo := &mockBackupRepo{
+		tables: []*models.TableDump{{Name: "board_games", Rows: 1, Data: []byte(`{"id":1}` + "\n")}},
+	}
+	handler := NewBackupHandler(backup.NewArchiver(repo, store))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/backup", nil)
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleBackup(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	if rec.Header().Get("Content-Type") != "application/gzip" {
+		t.Errorf("expected a gzip file, got %q", rec.Header().Get("Content-Type"))
+	}
+
+	compressed, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("expected a gzip body: %v", err)
+	}
+	archive := tar.NewReader(compressed)
+	var files []string
+	for {
+		header, err := archive.Next()
+		if err != nil {
+			break
+		}
+		files = append(files, header.Name)
+	}
+
+	if len(files) != 2 || files[0] != "tables/board_games.jsonl" || files[1] != "manifest.json" {
+		t.Errorf("expected the table then the manifest, got %v", files)
+	}
+}
+
+func TestHandleBackup_LegacyImages(t *testing.T) {
+	// Arrange
+	store, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	repo := &mockBackupRepo{err: repository.ErrLegacyImages}
+	handler := NewBackupHandler(backup.NewArchiver(repo, store))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/backup", nil)
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleBackup(ctx)
+
+	// Assert
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", rec.Code)
+	}
+
+	if rec.Header().Get("Content-Disposition") != "" {
+		t.Error("expected no attachment for an error")
+	}
+}
+
+type mockBackupRepo struct {
+	err    error
+	tables []*models.TableDump
+}
+
+func (m *mockBackupRepo) Dump(ctx context.Context, table func(dump *models.TableDump) error) (*models.DatabaseDump, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	for _, dump := range m.tables {
+		if err := table(dump); err != nil {
+			return nil, err
+		}
+	}
+	return &models.DatabaseDump{MigrationVersion: 13, BlobKeys: []string{}}, nil
+}
+
+func (m *mockBackupRepo) CheckRestorable(ctx context.Context, migrationVersion int64) error {
+	return m.err
+}
+
+func (m *mockBackupRepo) Restore(ctx context.Context, migrationVersion int64, tables []*models.TableDump) error {
+	return m.err
+}
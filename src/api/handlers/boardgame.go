@@ -1,27 +1,41 @@
 package handlers
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/eddiarnoldo/my-game-shelf/src/api/middleware"
 	"github.com/eddiarnoldo/my-game-shelf/src/internal/helpers"
 	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
 	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 type BoardGameHandler struct {
-	repo      repository.BoardGameRepo
-	imageRepo repository.BoardGameImageRepo
+	repo           repository.BoardGameRepo
+	imageRepo      repository.BoardGameImageRepo
+	variantWidths  []int
+	maxImagePixels int
 }
 
 // Use this function to create a new BoardGameHandler
 func NewBoardGameHandler(repo repository.BoardGameRepo, imageRepo repository.BoardGameImageRepo) *BoardGameHandler {
-	return &BoardGameHandler{repo: repo, imageRepo: imageRepo}
+	return &BoardGameHandler{repo: repo, imageRepo: imageRepo, variantWidths: helpers.DefaultVariantWidths, maxImagePixels: helpers.DefaultMaxImagePixels}
+}
+
+// Widths of the resized copies generated at upload, an empty list turns them off
+func (h *BoardGameHandler) SetImageVariantWidths(widths []int) {
+	h.variantWidths = widths
+}
+
+// Uploads with more pixels than this are rejected before they get decoded
+func (h *BoardGameHandler) SetMaxImagePixels(pixels int) {
+	h.maxImagePixels = pixels
 }
 
 // This is the function that will handle the creation of a new board game
@@ -33,7 +47,20 @@ func (h *BoardGameHandler) HandleBoardGameCreate(c *gin.Context) {
 		return
 	}
 
-	if err := h.repo.Create(c.Request.Context(), &game); err != nil {
+	if err := validatePlayerRange(&game); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.repo.Create(c.Request.Context(), middleware.UserID(c), &game); err != nil {
+		if errors.Is(err, repository.ErrHouseholdNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, repository.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		fmt.Println(err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create board game"})
 		return
@@ -42,15 +69,183 @@ func (h *BoardGameHandler) HandleBoardGameCreate(c *gin.Context) {
 	c.JSON(http.StatusCreated, game)
 }
 
+// Lists board games. Supports filtering (players, max_play_time, age, name, designer, publisher,
+// tags with tag_match all or any), sorting (sort, order) and cursor pagination (limit, cursor).
+// The body stays a plain array, the total and next cursor are sent as headers.
 func (h *BoardGameHandler) HandleGetBoardGames(c *gin.Context) {
-	boardGames, err := h.repo.GetAll(c.Request.Context())
+	filter, err := parseBoardGameFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.repo.GetAll(c.Request.Context(), middleware.UserID(c), filter)
+
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidSort) || errors.Is(err, repository.ErrInvalidCursor) ||
+			errors.Is(err, repository.ErrInvalidTagMatch) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.Itoa(page.Total))
+	if page.Next != "" {
+		c.Header("X-Next-Cursor", page.Next)
+	}
+
+	c.JSON(http.StatusOK, page.Games)
+}
+
+func parseBoardGameFilter(c *gin.Context) (models.BoardGameFilter, error) {
+	filter := models.BoardGameFilter{
+		NamePrefix: c.Query("name"),
+		Sort:       c.Query("sort"),
+		Order:      c.Query("order"),
+		Cursor:     c.Query("cursor"),
+		Tags:       splitQueryList(c.QueryArray("tags")),
+		TagMatch:   c.Query("tag_match"),
+		Designer:   c.Query("designer"),
+		Publisher:  c.Query("publisher"),
+	}
+
+	numbers := []struct {
+		param string
+		value *int
+	}{
+		{"players", &filter.Players},
+		{"max_play_time", &filter.MaxPlayTime},
+		{"age", &filter.Age},
+		{"limit", &filter.Limit},
+	}
+
+	for _, number := range numbers {
+		raw := c.Query(number.param)
+		if raw == "" {
+			continue
+		}
+
+		value, err := strconv.Atoi(raw)
+		if err != nil || value <= 0 {
+			return filter, fmt.Errorf("Invalid %s: must be a positive number", number.param)
+		}
+		*number.value = value
+	}
+
+	if raw := c.Query("on_shelf"); raw != "" {
+		onShelf, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid on_shelf: must be true or false")
+		}
+		filter.OnShelf = &onShelf
+	}
+
+	return filter, nil
+}
+
+// Full text search with typo tolerance: /api/boardgames/search?q=catan&limit=10
+func (h *BoardGameHandler) HandleSearchBoardGames(c *gin.Context) {
+	text := strings.TrimSpace(c.Query("q"))
+	if text == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing search query 'q'"})
+		return
+	}
 
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit: must be a positive number"})
+			return
+		}
+		limit = value
+	}
+
+	results, err := h.repo.Search(c.Request.Context(), middleware.UserID(c), text, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
 		return
 	}
 
-	c.JSON(http.StatusOK, boardGames)
+	c.JSON(http.StatusOK, results)
+}
+
+// Ranks the games that fit tonight:
+// /api/recommendations/tonight?players=5&minutes=60&age=8&tags=party,cards&exclude=3,7
+func (h *BoardGameHandler) HandleRecommendTonight(c *gin.Context) {
+	criteria, err := parseRecommendationCriteria(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recommendations, err := h.repo.Recommend(c.Request.Context(), middleware.UserID(c), criteria)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, recommendations)
+}
+
+// Lists can be repeated (tags=a&tags=b) or comma separated (tags=a,b)
+func parseRecommendationCriteria(c *gin.Context) (models.RecommendationCriteria, error) {
+	var criteria models.RecommendationCriteria
+	if c.Query("players") == "" {
+		return criteria, fmt.Errorf("Missing players")
+	}
+
+	numbers := []struct {
+		param string
+		value *int
+	}{
+		{"players", &criteria.Players},
+		{"minutes", &criteria.Minutes},
+		{"age", &criteria.Age},
+		{"limit", &criteria.Limit},
+	}
+
+	for _, number := range numbers {
+		raw := c.Query(number.param)
+		if raw == "" {
+			continue
+		}
+
+		value, err := strconv.Atoi(raw)
+		if err != nil || value <= 0 {
+			return criteria, fmt.Errorf("Invalid %s: must be a positive number", number.param)
+		}
+		*number.value = value
+	}
+
+	for _, tag := range splitQueryList(c.QueryArray("tags")) {
+		criteria.Tags = append(criteria.Tags, strings.ToLower(tag))
+	}
+
+	for _, raw := range splitQueryList(c.QueryArray("exclude")) {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return criteria, fmt.Errorf("Invalid exclude: %q is not a board game ID", raw)
+		}
+		criteria.ExcludeIDs = append(criteria.ExcludeIDs, id)
+	}
+
+	return criteria, nil
+}
+
+func splitQueryList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
 }
 
 func (h *BoardGameHandler) HandleGetBoardGameByID(c *gin.Context) {
@@ -63,7 +258,7 @@ func (h *BoardGameHandler) HandleGetBoardGameByID(c *gin.Context) {
 		return
 	}
 
-	game, err := h.repo.GetByID(c.Request.Context(), id)
+	game, err := h.repo.GetByID(c.Request.Context(), middleware.UserID(c), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Board game not found"})
 		return
@@ -72,6 +267,8 @@ func (h *BoardGameHandler) HandleGetBoardGameByID(c *gin.Context) {
 	c.JSON(http.StatusOK, game)
 }
 
+// Refuses to delete a game that has expansions, listing them, unless
+// ?with_expansions=true deletes them along with it
 func (h *BoardGameHandler) HandleBoardGameDelete(c *gin.Context) {
 	idParam := c.Param("id")
 
@@ -81,12 +278,20 @@ func (h *BoardGameHandler) HandleBoardGameDelete(c *gin.Context) {
 		return
 	}
 
-	err = h.repo.Delete(c.Request.Context(), id)
+	err = h.repo.Delete(c.Request.Context(), middleware.UserID(c), id, c.Query("with_expansions") == "true")
 	if err != nil {
 		if errors.Is(err, repository.ErrBoardGameNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Board game not found"})
 			return
 		}
+		if errors.Is(err, repository.ErrHasExpansions) {
+			h.respondHasExpansions(c, id)
+			return
+		}
+		if errors.Is(err, repository.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 
 		// Any other error is internal server error
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
@@ -103,111 +308,217 @@ func (h *BoardGameHandler) HandleBoardGameDelete(c *gin.Context) {
 	c.Writer.WriteHeaderNow() // Force Gin to write the header immediately
 }
 
-// Image handlers
-func (h *BoardGameHandler) HandleUploadBoardGameImage(c *gin.Context) {
-	boardGameIDParam := c.Param("id")
-	boardGameID, err := strconv.ParseInt(boardGameIDParam, 10, 64)
+func (h *BoardGameHandler) respondHasExpansions(c *gin.Context, id int64) {
+	game, err := h.repo.GetByID(c.Request.Context(), middleware.UserID(c), id)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board game ID"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
-	// 2. Get the uploaded file
-	file, err := c.FormFile("image")
+	c.JSON(http.StatusConflict, gin.H{
+		"error":      "Board game has expansions, delete them first or pass with_expansions=true",
+		"expansions": game.Expansions,
+	})
+}
+
+// Replaces the whole board game, same validation rules as create
+func (h *BoardGameHandler) HandleBoardGameUpdate(c *gin.Context) {
+	idParam := c.Param("id")
+
+	id, err := strconv.ParseInt(idParam, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No image provided"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
 		return
 	}
 
-	// 3. Get image type from form
-	imageType := c.PostForm("imageType") //TODO create a constants file
-	if imageType != "cover" && imageType != "gameplay" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image type"})
+	var game models.BoardGame
+	if err := c.ShouldBindJSON(&game); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	game.ID = id
 
-	// 4. Validate file size (e.g., max 10MB)
-	const maxFileSize = 10 * 1024 * 1024 // 10MB
-	if file.Size > maxFileSize {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "File too large (max 10MB)"})
+	if err := validatePlayerRange(&game); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// 5. Validate MIME type
-	if !strings.HasPrefix(file.Header.Get("Content-Type"), "image/") {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "File must be an image"})
+	if err := h.repo.Update(c.Request.Context(), middleware.UserID(c), &game); err != nil {
+		if errors.Is(err, repository.ErrBoardGameNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Board game not found"})
+			return
+		}
+		if errors.Is(err, repository.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update board game"})
 		return
 	}
 
-	// 6. Open and read the file
-	openedFile, err := file.Open()
+	// Answer the stored game like PATCH does, with its images, tags, credits and loan
+	updated, err := h.repo.GetByID(c.Request.Context(), middleware.UserID(c), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read image"})
+		if errors.Is(err, repository.ErrBoardGameNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Board game not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
-	defer openedFile.Close()
 
-	// 7. Read file bytes
-	imageData, err := io.ReadAll(openedFile)
+	c.JSON(http.StatusOK, updated)
+}
+
+// Partial update using JSON Merge Patch (RFC 7386)
+// A field set to null is removed, so only optional fields accept it.
+func (h *BoardGameHandler) HandleBoardGamePatch(c *gin.Context) {
+	idParam := c.Param("id")
+
+	id, err := strconv.ParseInt(idParam, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read image data"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
 		return
 	}
 
-	// 8. Generate thumbnail
-	thumbnailData, err := helpers.GenerateThumbnail(imageData, file.Header.Get("Content-Type"))
+	// 1. The patch document must be a JSON object
+	var patch map[string]json.RawMessage
+	if err := json.NewDecoder(c.Request.Body).Decode(&patch); err != nil || patch == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Patch must be a JSON object"})
+		return
+	}
+
+	for field := range patch {
+		if !repository.IsPatchableField(field) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Field '%s' cannot be patched", field)})
+			return
+		}
+	}
+
+	// 2. Load the current game so the merged result can be validated
+	current, err := h.repo.GetByID(c.Request.Context(), middleware.UserID(c), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate thumbnail"})
+		if errors.Is(err, repository.ErrBoardGameNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Board game not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
-	// 9. Create image model
-	image := &models.BoardGameImage{
-		BoardGameID:   boardGameID,
-		ImageData:     imageData,
-		ImageMimeType: file.Header.Get("Content-Type"),
-		ThumbnailData: thumbnailData,
-		ImageType:     imageType,
-		DisplayOrder:  0, // TODO: Calculate this
+	merged, err := applyMergePatch(current, patch)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	// 10. Save to database
-	err = h.imageRepo.SaveImage(c.Request.Context(), image)
+	// 3. Same binding rules as create
+	if err := binding.Validator.ValidateStruct(merged); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validatePlayerRange(merged); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 4. Only send the patched columns to the database
+	fields := make(map[string]any, len(patch))
+	for field, value := range patch {
+		if string(value) == "null" {
+			fields[field] = nil
+			continue
+		}
+		fields[field] = boardGameFieldValue(merged, field)
+	}
+
+	game, err := h.repo.Patch(c.Request.Context(), middleware.UserID(c), id, fields)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save image"})
+		if errors.Is(err, repository.ErrBoardGameNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Board game not found"})
+			return
+		}
+		// Changed by someone else since it was read above
+		if errors.Is(err, repository.ErrInvalidPlayerRange) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, repository.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update board game"})
 		return
 	}
 
-	// 11. Return success with image ID
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "Image uploaded successfully",
-		"imageId": image.ID,
-	})
+	c.JSON(http.StatusOK, game)
+}
 
+// max_players is optional (0 or omitted means NULL), when set it cannot be
+// below min_players
+func validatePlayerRange(game *models.BoardGame) error {
+	if game.MaxPlayers < 0 || (game.MaxPlayers > 0 && game.MaxPlayers < game.MinPlayers) {
+		return fmt.Errorf("max_players must be at least min_players")
+	}
+	return nil
 }
 
-func (h *BoardGameHandler) HandleGetBoardGameCoverImage(c *gin.Context) {
-	boardGameIDParam := c.Param("id")
-	boardGameID, err := strconv.ParseInt(boardGameIDParam, 10, 64)
+// applyMergePatch merges the patch document on top of the JSON form of the game
+func applyMergePatch(game *models.BoardGame, patch map[string]json.RawMessage) (*models.BoardGame, error) {
+	currentJSON, err := json.Marshal(game)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board game ID"})
-		return
+		return nil, err
 	}
 
-	// 2. Get the cover thumbnail from repository
-	image, err := h.imageRepo.GetCoverThumbnail(c.Request.Context(), boardGameID)
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(currentJSON, &document); err != nil {
+		return nil, err
+	}
+
+	for field, value := range patch {
+		if string(value) == "null" {
+			delete(document, field)
+			continue
+		}
+		document[field] = value
+	}
+
+	mergedJSON, err := json.Marshal(document)
 	if err != nil {
-		// If no cover image exists, return 404
-		c.JSON(http.StatusNotFound, gin.H{"error": "Cover image not found"})
-		return
+		return nil, err
 	}
 
-	// 3. Set the Content-Type header (crucial!)
-	c.Header("Content-Type", image.ImageMimeType)
+	var merged models.BoardGame
+	if err := json.Unmarshal(mergedJSON, &merged); err != nil {
+		return nil, fmt.Errorf("Invalid patch: %w", err)
+	}
 
-	// 4. Optional: Add cache headers for better performance
-	c.Header("Cache-Control", "public, max-age=86400") // Cache for 24 hours
+	return &merged, nil
+}
 
-	// 5. Write the thumbnail bytes directly to response
-	c.Data(http.StatusOK, image.ImageMimeType, image.ThumbnailData)
+func boardGameFieldValue(game *models.BoardGame, field string) any {
+	switch field {
+	case "name":
+		return game.Name
+	case "min_players":
+		return game.MinPlayers
+	case "max_players":
+		if game.MaxPlayers == 0 {
+			return nil
+		}
+		return game.MaxPlayers
+	case "play_time":
+		return game.PlayTime
+	case "min_age":
+		return game.MinAge
+	case "description":
+		return game.Description
+	case "year_published":
+		return game.YearPublished
+	}
+	return nil
 }
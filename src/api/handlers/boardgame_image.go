@@ -0,0 +1,467 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/api/middleware"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/helpers"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// Image handlers
+func (h *BoardGameHandler) HandleUploadBoardGameImage(c *gin.Context) {
+	boardGameIDParam := c.Param("id")
+	boardGameID, err := strconv.ParseInt(boardGameIDParam, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board game ID"})
+		return
+	}
+
+	// 2. Get the uploaded file
+	file, err := c.FormFile("image")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No image provided"})
+		return
+	}
+
+	// 3. Get image type from form
+	imageType := c.PostForm("imageType") //TODO create a constants file
+	if imageType != "cover" && imageType != "gameplay" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image type"})
+		return
+	}
+
+	// What happens to the current cover: replace (default), demote to gameplay or reject
+	coverMode := repository.CoverMode(c.DefaultPostForm("coverMode", string(repository.CoverModeReplace)))
+	if coverMode != repository.CoverModeReplace && coverMode != repository.CoverModeDemote && coverMode != repository.CoverModeReject {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cover mode (replace, demote or reject)"})
+		return
+	}
+
+	// 4. Validate file size (e.g., max 10MB)
+	const maxFileSize = 10 * 1024 * 1024 // 10MB
+	if file.Size > maxFileSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File too large (max 10MB)"})
+		return
+	}
+
+	// 5. Open and read the file
+	openedFile, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read image"})
+		return
+	}
+	defer openedFile.Close()
+
+	// 6. Read file bytes, never more than the limit whatever the header says
+	imageData, err := io.ReadAll(io.LimitReader(openedFile, maxFileSize+1))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read image data"})
+		return
+	}
+	if len(imageData) > maxFileSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File too large (max 10MB)"})
+		return
+	}
+
+	// 7. Check the real type and size, strip the metadata. The Content-Type header is ignored.
+	imageData, mimeType, err := helpers.SanitizeImage(imageData, h.maxImagePixels)
+	if err != nil {
+		if errors.Is(err, helpers.ErrUnsupportedImage) || errors.Is(err, helpers.ErrImageTooLarge) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read image data"})
+		return
+	}
+
+	// 8. Generate thumbnail
+	thumbnailData, err := helpers.GenerateThumbnail(imageData, mimeType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate thumbnail"})
+		return
+	}
+
+	// 9. Generate the responsive variants
+	variants, err := helpers.GenerateVariants(imageData, mimeType, h.variantWidths)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate image variants"})
+		return
+	}
+
+	// 10. Create image model
+	image := &models.BoardGameImage{
+		BoardGameID:   boardGameID,
+		ImageData:     imageData,
+		ImageMimeType: mimeType,
+		ThumbnailData: thumbnailData,
+		ImageType:     imageType,
+		Variants:      variants,
+	}
+
+	// 11. Save to database
+	previousCoverID, err := h.imageRepo.SaveImage(c.Request.Context(), middleware.UserID(c), image, coverMode)
+	if err != nil {
+		if errors.Is(err, repository.ErrCoverExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, repository.ErrBoardGameNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Board game not found"})
+			return
+		}
+		if errors.Is(err, repository.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save image"})
+		return
+	}
+
+	// 12. A cover took the place of another one, nothing new was added to the game
+	if previousCoverID != 0 {
+		response := gin.H{"imageId": image.ID}
+		if coverMode == repository.CoverModeDemote {
+			response["message"] = "Cover image replaced, the previous cover is now a gameplay image"
+			response["demotedImageId"] = previousCoverID
+		} else {
+			response["message"] = "Cover image replaced"
+			response["replacedImageId"] = previousCoverID
+		}
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	// 13. Return success with image ID
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Image uploaded successfully",
+		"imageId": image.ID,
+	})
+
+}
+
+// Serves the cover thumbnail, or the cover resized with ?w=
+func (h *BoardGameHandler) HandleGetBoardGameCoverImage(c *gin.Context) {
+	boardGameIDParam := c.Param("id")
+	boardGameID, err := strconv.ParseInt(boardGameIDParam, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board game ID"})
+		return
+	}
+
+	if c.Query("w") != "" {
+		h.serveCoverVariant(c, boardGameID)
+		return
+	}
+
+	// 2. Get the cover thumbnail from repository
+	image, err := h.imageRepo.GetCoverThumbnail(c.Request.Context(), middleware.UserID(c), boardGameID)
+	if err != nil {
+		// If no cover image exists, return 404
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cover image not found"})
+		return
+	}
+
+	// 3. Write the thumbnail, with ETag and caching headers
+	serveImageData(c, image.ThumbnailData, image.ImageMimeType, image.ContentHash, "thumbnail", image.UploadedAt)
+}
+
+// Lists the images of a board game as metadata with URLs, no bytes
+func (h *BoardGameHandler) HandleListBoardGameImages(c *gin.Context) {
+	boardGameIDParam := c.Param("id")
+	boardGameID, err := strconv.ParseInt(boardGameIDParam, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board game ID"})
+		return
+	}
+
+	images, err := h.imageRepo.ListImages(c.Request.Context(), middleware.UserID(c), boardGameID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, images)
+}
+
+// Streams any image full size, or the variant closest to ?w= in a format the client accepts
+func (h *BoardGameHandler) HandleGetBoardGameImage(c *gin.Context) {
+	imageID, err := strconv.ParseInt(c.Param("imageId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image ID"})
+		return
+	}
+
+	if c.Query("w") != "" {
+		width, ok := parseImageWidth(c)
+		if !ok {
+			return
+		}
+
+		variants, err := h.imageRepo.ListVariants(c.Request.Context(), middleware.UserID(c), imageID)
+		if err != nil {
+			respondImageError(c, err)
+			return
+		}
+
+		if h.serveVariant(c, imageID, variants, width) {
+			return
+		}
+	}
+
+	h.serveOriginal(c, imageID)
+}
+
+func (h *BoardGameHandler) serveCoverVariant(c *gin.Context, boardGameID int64) {
+	width, ok := parseImageWidth(c)
+	if !ok {
+		return
+	}
+
+	images, err := h.imageRepo.ListImages(c.Request.Context(), middleware.UserID(c), boardGameID)
+	if err != nil {
+		respondImageError(c, err)
+		return
+	}
+
+	for _, image := range images {
+		if image.ImageType != "cover" {
+			continue
+		}
+		if !h.serveVariant(c, image.ID, image.Variants, width) {
+			h.serveOriginal(c, image.ID)
+		}
+		return
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{"error": "Cover image not found"})
+}
+
+func (h *BoardGameHandler) serveOriginal(c *gin.Context, imageID int64) {
+	image, err := h.imageRepo.GetImageByID(c.Request.Context(), middleware.UserID(c), imageID)
+	if err != nil {
+		respondImageError(c, err)
+		return
+	}
+
+	serveImageData(c, image.ImageData, image.ImageMimeType, image.ContentHash, "", image.UploadedAt)
+}
+
+// Streams the thumbnail of any image
+func (h *BoardGameHandler) HandleGetBoardGameImageThumbnail(c *gin.Context) {
+	imageID, err := strconv.ParseInt(c.Param("imageId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image ID"})
+		return
+	}
+
+	image, err := h.imageRepo.GetThumbnailByID(c.Request.Context(), middleware.UserID(c), imageID)
+	if err != nil {
+		respondImageError(c, err)
+		return
+	}
+
+	serveImageData(c, image.ThumbnailData, image.ImageMimeType, image.ContentHash, "thumbnail", image.UploadedAt)
+}
+
+func (h *BoardGameHandler) HandleDeleteBoardGameImage(c *gin.Context) {
+	imageID, err := strconv.ParseInt(c.Param("imageId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image ID"})
+		return
+	}
+
+	if err := h.imageRepo.DeleteImage(c.Request.Context(), middleware.UserID(c), imageID); err != nil {
+		respondImageError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	c.Writer.WriteHeaderNow() // Same as board game delete, force the 204
+}
+
+type reorderImagesRequest struct {
+	ImageIDs []int64 `json:"image_ids" binding:"required"`
+}
+
+// Sets the display order of the gameplay images, body: {"image_ids": [3, 1, 2]}
+func (h *BoardGameHandler) HandleReorderBoardGameImages(c *gin.Context) {
+	boardGameID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board game ID"})
+		return
+	}
+
+	var request reorderImagesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.imageRepo.ReorderImages(c.Request.Context(), middleware.UserID(c), boardGameID, request.ImageIDs)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidImageOrder) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, repository.ErrBoardGameNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Board game not found"})
+			return
+		}
+		if errors.Is(err, repository.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	images, err := h.imageRepo.ListImages(c.Request.Context(), middleware.UserID(c), boardGameID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, images)
+}
+
+// Writes the matching variant, false means the original should be served instead
+func (h *BoardGameHandler) serveVariant(c *gin.Context, imageID int64, variants []models.ImageVariant, width int) bool {
+	// The answer depends on Accept, caches must not mix the formats up
+	c.Header("Vary", "Accept")
+
+	selected := selectImageVariant(variants, width, c.GetHeader("Accept"))
+	if selected == nil {
+		return false
+	}
+
+	variant, err := h.imageRepo.GetVariant(c.Request.Context(), middleware.UserID(c), imageID, selected.Width, selected.MimeType)
+	if err != nil {
+		respondImageError(c, err)
+		return true
+	}
+
+	suffix := fmt.Sprintf("%d-%s", variant.Width, strings.TrimPrefix(variant.MimeType, "image/"))
+	serveImageData(c, variant.Data, variant.MimeType, variant.ContentHash, suffix, variant.UploadedAt)
+	return true
+}
+
+// Writes image bytes through http.ServeContent, which answers If-None-Match,
+// If-Modified-Since (304) and Range requests. The ETag is the content hash of the
+// original plus a suffix naming the representation (thumbnail, 800-webp...).
+// URLs carrying the current ?v= never change so they are cached for a year, anything
+// else must revalidate, which is a cheap 304 while the image stays the same. Images
+// belong to a household, so only the browser may keep them, never a shared cache.
+func serveImageData(c *gin.Context, data []byte, mimeType string, contentHash string, suffix string, modTime time.Time) {
+	etag := contentHash
+	if etag == "" {
+		// Not hashed yet, see migrate-images
+		etag = helpers.ContentHash(data)
+	}
+	if suffix != "" {
+		etag += "-" + suffix
+	}
+
+	if version := c.Query("v"); version != "" && contentHash != "" && version == models.ImageVersion(contentHash) {
+		c.Header("Cache-Control", "private, max-age=31536000, immutable")
+	} else {
+		c.Header("Cache-Control", "private, no-cache")
+	}
+	c.Header("ETag", `"`+etag+`"`)
+	c.Header("Content-Type", mimeType)
+
+	http.ServeContent(c.Writer, c.Request, "", modTime, bytes.NewReader(data))
+	c.Writer.WriteHeaderNow() // A 304 has no body, force the status out like the deletes do
+}
+
+func parseImageWidth(c *gin.Context) (int, bool) {
+	width, err := strconv.Atoi(c.Query("w"))
+	if err != nil || width <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid width"})
+		return 0, false
+	}
+	return width, true
+}
+
+// Formats we only send to clients that ask for them, best first
+var modernImageTypes = []string{"image/webp"}
+
+// Picks the smallest variant at least as wide as requested, nil when only the
+// original is big enough. Variants must be sorted by width.
+func selectImageVariant(variants []models.ImageVariant, width int, accept string) *models.ImageVariant {
+	var candidates []models.ImageVariant
+	for _, variant := range variants {
+		if variant.Width < width {
+			continue
+		}
+		if len(candidates) > 0 && variant.Width != candidates[0].Width {
+			break
+		}
+		candidates = append(candidates, variant)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	for _, mimeType := range modernImageTypes {
+		if !acceptsImageType(accept, mimeType) {
+			continue
+		}
+		for i := range candidates {
+			if candidates[i].MimeType == mimeType {
+				return &candidates[i]
+			}
+		}
+	}
+
+	// Every width has a JPEG or PNG copy
+	for i := range candidates {
+		if !slices.Contains(modernImageTypes, candidates[i].MimeType) {
+			return &candidates[i]
+		}
+	}
+	return nil
+}
+
+// Only an explicit entry counts, image/* and */* don't promise WebP support
+func acceptsImageType(accept string, mimeType string) bool {
+	for _, entry := range strings.Split(accept, ",") {
+		params := strings.Split(entry, ";")
+		if !strings.EqualFold(strings.TrimSpace(params[0]), mimeType) {
+			continue
+		}
+		for _, param := range params[1:] {
+			if q, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if value, err := strconv.ParseFloat(q, 64); err == nil && value == 0 {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func respondImageError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrImageNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		return
+	}
+	if errors.Is(err, repository.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+}
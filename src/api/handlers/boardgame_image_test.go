@@ -0,0 +1,564 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandleListBoardGameImages_OK(t *testing.T) {
+	// Arrange
+	imageRepo := &mockBoardGameImageRepo{}
+	handler := NewBoardGameHandler(&mockBoardGameRepo{}, imageRepo)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/boardgame/1/images", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleListBoardGameImages(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var response []*models.BoardGameImage
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response JSON: %v", err)
+	}
+
+	if len(response) != 2 {
+		t.Fatalf("expected 2 images, got %d", len(response))
+	}
+
+	if response[1].URL != "/api/boardgame/images/2" {
+		t.Errorf("expected image URL '/api/boardgame/images/2', got '%s'", response[1].URL)
+	}
+}
+
+func TestHandleGetBoardGameImage_OK(t *testing.T) {
+	// Arrange
+	imageRepo := &mockBoardGameImageRepo{}
+	handler := NewBoardGameHandler(&mockBoardGameRepo{}, imageRepo)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/boardgame/images/5", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "imageId", Value: "5"}}
+
+	// Act
+	handler.HandleGetBoardGameImage(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	if rec.Header().Get("Content-Type") != "image/png" {
+		t.Errorf("expected Content-Type image/png, got '%s'", rec.Header().Get("Content-Type"))
+	}
+
+	if rec.Body.String() != "full image" {
+		t.Errorf("expected full image bytes, got '%s'", rec.Body.String())
+	}
+}
+
+func TestHandleGetBoardGameImageThumbnail_OK(t *testing.T) {
+	// Arrange
+	imageRepo := &mockBoardGameImageRepo{}
+	handler := NewBoardGameHandler(&mockBoardGameRepo{}, imageRepo)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/boardgame/images/5/thumbnail", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "imageId", Value: "5"}}
+
+	// Act
+	handler.HandleGetBoardGameImageThumbnail(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	if rec.Body.String() != "thumbnail" {
+		t.Errorf("expected thumbnail bytes, got '%s'", rec.Body.String())
+	}
+}
+
+func TestHandleGetBoardGameImage_NotFound(t *testing.T) {
+	// Arrange
+	imageRepo := &mockBoardGameImageRepo{getImageError: repository.ErrImageNotFound}
+	handler := NewBoardGameHandler(&mockBoardGameRepo{}, imageRepo)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/boardgame/images/999", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "imageId", Value: "999"}}
+
+	// Act
+	handler.HandleGetBoardGameImage(ctx)
+
+	// Assert
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestHandleDeleteBoardGameImage_NoContent(t *testing.T) {
+	// Arrange
+	imageRepo := &mockBoardGameImageRepo{}
+	handler := NewBoardGameHandler(&mockBoardGameRepo{}, imageRepo)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/boardgame/images/5", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "imageId", Value: "5"}}
+
+	// Act
+	handler.HandleDeleteBoardGameImage(ctx)
+
+	// Assert
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", rec.Code)
+	}
+
+	if !imageRepo.deleteByIDCalled {
+		t.Fatal("expected DeleteImage() to be called on repository")
+	}
+}
+
+func TestHandleDeleteBoardGameImage_NotFound(t *testing.T) {
+	// Arrange
+	imageRepo := &mockBoardGameImageRepo{deleteError: repository.ErrImageNotFound}
+	handler := NewBoardGameHandler(&mockBoardGameRepo{}, imageRepo)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/boardgame/images/999", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "imageId", Value: "999"}}
+
+	// Act
+	handler.HandleDeleteBoardGameImage(ctx)
+
+	// Assert
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestHandleReorderBoardGameImages_OK(t *testing.T) {
+	// Arrange
+	imageRepo := &mockBoardGameImageRepo{}
+	handler := NewBoardGameHandler(&mockBoardGameRepo{}, imageRepo)
+
+	body := []byte(`{"image_ids": [4, 2, 3]}`)
+	req := httptest.NewRequest(http.MethodPut, "/api/boardgame/1/images/order", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleReorderBoardGameImages(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d %s", rec.Code, rec.Body)
+	}
+
+	if len(imageRepo.reorderIDs) != 3 || imageRepo.reorderIDs[0] != 4 {
+		t.Errorf("expected order [4 2 3], got %v", imageRepo.reorderIDs)
+	}
+}
+
+func TestHandleReorderBoardGameImages_InvalidOrder(t *testing.T) {
+	// Arrange
+	imageRepo := &mockBoardGameImageRepo{reorderError: repository.ErrInvalidImageOrder}
+	handler := NewBoardGameHandler(&mockBoardGameRepo{}, imageRepo)
+
+	body := []byte(`{"image_ids": [4, 4]}`)
+	req := httptest.NewRequest(http.MethodPut, "/api/boardgame/1/images/order", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleReorderBoardGameImages(ctx)
+
+	// Assert
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+}
+
+var testVariants = []models.ImageVariant{
+	{Width: 300, Height: 200, MimeType: "image/jpeg"},
+	{Width: 300, Height: 200, MimeType: "image/webp"},
+	{Width: 800, Height: 533, MimeType: "image/jpeg"},
+}
+
+func TestHandleGetBoardGameImage_Variant(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		accept       string
+		expectedBody string
+	}{
+		{"smallest wide enough", "?w=200", "image/webp,image/*", "300 image/webp"},
+		{"fallback without webp", "?w=200", "image/*,*/*;q=0.8", "300 image/jpeg"},
+		{"webp refused", "?w=200", "image/webp;q=0, image/*", "300 image/jpeg"},
+		{"only jpeg at that width", "?w=500", "image/webp", "800 image/jpeg"},
+		{"wider than every variant", "?w=2000", "image/webp", "full image"},
+		{"no width", "", "image/webp", "full image"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			imageRepo := &mockBoardGameImageRepo{variants: testVariants}
+			handler := NewBoardGameHandler(&mockBoardGameRepo{}, imageRepo)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/boardgame/images/5"+tt.query, nil)
+			req.Header.Set("Accept", tt.accept)
+			ctx, rec := createTestContext(req)
+			ctx.Params = gin.Params{{Key: "imageId", Value: "5"}}
+
+			// Act
+			handler.HandleGetBoardGameImage(ctx)
+
+			// Assert
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", rec.Code)
+			}
+
+			if rec.Body.String() != tt.expectedBody {
+				t.Errorf("expected body '%s', got '%s'", tt.expectedBody, rec.Body.String())
+			}
+
+			if tt.query != "" && rec.Header().Get("Vary") != "Accept" {
+				t.Errorf("expected Vary: Accept, got '%s'", rec.Header().Get("Vary"))
+			}
+		})
+	}
+}
+
+func TestHandleGetBoardGameImage_InvalidWidth(t *testing.T) {
+	// Arrange
+	handler := NewBoardGameHandler(&mockBoardGameRepo{}, &mockBoardGameImageRepo{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/boardgame/images/5?w=wide", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "imageId", Value: "5"}}
+
+	// Act
+	handler.HandleGetBoardGameImage(ctx)
+
+	// Assert
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestHandleGetBoardGameCoverImage_Variant(t *testing.T) {
+	// Arrange
+	imageRepo := &mockBoardGameImageRepo{variants: testVariants}
+	handler := NewBoardGameHandler(&mockBoardGameRepo{}, imageRepo)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/boardgame/1/images/cover?w=800", nil)
+	req.Header.Set("Accept", "image/avif,image/webp")
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleGetBoardGameCoverImage(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	if rec.Body.String() != "800 image/jpeg" {
+		t.Errorf("expected the 800px JPEG, got '%s'", rec.Body.String())
+	}
+}
+
+var gameplayUpload = map[string]string{"imageType": "gameplay"}
+
+func newUploadRequest(t *testing.T, data []byte, contentType string, fields map[string]string) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, value := range fields {
+		writer.WriteField(name, value)
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="image"; filename="photo"`)
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		t.Fatalf("failed to create multipart body: %v", err)
+	}
+	part.Write(data)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/boardgame/1/images", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func encodeTestImage(t *testing.T, width, height int, asJPEG bool) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	if asJPEG {
+		jpeg.Encode(&buf, img, nil)
+	} else {
+		png.Encode(&buf, img)
+	}
+	return buf.Bytes()
+}
+
+// Inserts an EXIF segment with the given orientation right after the JPEG SOI marker
+func withExifOrientation(data []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01")
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112) // Orientation
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)      // SHORT
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0) // Padding and no next IFD
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	return append(append([]byte{0xFF, 0xD8}, segment...), data[2:]...)
+}
+
+func TestHandleUploadBoardGameImage_DetectsType(t *testing.T) {
+	// Arrange
+	imageRepo := &mockBoardGameImageRepo{}
+	handler := NewBoardGameHandler(&mockBoardGameRepo{}, imageRepo)
+
+	req := newUploadRequest(t, encodeTestImage(t, 20, 20, false), "application/octet-stream", gameplayUpload)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleUploadBoardGameImage(ctx)
+
+	// Assert
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d %s", rec.Code, rec.Body)
+	}
+
+	if imageRepo.userID != testUser.ID {
+		t.Errorf("expected the image saved for user %d, got %d", testUser.ID, imageRepo.userID)
+	}
+
+	if imageRepo.savedImage.ImageMimeType != "image/png" {
+		t.Errorf("expected detected type image/png, got '%s'", imageRepo.savedImage.ImageMimeType)
+	}
+}
+
+func TestHandleUploadBoardGameImage_Rejected(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+		maxPixels   int
+	}{
+		{"not an image", []byte("#!/bin/sh\necho hello"), "image/png", 1000},
+		{"too many pixels", encodeTestImage(t, 20, 20, false), "image/png", 399},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			imageRepo := &mockBoardGameImageRepo{}
+			handler := NewBoardGameHandler(&mockBoardGameRepo{}, imageRepo)
+			handler.SetMaxImagePixels(tt.maxPixels)
+
+			req := newUploadRequest(t, tt.data, tt.contentType, gameplayUpload)
+			ctx, rec := createTestContext(req)
+			ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			// Act
+			handler.HandleUploadBoardGameImage(ctx)
+
+			// Assert
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", rec.Code)
+			}
+
+			if imageRepo.createCalled {
+				t.Fatal("expected SaveImage() not to be called")
+			}
+		})
+	}
+}
+
+func TestHandleUploadBoardGameImage_StripsExif(t *testing.T) {
+	tests := []struct {
+		name           string
+		orientation    uint16
+		expectedWidth  int
+		expectedHeight int
+	}{
+		{"upright", 1, 40, 20},
+		{"rotated 90 degrees", 6, 20, 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			imageRepo := &mockBoardGameImageRepo{}
+			handler := NewBoardGameHandler(&mockBoardGameRepo{}, imageRepo)
+
+			photo := withExifOrientation(encodeTestImage(t, 40, 20, true), tt.orientation)
+			req := newUploadRequest(t, photo, "image/jpeg", gameplayUpload)
+			ctx, rec := createTestContext(req)
+			ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			// Act
+			handler.HandleUploadBoardGameImage(ctx)
+
+			// Assert
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("expected status 201, got %d %s", rec.Code, rec.Body)
+			}
+
+			saved := imageRepo.savedImage.ImageData
+			if bytes.Contains(saved, []byte("Exif")) {
+				t.Error("expected the EXIF segment to be removed")
+			}
+
+			config, err := jpeg.DecodeConfig(bytes.NewReader(saved))
+			if err != nil {
+				t.Fatalf("saved image is not a JPEG: %v", err)
+			}
+			if config.Width != tt.expectedWidth || config.Height != tt.expectedHeight {
+				t.Errorf("expected %dx%d, got %dx%d", tt.expectedWidth, tt.expectedHeight, config.Width, config.Height)
+			}
+		})
+	}
+}
+
+func TestHandleGetBoardGameImage_Caching(t *testing.T) {
+	tests := []struct {
+		name                 string
+		query                string
+		headers              map[string]string
+		expectedStatus       int
+		expectedBody         string
+		expectedCacheControl string
+	}{
+		{"unversioned", "", nil, http.StatusOK, "full image", "private, no-cache"},
+		{"current version", "?v=f00dcafe", nil, http.StatusOK, "full image", "private, max-age=31536000, immutable"},
+		{"old version", "?v=0ld", nil, http.StatusOK, "full image", "private, no-cache"},
+		{"etag matches", "", map[string]string{"If-None-Match": `"f00dcafe"`}, http.StatusNotModified, "", "private, no-cache"},
+		{"etag changed", "", map[string]string{"If-None-Match": `"0ld"`}, http.StatusOK, "full image", "private, no-cache"},
+		{"not modified since", "", map[string]string{"If-Modified-Since": "Wed, 01 May 2024 12:00:00 GMT"}, http.StatusNotModified, "", "private, no-cache"},
+		{"range", "", map[string]string{"Range": "bytes=5-"}, http.StatusPartialContent, "image", "private, no-cache"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := NewBoardGameHandler(&mockBoardGameRepo{}, &mockBoardGameImageRepo{})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/boardgame/images/5"+tt.query, nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			ctx, rec := createTestContext(req)
+			ctx.Params = gin.Params{{Key: "imageId", Value: "5"}}
+
+			// Act
+			handler.HandleGetBoardGameImage(ctx)
+
+			// Assert
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+
+			if rec.Body.String() != tt.expectedBody {
+				t.Errorf("expected body '%s', got '%s'", tt.expectedBody, rec.Body.String())
+			}
+
+			if rec.Header().Get("ETag") != `"f00dcafe"` {
+				t.Errorf("expected ETag \"f00dcafe\", got '%s'", rec.Header().Get("ETag"))
+			}
+
+			if rec.Header().Get("Cache-Control") != tt.expectedCacheControl {
+				t.Errorf("expected Cache-Control '%s', got '%s'", tt.expectedCacheControl, rec.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
+
+func TestHandleUploadBoardGameImage_CoverModes(t *testing.T) {
+	tests := []struct {
+		name             string
+		currentCoverID   int64
+		coverMode        string
+		expectedStatus   int
+		expectedMode     repository.CoverMode
+		expectedPrevious string
+	}{
+		{"first cover", 0, "", http.StatusCreated, repository.CoverModeReplace, ""},
+		{"replace by default", 7, "", http.StatusOK, repository.CoverModeReplace, "replacedImageId"},
+		{"demote", 7, "demote", http.StatusOK, repository.CoverModeDemote, "demotedImageId"},
+		{"reject", 7, "reject", http.StatusConflict, repository.CoverModeReject, ""},
+		{"invalid mode", 7, "swap", http.StatusBadRequest, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			imageRepo := &mockBoardGameImageRepo{currentCoverID: tt.currentCoverID}
+			handler := NewBoardGameHandler(&mockBoardGameRepo{}, imageRepo)
+
+			fields := map[string]string{"imageType": "cover"}
+			if tt.coverMode != "" {
+				fields["coverMode"] = tt.coverMode
+			}
+			req := newUploadRequest(t, encodeTestImage(t, 20, 20, false), "image/png", fields)
+			ctx, rec := createTestContext(req)
+			ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			// Act
+			handler.HandleUploadBoardGameImage(ctx)
+
+			// Assert
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d %s", tt.expectedStatus, rec.Code, rec.Body)
+			}
+
+			if imageRepo.coverMode != tt.expectedMode {
+				t.Errorf("expected cover mode '%s', got '%s'", tt.expectedMode, imageRepo.coverMode)
+			}
+
+			if tt.expectedPrevious != "" {
+				var response map[string]any
+				if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+					t.Fatalf("failed to unmarshal response JSON: %v", err)
+				}
+				if response[tt.expectedPrevious] != float64(7) {
+					t.Errorf("expected %s 7, got %v", tt.expectedPrevious, response[tt.expectedPrevious])
+				}
+			}
+		})
+	}
+}
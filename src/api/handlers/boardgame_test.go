@@ -4,11 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/eddiarnoldo/my-game-shelf/src/api/middleware"
 	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
 	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
 	"github.com/gin-gonic/gin"
@@ -32,10 +37,14 @@ func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
 
+// Every test request is signed in as this user, like behind middleware.Authenticate
+var testUser = &models.User{ID: 42, Email: "ada@example.com", DisplayName: "Ada"}
+
 func createTestContext(req *http.Request) (*gin.Context, *httptest.ResponseRecorder) {
 	rec := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(rec)
 	ctx.Request = req
+	middleware.SetCurrentUser(ctx, testUser)
 	return ctx, rec
 }
 
@@ -70,6 +79,50 @@ func TestHandleBoardGameCreate_OK(t *testing.T) {
 	if !repo.createCalled {
 		t.Fatal("expected Create() to be called on repository")
 	}
+
+	if !reflect.DeepEqual(repo.userIDs, []int64{testUser.ID}) {
+		t.Errorf("expected the game on the shelf of user %d, got %v", testUser.ID, repo.userIDs)
+	}
+}
+
+func TestHandleBoardGameCreate_HouseholdErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"viewer of the household", repository.ErrForbidden, http.StatusForbidden},
+		{"not a member of the household", repository.ErrHouseholdNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mockBoardGameRepo{createError: tt.err}
+			handler := NewBoardGameHandler(repo, nil)
+
+			body := []byte(`{
+				"name": "Catan",
+				"min_players": 3,
+				"play_time": 90,
+				"min_age": 10,
+				"description": "Trade and build",
+				"household_id": 5
+			}`)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/boardgames", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			ctx, rec := createTestContext(req)
+
+			// Act
+			handler.HandleBoardGameCreate(ctx)
+
+			// Assert
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
 }
 
 func TestHandleBoardGameCreate_BadRequestJSON(t *testing.T) {
@@ -121,6 +174,10 @@ func TestHandleGetAllBoardgames_OK(t *testing.T) {
 		t.Fatal("expected GetAll() to be called on repository")
 	}
 
+	if !reflect.DeepEqual(repo.userIDs, []int64{testUser.ID}) {
+		t.Errorf("expected the shelf of user %d, got %v", testUser.ID, repo.userIDs)
+	}
+
 	bodyBytes := rec.Body.Bytes()
 	var response []*models.BoardGame
 
@@ -174,6 +231,198 @@ func TestHandleGetAllBoardGames_errorRepo(t *testing.T) {
 	}
 }
 
+func TestHandleGetAllBoardGames_FiltersAndPagination(t *testing.T) {
+	// Arrange
+	repo := &mockBoardGameRepo{getAllNext: "next-token"}
+	handler := NewBoardGameHandler(repo, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/boardgames?players=5&max_play_time=60&age=8&name=cat&sort=name&order=desc&limit=20&tags=co-op,party&tag_match=any&designer=rosenberg&publisher=lookout&on_shelf=true", nil)
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleGetBoardGames(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d %s", rec.Code, rec.Body)
+	}
+
+	onShelf := true
+	expected := models.BoardGameFilter{
+		Players: 5, MaxPlayTime: 60, Age: 8, NamePrefix: "cat", Sort: "name", Order: "desc", Limit: 20,
+		Tags: []string{"co-op", "party"}, TagMatch: "any", Designer: "rosenberg", Publisher: "lookout",
+		OnShelf: &onShelf,
+	}
+	if !reflect.DeepEqual(repo.getAllFilter, expected) {
+		t.Errorf("expected filter %+v, got %+v", expected, repo.getAllFilter)
+	}
+
+	if rec.Header().Get("X-Total-Count") != "1" {
+		t.Errorf("expected X-Total-Count 1, got '%s'", rec.Header().Get("X-Total-Count"))
+	}
+
+	if rec.Header().Get("X-Next-Cursor") != "next-token" {
+		t.Errorf("expected X-Next-Cursor 'next-token', got '%s'", rec.Header().Get("X-Next-Cursor"))
+	}
+}
+
+func TestHandleGetAllBoardGames_InvalidQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		repoErr error
+	}{
+		{name: "non numeric players", url: "/api/boardgames?players=many"},
+		{name: "negative limit", url: "/api/boardgames?limit=-1"},
+		{name: "on shelf not a boolean", url: "/api/boardgames?on_shelf=maybe"},
+		{name: "unknown sort", url: "/api/boardgames?sort=rating", repoErr: repository.ErrInvalidSort},
+		{name: "bad cursor", url: "/api/boardgames?cursor=abc", repoErr: repository.ErrInvalidCursor},
+		{name: "unknown tag match", url: "/api/boardgames?tags=party&tag_match=some", repoErr: repository.ErrInvalidTagMatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mockBoardGameRepo{getAllError: tt.repoErr}
+			handler := NewBoardGameHandler(repo, nil)
+
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			ctx, rec := createTestContext(req)
+
+			// Act
+			handler.HandleGetBoardGames(ctx)
+
+			// Assert
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestHandleSearchBoardGames_OK(t *testing.T) {
+	// Arrange
+	repo := &mockBoardGameRepo{}
+	handler := NewBoardGameHandler(repo, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/boardgames/search?q=catn", nil)
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleSearchBoardGames(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	if repo.searchText != "catn" {
+		t.Errorf("expected search for 'catn', got '%s'", repo.searchText)
+	}
+
+	var response []*models.BoardGameSearchResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response JSON: %v", err)
+	}
+
+	if len(response) != 1 || response[0].Name != "Catan" || response[0].Rank == 0 {
+		t.Errorf("expected ranked result 'Catan', got %+v", response)
+	}
+}
+
+func TestHandleSearchBoardGames_MissingQuery(t *testing.T) {
+	// Arrange
+	repo := &mockBoardGameRepo{}
+	handler := NewBoardGameHandler(repo, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/boardgames/search?q=%20", nil)
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleSearchBoardGames(ctx)
+
+	// Assert
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+
+	if repo.searchCalled {
+		t.Fatal("Search() should not be called without a query")
+	}
+}
+
+func TestHandleRecommendTonight_OK(t *testing.T) {
+	// Arrange
+	repo := &mockBoardGameRepo{}
+	handler := NewBoardGameHandler(repo, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/recommendations/tonight?players=3&minutes=90&age=10&tags=Trading,%20dice&tags=family&exclude=4,5", nil)
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleRecommendTonight(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d %s", rec.Code, rec.Body)
+	}
+
+	criteria := repo.criteria
+	if criteria.Players != 3 || criteria.Minutes != 90 || criteria.Age != 10 {
+		t.Errorf("expected 3 players, 90 minutes and age 10, got %+v", criteria)
+	}
+	if strings.Join(criteria.Tags, ",") != "trading,dice,family" {
+		t.Errorf("expected tags trading, dice and family, got %v", criteria.Tags)
+	}
+	if len(criteria.ExcludeIDs) != 2 || criteria.ExcludeIDs[0] != 4 || criteria.ExcludeIDs[1] != 5 {
+		t.Errorf("expected games 4 and 5 to be excluded, got %v", criteria.ExcludeIDs)
+	}
+
+	var response []*models.Recommendation
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response JSON: %v", err)
+	}
+
+	if len(response) != 1 || response[0].Reason == "" {
+		t.Errorf("expected one recommendation with a reason, got %+v", response)
+	}
+}
+
+func TestHandleRecommendTonight_BadRequest(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"missing players", "minutes=60"},
+		{"zero players", "players=0"},
+		{"invalid minutes", "players=4&minutes=soon"},
+		{"invalid exclude", "players=4&exclude=catan"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mockBoardGameRepo{}
+			handler := NewBoardGameHandler(repo, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/recommendations/tonight?"+tt.query, nil)
+			ctx, rec := createTestContext(req)
+
+			// Act
+			handler.HandleRecommendTonight(ctx)
+
+			// Assert
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", rec.Code)
+			}
+
+			if repo.recommendCalled {
+				t.Fatal("Recommend() should not be called with invalid criteria")
+			}
+		})
+	}
+}
+
 func TestHandleGetBoardGameByID_OK(t *testing.T) {
 	// Arrange
 	repo := &mockBoardGameRepo{}
@@ -217,6 +466,50 @@ func TestHandleGetBoardGameByID_OK(t *testing.T) {
 	}
 }
 
+func TestHandleGetBoardGameByID_EmbedsImages(t *testing.T) {
+	// Arrange
+	repo := &mockBoardGameRepo{
+		getByIDGame: &models.BoardGame{
+			ID:            123,
+			Name:          "Catan",
+			CoverImageUrL: models.CoverImageURL(123, "abc123"),
+			Images: []models.BoardGameImageRef{
+				{ID: 455, URL: models.ImageURL(455, "abc123"), Type: "cover"},
+				{ID: 456, URL: models.ImageURL(456, "def456"), Type: "gameplay"},
+			},
+		},
+	}
+	handler := NewBoardGameHandler(repo, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/boardgames/123", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "123"}}
+
+	// Act
+	handler.HandleGetBoardGameByID(ctx)
+
+	// Assert
+	var response struct {
+		CoverImageURL string `json:"coverImageUrl"`
+		Images        []struct {
+			ID   int64  `json:"id"`
+			URL  string `json:"url"`
+			Type string `json:"type"`
+		} `json:"images"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response JSON: %v", err)
+	}
+
+	if response.CoverImageURL != "/api/boardgame/123/images/cover?v=abc123" {
+		t.Errorf("expected cover URL, got '%s'", response.CoverImageURL)
+	}
+
+	if len(response.Images) != 2 || response.Images[1].URL != "/api/boardgame/images/456?v=def456" || response.Images[1].Type != "gameplay" {
+		t.Errorf("expected embedded image references, got %+v", response.Images)
+	}
+}
+
 func TestHandleGetBoardGameById_errorRepo(t *testing.T) {
 	// Arrange
 	var ErrMockDBFailure = ErrMockDBFailureType{}
@@ -245,7 +538,8 @@ func TestHandleGetBoardGameById_errorRepo(t *testing.T) {
 func TestHandleBoardGameDelete_NoContent(t *testing.T) {
 	// Arrange
 	repo := &mockBoardGameRepo{}
-	handler := NewBoardGameHandler(repo, nil)
+	imageRepo := &mockBoardGameImageRepo{}
+	handler := NewBoardGameHandler(repo, imageRepo)
 
 	req := httptest.NewRequest(http.MethodDelete, "/api/boardgames/1", nil)
 	ctx, rec := createTestContext(req)
@@ -270,7 +564,7 @@ func TestHandleBoardGameDelete_NotFound(t *testing.T) {
 	repo := &mockBoardGameRepo{
 		deleteError: ErrNotFound,
 	}
-	handler := NewBoardGameHandler(repo, nil)
+	handler := NewBoardGameHandler(repo, &mockBoardGameImageRepo{})
 
 	req := httptest.NewRequest(http.MethodDelete, "/api/boardgames/999", nil)
 	ctx, rec := createTestContext(req)
@@ -295,7 +589,7 @@ func TestHandleBoardGameDelete_InternalServerError(t *testing.T) {
 	repo := &mockBoardGameRepo{
 		deleteError: ErrMockDBFailure,
 	}
-	handler := NewBoardGameHandler(repo, nil)
+	handler := NewBoardGameHandler(repo, &mockBoardGameImageRepo{})
 
 	req := httptest.NewRequest(http.MethodDelete, "/api/boardgames/1", nil)
 	ctx, rec := createTestContext(req)
@@ -314,76 +608,593 @@ func TestHandleBoardGameDelete_InternalServerError(t *testing.T) {
 	}
 }
 
+func TestHandleBoardGameDelete_Forbidden(t *testing.T) {
+	// Arrange
+	repo := &mockBoardGameRepo{
+		deleteError: repository.ErrForbidden,
+	}
+	handler := NewBoardGameHandler(repo, &mockBoardGameImageRepo{})
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/boardgames/1", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleBoardGameDelete(ctx)
+
+	// Assert
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a viewer, got %d", rec.Code)
+	}
+}
+
+func TestHandleBoardGameDelete_HasExpansions(t *testing.T) {
+	// Arrange
+	repo := &mockBoardGameRepo{
+		expansionTree: []int64{2},
+		getByIDGame: &models.BoardGame{ID: 1, Name: "Agricola", Expansions: []models.ExpansionRef{
+			{ID: 2, Name: "Farmers of the Moor", Relation: models.RelationExpansion},
+		}},
+	}
+	imageRepo := &mockBoardGameImageRepo{}
+	handler := NewBoardGameHandler(repo, imageRepo)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/boardgames/1", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleBoardGameDelete(ctx)
+
+	// Assert
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", rec.Code)
+	}
+
+	if repo.withExpansions || len(repo.deletedIDs) > 0 {
+		t.Fatal("expected nothing to be deleted")
+	}
+
+	var response struct {
+		Expansions []models.ExpansionRef `json:"expansions"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response JSON: %v", err)
+	}
+
+	if len(response.Expansions) != 1 || response.Expansions[0].ID != 2 {
+		t.Errorf("expected the expansions to be listed, got %+v", response.Expansions)
+	}
+}
+
+func TestHandleBoardGameDelete_WithExpansions(t *testing.T) {
+	// Arrange
+	repo := &mockBoardGameRepo{expansionTree: []int64{3, 2}}
+	handler := NewBoardGameHandler(repo, &mockBoardGameImageRepo{})
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/boardgames/1?with_expansions=true", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleBoardGameDelete(ctx)
+
+	// Assert
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", rec.Code)
+	}
+
+	if !repo.withExpansions || !reflect.DeepEqual(repo.deletedIDs, []int64{3, 2, 1}) {
+		t.Errorf("expected the game to be deleted with its expansions, got %v", repo.deletedIDs)
+	}
+}
+
+func TestHandleBoardGameUpdate_OK(t *testing.T) {
+	// Arrange
+	repo := &mockBoardGameRepo{getByIDGame: &models.BoardGame{
+		ID:        1,
+		Name:      "Catan",
+		Images:    []models.BoardGameImageRef{{ID: 5, URL: "/api/boardgame/images/5", Type: "cover"}},
+		Tags:      []models.TagRef{{ID: 2, Name: "Trading", Kind: models.TagKindMechanic}},
+		UpdatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}}
+	handler := NewBoardGameHandler(repo, nil)
+
+	body := []byte(`{
+		"name": "Catan",
+		"min_players": 3,
+		"max_players": 4,
+		"play_time": 90,
+		"min_age": 10,
+		"description": "Trade and build"
+	}`)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/boardgames/1", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleBoardGameUpdate(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d %s", rec.Code, rec.Body)
+	}
+
+	if !repo.updateCalled {
+		t.Fatal("expected Update() to be called on repository")
+	}
+
+	if repo.updatedGame.ID != 1 || repo.updatedGame.Name != "Catan" {
+		t.Errorf("expected game 1 named 'Catan', got %d '%s'", repo.updatedGame.ID, repo.updatedGame.Name)
+	}
+
+	// The stored game comes back, not the request
+	var game models.BoardGame
+	if err := json.Unmarshal(rec.Body.Bytes(), &game); err != nil {
+		t.Fatalf("failed to unmarshal response JSON: %v", err)
+	}
+
+	if !repo.getByIDCalled || len(game.Images) != 1 || len(game.Tags) != 1 || !game.UpdatedAt.Equal(repo.getByIDGame.UpdatedAt) {
+		t.Errorf("expected the game read back after the update, got %+v", game)
+	}
+}
+
+func TestHandleBoardGameUpdate_BadRequestJSON(t *testing.T) {
+	// Arrange
+	repo := &mockBoardGameRepo{}
+	handler := NewBoardGameHandler(repo, nil)
+
+	//Missing required fields
+	body := []byte(`{"name": "Catan"}`)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/boardgames/1", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleBoardGameUpdate(ctx)
+
+	// Assert
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+
+	if repo.updateCalled {
+		t.Fatal("Update() should not be called on bad request")
+	}
+}
+
+func TestHandleBoardGameUpdate_PlayerRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxPlayers string // Left out when empty
+		status     int
+	}{
+		{name: "no max players", status: http.StatusOK},
+		{name: "max players of 0", maxPlayers: `"max_players": 0,`, status: http.StatusOK},
+		{name: "max below min", maxPlayers: `"max_players": 2,`, status: http.StatusBadRequest},
+		{name: "negative max", maxPlayers: `"max_players": -1,`, status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mockBoardGameRepo{}
+			handler := NewBoardGameHandler(repo, nil)
+
+			body := `{"name": "Catan", "min_players": 3, ` + tt.maxPlayers + ` "play_time": 90, "min_age": 10, "description": "Trade and build"}`
+			req := httptest.NewRequest(http.MethodPut, "/api/boardgames/1", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			ctx, rec := createTestContext(req)
+			ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			// Act
+			handler.HandleBoardGameUpdate(ctx)
+
+			// Assert
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d %s", tt.status, rec.Code, rec.Body)
+			}
+			if repo.updateCalled != (tt.status == http.StatusOK) {
+				t.Errorf("expected Update() to be called only for a valid range")
+			}
+		})
+	}
+}
+
+func TestHandleBoardGameUpdate_NotFound(t *testing.T) {
+	// Arrange
+	repo := &mockBoardGameRepo{
+		updateError: repository.ErrBoardGameNotFound,
+	}
+	handler := NewBoardGameHandler(repo, nil)
+
+	body := []byte(`{
+		"name": "Catan",
+		"min_players": 3,
+		"play_time": 90,
+		"min_age": 10,
+		"description": "Trade and build"
+	}`)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/boardgames/999", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "999"}}
+
+	// Act
+	handler.HandleBoardGameUpdate(ctx)
+
+	// Assert
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestHandleBoardGameUpdate_Forbidden(t *testing.T) {
+	// Arrange
+	repo := &mockBoardGameRepo{
+		updateError: repository.ErrForbidden,
+	}
+	handler := NewBoardGameHandler(repo, nil)
+
+	body := []byte(`{
+		"name": "Catan",
+		"min_players": 3,
+		"play_time": 90,
+		"min_age": 10,
+		"description": "Trade and build"
+	}`)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/boardgames/1", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleBoardGameUpdate(ctx)
+
+	// Assert
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a viewer, got %d", rec.Code)
+	}
+}
+
+func TestHandleBoardGamePatch_OK(t *testing.T) {
+	// Arrange
+	repo := &mockBoardGameRepo{}
+	handler := NewBoardGameHandler(repo, nil)
+
+	body := []byte(`{"name": "Honey Buzz: Fall Flowers", "max_players": null}`)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/boardgames/1", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleBoardGamePatch(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d %s", rec.Code, rec.Body)
+	}
+
+	if !repo.patchCalled {
+		t.Fatal("expected Patch() to be called on repository")
+	}
+
+	if len(repo.patchedFields) != 2 {
+		t.Fatalf("expected 2 patched fields, got %d", len(repo.patchedFields))
+	}
+
+	if repo.patchedFields["name"] != "Honey Buzz: Fall Flowers" {
+		t.Errorf("expected patched name, got %v", repo.patchedFields["name"])
+	}
+
+	if value, ok := repo.patchedFields["max_players"]; !ok || value != nil {
+		t.Errorf("expected max_players to be cleared, got %v", value)
+	}
+}
+
+func TestHandleBoardGamePatch_RequiredFieldRemoved(t *testing.T) {
+	// Arrange
+	repo := &mockBoardGameRepo{}
+	handler := NewBoardGameHandler(repo, nil)
+
+	body := []byte(`{"name": null}`)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/boardgames/1", bytes.NewReader(body))
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleBoardGamePatch(ctx)
+
+	// Assert
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+
+	if repo.patchCalled {
+		t.Fatal("Patch() should not be called when validation fails")
+	}
+}
+
+func TestHandleBoardGamePatch_UnknownField(t *testing.T) {
+	// Arrange
+	repo := &mockBoardGameRepo{}
+	handler := NewBoardGameHandler(repo, nil)
+
+	body := []byte(`{"created_at": "2020-01-01T00:00:00Z"}`)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/boardgames/1", bytes.NewReader(body))
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleBoardGamePatch(ctx)
+
+	// Assert
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+
+	if repo.patchCalled {
+		t.Fatal("Patch() should not be called for read-only fields")
+	}
+}
+
+func TestHandleBoardGamePatch_NotFound(t *testing.T) {
+	// Arrange
+	repo := &mockBoardGameRepo{
+		getByIDError: repository.ErrBoardGameNotFound,
+	}
+	handler := NewBoardGameHandler(repo, nil)
+
+	body := []byte(`{"play_time": 45}`)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/boardgames/999", bytes.NewReader(body))
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "999"}}
+
+	// Act
+	handler.HandleBoardGamePatch(ctx)
+
+	// Assert
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestHandleBoardGamePatch_RangeChangedMeanwhile(t *testing.T) {
+	// Arrange: the game read allows 4 players, min_players is raised before the write
+	repo := &mockBoardGameRepo{patchError: repository.ErrInvalidPlayerRange}
+	handler := NewBoardGameHandler(repo, nil)
+
+	body := []byte(`{"max_players": 3}`)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/boardgames/1", bytes.NewReader(body))
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleBoardGamePatch(ctx)
+
+	// Assert
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d %s", rec.Code, rec.Body)
+	}
+}
+
 // Helper mock repo and methods
 // Mocks in Go are about satisfying interfaces, not about test intent.
 type mockBoardGameRepo struct {
 	createCalled     bool
+	createError      error
 	getAllCalled     bool
 	getAllError      error
+	getAllFilter     models.BoardGameFilter
+	getAllNext       string
 	getByIDCalled    bool
 	getByIDError     error
+	getByIDGame      *models.BoardGame
 	deleteByIDCalled bool
 	deleteError      error
+	deletedIDs       []int64
+	withExpansions   bool
+	expansionTree    []int64 // Delete fails with ErrHasExpansions unless withExpansions
+	searchCalled     bool
+	searchText       string
+	recommendCalled  bool
+	criteria         models.RecommendationCriteria
+	updateCalled     bool
+	updateError      error
+	updatedGame      *models.BoardGame
+	patchCalled      bool
+	patchedFields    map[string]any
+	patchError       error
+	userIDs          []int64 // Shelf of every call
 }
 
-func (m *mockBoardGameRepo) Create(ctx context.Context, game *models.BoardGame) error {
+func (m *mockBoardGameRepo) Create(ctx context.Context, userID int64, game *models.BoardGame) error {
 	m.createCalled = true
-	return nil
+	m.userIDs = append(m.userIDs, userID)
+	return m.createError
 }
 
-func (m *mockBoardGameRepo) GetAll(ctx context.Context) ([]*models.BoardGame, error) {
+func (m *mockBoardGameRepo) GetAll(ctx context.Context, userID int64, filter models.BoardGameFilter) (*models.BoardGamePage, error) {
 	m.getAllCalled = true
+	m.getAllFilter = filter
+	m.userIDs = append(m.userIDs, userID)
 
 	if m.getAllError != nil {
 		return nil, m.getAllError
 	}
 
-	return []*models.BoardGame{
-		{Name: "Honey Buzz", MinPlayers: 2, MaxPlayers: 4, PlayTime: 30, MinAge: 6, Description: "A sweet game"},
+	return &models.BoardGamePage{
+		Games: []*models.BoardGame{
+			{Name: "Honey Buzz", MinPlayers: 2, MaxPlayers: 4, PlayTime: 30, MinAge: 6, Description: "A sweet game"},
+		},
+		Total: 1,
+		Next:  m.getAllNext,
 	}, nil
 }
 
-func (m *mockBoardGameRepo) GetByID(ctx context.Context, id int64) (*models.BoardGame, error) {
+func (m *mockBoardGameRepo) GetByID(ctx context.Context, userID int64, id int64) (*models.BoardGame, error) {
 	m.getByIDCalled = true
+	m.userIDs = append(m.userIDs, userID)
 	if m.getByIDError != nil {
 		return nil, m.getByIDError
 	}
 
+	if m.getByIDGame != nil {
+		return m.getByIDGame, nil
+	}
+
 	dummy := &models.BoardGame{Name: "Honey Buzz", MinPlayers: 2, MaxPlayers: 4, PlayTime: 30, MinAge: 6, Description: "A sweet game"}
 	return dummy, nil
 }
 
-func (m *mockBoardGameRepo) Delete(ctx context.Context, id int64) error {
+func (m *mockBoardGameRepo) Search(ctx context.Context, userID int64, text string, limit int) ([]*models.BoardGameSearchResult, error) {
+	m.searchCalled = true
+	m.searchText = text
+	return []*models.BoardGameSearchResult{
+		{BoardGame: models.BoardGame{ID: 1, Name: "Catan", MinPlayers: 3, MaxPlayers: 4}, Rank: 0.6},
+	}, nil
+}
+
+func (m *mockBoardGameRepo) Recommend(ctx context.Context, userID int64, criteria models.RecommendationCriteria) ([]*models.Recommendation, error) {
+	m.recommendCalled = true
+	m.criteria = criteria
+	return []*models.Recommendation{
+		{BoardGame: models.BoardGame{ID: 1, Name: "Catan", MinPlayers: 3, MaxPlayers: 4}, Score: 3.5, Reason: "fits 3 players, 60 min, ages 10+"},
+	}, nil
+}
+
+func (m *mockBoardGameRepo) Update(ctx context.Context, userID int64, game *models.BoardGame) error {
+	m.updateCalled = true
+	m.updatedGame = game
+	return m.updateError
+}
+
+func (m *mockBoardGameRepo) Patch(ctx context.Context, userID int64, id int64, fields map[string]any) (*models.BoardGame, error) {
+	m.patchCalled = true
+	m.patchedFields = fields
+	if m.patchError != nil {
+		return nil, m.patchError
+	}
+	return &models.BoardGame{ID: id, Name: "Honey Buzz", MinPlayers: 2, PlayTime: 30, MinAge: 6, Description: "A sweet game"}, nil
+}
+
+func (m *mockBoardGameRepo) Delete(ctx context.Context, userID int64, id int64, withExpansions bool) error {
 	m.deleteByIDCalled = true
+	m.withExpansions = withExpansions
 	if m.deleteError != nil {
 		return m.deleteError
 	}
+	if len(m.expansionTree) > 0 && !withExpansions {
+		return repository.ErrHasExpansions
+	}
+	m.deletedIDs = append(m.deletedIDs, append(m.expansionTree, id)...)
 	return nil
 }
 
+func (m *mockBoardGameRepo) Access(ctx context.Context, userID int64, id int64) (models.HouseholdRole, error) {
+	return models.RoleOwner, m.getByIDError
+}
+
 type mockBoardGameImageRepo struct {
 	createCalled     bool
 	getAllCalled     bool
 	getByIDCalled    bool
 	deleteByIDCalled bool
+	deleteError      error
+	getImageError    error
+	reorderCalled    bool
+	reorderIDs       []int64
+	reorderError     error
+	variants         []models.ImageVariant
+	savedImage       *models.BoardGameImage
+	coverMode        repository.CoverMode
+	currentCoverID   int64
+	userID           int64
 }
 
-func (m *mockBoardGameImageRepo) SaveImage(ctx context.Context, image *models.BoardGameImage) error {
+func (m *mockBoardGameImageRepo) SaveImage(ctx context.Context, userID int64, image *models.BoardGameImage, coverMode repository.CoverMode) (int64, error) {
 	m.createCalled = true
-	return nil
+	m.savedImage = image
+	m.userID = userID
+	m.coverMode = coverMode
+	image.ID = 10
+	if m.currentCoverID == 0 || image.ImageType != "cover" {
+		return 0, nil
+	}
+	if coverMode == repository.CoverModeReject {
+		return 0, repository.ErrCoverExists
+	}
+	return m.currentCoverID, nil
 }
 
-func (m *mockBoardGameImageRepo) GetAllImagesForBoardGame(ctx context.Context, boardGameId int64, imageType string) ([]*models.BoardGameImage, error) {
+func (m *mockBoardGameImageRepo) GetAllImagesForBoardGame(ctx context.Context, userID int64, boardGameId int64, imageType string) ([]*models.BoardGameImage, error) {
 	m.getAllCalled = true
 	return []*models.BoardGameImage{}, nil
 }
 
-func (m *mockBoardGameImageRepo) GetCoverThumbnail(ctx context.Context, boardGameId int64) (*models.BoardGameImage, error) {
+func (m *mockBoardGameImageRepo) ListImages(ctx context.Context, userID int64, boardGameId int64) ([]*models.BoardGameImage, error) {
+	m.getAllCalled = true
+	return []*models.BoardGameImage{
+		{ID: 1, BoardGameID: boardGameId, ImageType: "cover", ImageMimeType: "image/png", URL: models.ImageURL(1, ""), Variants: m.variants},
+		{ID: 2, BoardGameID: boardGameId, ImageType: "gameplay", ImageMimeType: "image/jpeg", URL: models.ImageURL(2, "")},
+	}, nil
+}
+
+func (m *mockBoardGameImageRepo) GetImageByID(ctx context.Context, userID int64, id int64) (*models.BoardGameImage, error) {
+	m.getByIDCalled = true
+	if m.getImageError != nil {
+		return nil, m.getImageError
+	}
+	return &models.BoardGameImage{ID: id, ImageMimeType: "image/png", ImageData: []byte("full image"),
+		ContentHash: "f00dcafe", UploadedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}, nil
+}
+
+func (m *mockBoardGameImageRepo) GetThumbnailByID(ctx context.Context, userID int64, id int64) (*models.BoardGameImage, error) {
+	m.getByIDCalled = true
+	if m.getImageError != nil {
+		return nil, m.getImageError
+	}
+	return &models.BoardGameImage{ID: id, ImageMimeType: "image/jpeg", ThumbnailData: []byte("thumbnail")}, nil
+}
+
+func (m *mockBoardGameImageRepo) GetCoverThumbnail(ctx context.Context, userID int64, boardGameId int64) (*models.BoardGameImage, error) {
 	m.getByIDCalled = true
 	return &models.BoardGameImage{}, nil
 }
 
-func (m *mockBoardGameImageRepo) DeleteImage(ctx context.Context, id int64) error {
+func (m *mockBoardGameImageRepo) ListVariants(ctx context.Context, userID int64, imageID int64) ([]models.ImageVariant, error) {
+	return m.variants, nil
+}
+
+func (m *mockBoardGameImageRepo) GetVariant(ctx context.Context, userID int64, imageID int64, width int, mimeType string) (*models.ImageVariant, error) {
+	for _, variant := range m.variants {
+		if variant.Width == width && variant.MimeType == mimeType {
+			variant.Data = []byte(fmt.Sprintf("%d %s", width, mimeType))
+			return &variant, nil
+		}
+	}
+	return nil, repository.ErrImageNotFound
+}
+
+func (m *mockBoardGameImageRepo) ReorderImages(ctx context.Context, userID int64, boardGameId int64, imageIDs []int64) error {
+	m.reorderCalled = true
+	m.reorderIDs = imageIDs
+	return m.reorderError
+}
+
+func (m *mockBoardGameImageRepo) DeleteImage(ctx context.Context, userID int64, id int64) error {
 	m.deleteByIDCalled = true
-	return nil
+	return m.deleteError
 }
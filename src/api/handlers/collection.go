@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/api/middleware"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/collection"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// CSV and JSON exports of the whole shelf, and importing them back
+type CollectionHandler struct {
+	repo repository.CollectionRepo
+}
+
+func NewCollectionHandler(repo repository.CollectionRepo) *CollectionHandler {
+	return &CollectionHandler{repo: repo}
+}
+
+// Downloads every game, ?format=csv or json (default)
+func (h *CollectionHandler) HandleExport(c *gin.Context) {
+	format := c.DefaultQuery("format", collection.FormatJSON)
+	if format != collection.FormatCSV && format != collection.FormatJSON {
+		c.JSON(http.StatusBadRequest, gin.H{"error": collection.ErrUnknownFormat.Error()})
+		return
+	}
+
+	games, err := h.repo.ExportBoardGames(c.Request.Context(), middleware.UserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	// Written to memory first so a failure can still answer 500
+	var file bytes.Buffer
+	if err := collection.Write(&file, format, games); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	filename := fmt.Sprintf("my-game-shelf-%s.%s", time.Now().Format("2006-01-02"), format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, collection.ContentType(format), file.Bytes())
+}
+
+// Imports a file from HandleExport, sent as the "file" form field or as the
+// request body. ?on_duplicate=skip, update or fail (default) says what to do
+// with games already in the household the games go to. Invalid rows are left out and listed with
+// their line number in the report's errors.
+func (h *CollectionHandler) HandleImport(c *gin.Context) {
+	onDuplicate := c.DefaultQuery("on_duplicate", models.DuplicateFail)
+	switch onDuplicate {
+	case models.DuplicateSkip, models.DuplicateUpdate, models.DuplicateFail:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid on_duplicate: must be skip, update or fail"})
+		return
+	}
+
+	file, filename, ok := openImportFile(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	games, rowErrors, err := collection.Parse(file, importFormat(c, filename))
+	if err != nil {
+		switch {
+		case isFileTooLarge(err):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File too large (max 64MB)"})
+		case errors.Is(err, collection.ErrInvalidFile), errors.Is(err, collection.ErrUnknownFormat):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	report, err := h.repo.ImportBoardGames(c.Request.Context(), middleware.UserID(c), games, onDuplicate)
+	if errors.Is(err, repository.ErrForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil && !errors.Is(err, repository.ErrDuplicateName) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	report.Errors = append(report.Errors, rowErrors...)
+	sort.SliceStable(report.Errors, func(i, j int) bool { return report.Errors[i].Line < report.Errors[j].Line })
+
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Nothing was imported: " + err.Error(), "errors": report.Errors})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ?format= first, then the file extension or content type; JSON when nothing says
+func importFormat(c *gin.Context, filename string) string {
+	if format := c.Query("format"); format != "" {
+		return format
+	}
+
+	switch {
+	case strings.EqualFold(filepath.Ext(filename), ".csv"):
+		return collection.FormatCSV
+	case filename == "" && strings.Contains(c.ContentType(), "csv"):
+		return collection.FormatCSV
+	default:
+		return collection.FormatJSON
+	}
+}
@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+)
+
+func TestHandleExport_CSV(t *testing.T) {
+	// Arrange
+	repo := &mockCollectionRepo{
+		exported: []*models.CollectionGame{
+			{ID: 1, Name: "Catan", MinPlayers: 3, MaxPlayers: 4, PlayTime: 90, MinAge: 10, Description: "Trade",
+				Tags: []models.TagRef{{Name: "Strategy", Kind: "category"}}},
+		},
+	}
+	handler := NewCollectionHandler(repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/export?format=csv", nil)
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleExport(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/csv") {
+		t.Errorf("expected a CSV file, got %q", rec.Header().Get("Content-Type"))
+	}
+
+	if !strings.Contains(rec.Header().Get("Content-Disposition"), ".csv") {
+		t.Errorf("expected a .csv attachment, got %q", rec.Header().Get("Content-Disposition"))
+	}
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], "1,Catan,3,4,90,10,Trade,,,category:Strategy,") {
+		t.Errorf("unexpected export %q", rec.Body.String())
+	}
+}
+
+func TestHandleExport_InvalidFormat(t *testing.T) {
+	// Arrange
+	repo := &mockCollectionRepo{}
+	handler := NewCollectionHandler(repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/export?format=xml", nil)
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleExport(ctx)
+
+	// Assert
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestHandleImport_ReportsRowErrors(t *testing.T) {
+	// Arrange
+	repo := &mockCollectionRepo{}
+	handler := NewCollectionHandler(repo)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("file", "shelf.csv")
+	part.Write([]byte("name,min_players,play_time,min_age,description\n" +
+		"Catan,3,90,10,Trade\n" +
+		"Skull,,30,10,Bluff\n"))
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/import?on_duplicate=skip", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleImport(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d %s", rec.Code, rec.Body)
+	}
+
+	if repo.onDuplicate != models.DuplicateSkip || len(repo.imported) != 1 || repo.imported[0].Name != "Catan" {
+		t.Fatalf("expected only Catan to be imported with skip, got %q %+v", repo.onDuplicate, repo.imported)
+	}
+
+	var report models.CollectionImportReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("failed to unmarshal response JSON: %v", err)
+	}
+
+	if len(report.Errors) != 1 || report.Errors[0].Line != 3 || report.Errors[0].Name != "Skull" {
+		t.Errorf("expected Skull to be reported on line 3, got %+v", report.Errors)
+	}
+}
+
+func TestHandleImport_DuplicateFails(t *testing.T) {
+	// Arrange
+	repo := &mockCollectionRepo{duplicates: true}
+	handler := NewCollectionHandler(repo)
+
+	body := `[{"name": "Catan", "min_players": 3, "play_time": 90, "min_age": 10, "description": "Trade"}]`
+	req := httptest.NewRequest(http.MethodPost, "/api/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleImport(ctx)
+
+	// Assert
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", rec.Code)
+	}
+
+	if repo.onDuplicate != models.DuplicateFail {
+		t.Errorf("expected fail to be the default, got %q", repo.onDuplicate)
+	}
+
+	var response struct {
+		Errors []models.CollectionRowError `json:"errors"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response JSON: %v", err)
+	}
+
+	if len(response.Errors) != 1 || response.Errors[0].Line != 1 {
+		t.Errorf("expected the duplicate on line 1, got %+v", response.Errors)
+	}
+}
+
+func TestHandleImport_RoundTripsImages(t *testing.T) {
+	for _, format := range []string{"csv", "json"} {
+		t.Run(format, func(t *testing.T) {
+			// Arrange
+			repo := &mockCollectionRepo{
+				exported: []*models.CollectionGame{
+					{ID: 1, Name: "Catan", MinPlayers: 3, MaxPlayers: 4, PlayTime: 90, MinAge: 10, Description: "Trade",
+						Images: []models.BoardGameImageRef{{ID: 5, Type: "cover"}, {ID: 6, Type: "gallery"}}},
+				},
+			}
+			handler := NewCollectionHandler(repo)
+
+			exportReq := httptest.NewRequest(http.MethodGet, "/api/export?format="+format, nil)
+			exportCtx, exported := createTestContext(exportReq)
+			handler.HandleExport(exportCtx)
+			if exported.Code != http.StatusOK {
+				t.Fatalf("expected the export to answer 200, got %d", exported.Code)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/import?format="+format, exported.Body)
+			ctx, rec := createTestContext(req)
+
+			// Act
+			handler.HandleImport(ctx)
+
+			// Assert
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d %s", rec.Code, rec.Body)
+			}
+
+			if len(repo.imported) != 1 || len(repo.imported[0].Images) != 2 ||
+				repo.imported[0].Images[0].ID != 5 || repo.imported[0].Images[1].Type != "gallery" {
+				t.Fatalf("expected both image references to reach the repository, got %+v", repo.imported)
+			}
+
+			var report models.CollectionImportReport
+			if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+				t.Fatalf("failed to unmarshal response JSON: %v", err)
+			}
+
+			if len(report.Created) != 1 || len(report.Created[0].Images) != 2 ||
+				report.Created[0].Images[0].Status != models.ImageRestored {
+				t.Errorf("expected the restored images in the report, got %+v", report.Created)
+			}
+		})
+	}
+}
+
+func TestHandleImport_InvalidRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		body string
+	}{
+		{"unknown strategy", "/api/import?on_duplicate=merge", "[]"},
+		{"unknown format", "/api/import?format=xml", "<items/>"},
+		{"not an export", "/api/import", `{"name": "Catan"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mockCollectionRepo{}
+			handler := NewCollectionHandler(repo)
+
+			req := httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+			ctx, rec := createTestContext(req)
+
+			// Act
+			handler.HandleImport(ctx)
+
+			// Assert
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", rec.Code)
+			}
+
+			if repo.imported != nil {
+				t.Fatal("expected ImportBoardGames() not to be called")
+			}
+		})
+	}
+}
+
+// Imports create every game and restore its images, or report them all as duplicates
+type mockCollectionRepo struct {
+	err         error
+	exported    []*models.CollectionGame
+	imported    []*models.CollectionGame
+	onDuplicate string
+	duplicates  bool
+}
+
+func (m *mockCollectionRepo) ExportBoardGames(ctx context.Context, userID int64) ([]*models.CollectionGame, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.exported, nil
+}
+
+func (m *mockCollectionRepo) ImportBoardGames(ctx context.Context, userID int64, games []*models.CollectionGame, onDuplicate string) (*models.CollectionImportReport, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.imported = games
+	m.onDuplicate = onDuplicate
+
+	report := &models.CollectionImportReport{Errors: []models.CollectionRowError{}}
+	for i, game := range games {
+		if m.duplicates {
+			report.Errors = append(report.Errors, models.CollectionRowError{
+				Line: game.Line, Name: game.Name, Error: repository.ErrDuplicateName.Error(),
+			})
+			continue
+		}
+		entry := models.CollectionImportEntry{Line: game.Line, BoardGameID: int64(i + 1), Name: game.Name}
+		for _, image := range game.Images {
+			entry.Images = append(entry.Images, models.CollectionImageResult{ID: image.ID, Type: image.Type, Status: models.ImageRestored})
+		}
+		report.Created = append(report.Created, entry)
+	}
+
+	if m.duplicates {
+		return report, repository.ErrDuplicateName
+	}
+	return report, nil
+}
@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/api/middleware"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// Designers, artists and publishers
+type CreditHandler struct {
+	repo repository.CreditRepo
+}
+
+func NewCreditHandler(repo repository.CreditRepo) *CreditHandler {
+	return &CreditHandler{repo: repo}
+}
+
+func (h *CreditHandler) HandleCreatePerson(c *gin.Context) {
+	var person models.Person
+	if err := c.ShouldBindJSON(&person); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.repo.CreatePerson(c.Request.Context(), &person); err != nil {
+		respondCreditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, person)
+}
+
+// Everyone credited on a game, ?role=designer or ?role=artist for one role only
+func (h *CreditHandler) HandleGetPeople(c *gin.Context) {
+	role, ok := parseRole(c)
+	if !ok {
+		return
+	}
+	h.getPeople(c, role)
+}
+
+func (h *CreditHandler) HandleGetDesigners(c *gin.Context) {
+	h.getPeople(c, models.RoleDesigner)
+}
+
+func (h *CreditHandler) HandleGetArtists(c *gin.Context) {
+	h.getPeople(c, models.RoleArtist)
+}
+
+func (h *CreditHandler) getPeople(c *gin.Context, role string) {
+	people, err := h.repo.GetPeople(c.Request.Context(), role)
+	if err != nil {
+		respondCreditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, people)
+}
+
+func (h *CreditHandler) HandleGetPersonByID(c *gin.Context) {
+	id, ok := parseCreditID(c, "Invalid person ID")
+	if !ok {
+		return
+	}
+
+	person, err := h.repo.GetPersonByID(c.Request.Context(), id)
+	if err != nil {
+		respondCreditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, person)
+}
+
+func (h *CreditHandler) HandleUpdatePerson(c *gin.Context) {
+	id, ok := parseCreditID(c, "Invalid person ID")
+	if !ok {
+		return
+	}
+
+	var person models.Person
+	if err := c.ShouldBindJSON(&person); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	person.ID = id
+
+	if err := h.repo.UpdatePerson(c.Request.Context(), &person); err != nil {
+		respondCreditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, person)
+}
+
+// Removes the person from every game
+func (h *CreditHandler) HandleDeletePerson(c *gin.Context) {
+	id, ok := parseCreditID(c, "Invalid person ID")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeletePerson(c.Request.Context(), id); err != nil {
+		respondCreditError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	c.Writer.WriteHeaderNow()
+}
+
+// Games of the person in any role, ?role= narrows it down
+func (h *CreditHandler) HandleGetPersonGames(c *gin.Context) {
+	role, ok := parseRole(c)
+	if !ok {
+		return
+	}
+	h.getPersonGames(c, role)
+}
+
+func (h *CreditHandler) HandleGetDesignerGames(c *gin.Context) {
+	h.getPersonGames(c, models.RoleDesigner)
+}
+
+func (h *CreditHandler) HandleGetArtistGames(c *gin.Context) {
+	h.getPersonGames(c, models.RoleArtist)
+}
+
+func (h *CreditHandler) getPersonGames(c *gin.Context, role string) {
+	id, ok := parseCreditID(c, "Invalid person ID")
+	if !ok {
+		return
+	}
+
+	games, err := h.repo.GetPersonGames(c.Request.Context(), middleware.UserID(c), id, role)
+	if err != nil {
+		respondCreditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, games)
+}
+
+func (h *CreditHandler) HandleCreatePublisher(c *gin.Context) {
+	var publisher models.Publisher
+	if err := c.ShouldBindJSON(&publisher); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.repo.CreatePublisher(c.Request.Context(), &publisher); err != nil {
+		respondCreditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, publisher)
+}
+
+func (h *CreditHandler) HandleGetPublishers(c *gin.Context) {
+	publishers, err := h.repo.GetPublishers(c.Request.Context())
+	if err != nil {
+		respondCreditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, publishers)
+}
+
+func (h *CreditHandler) HandleGetPublisherByID(c *gin.Context) {
+	id, ok := parseCreditID(c, "Invalid publisher ID")
+	if !ok {
+		return
+	}
+
+	publisher, err := h.repo.GetPublisherByID(c.Request.Context(), id)
+	if err != nil {
+		respondCreditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, publisher)
+}
+
+func (h *CreditHandler) HandleUpdatePublisher(c *gin.Context) {
+	id, ok := parseCreditID(c, "Invalid publisher ID")
+	if !ok {
+		return
+	}
+
+	var publisher models.Publisher
+	if err := c.ShouldBindJSON(&publisher); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	publisher.ID = id
+
+	if err := h.repo.UpdatePublisher(c.Request.Context(), &publisher); err != nil {
+		respondCreditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, publisher)
+}
+
+func (h *CreditHandler) HandleDeletePublisher(c *gin.Context) {
+	id, ok := parseCreditID(c, "Invalid publisher ID")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeletePublisher(c.Request.Context(), id); err != nil {
+		respondCreditError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	c.Writer.WriteHeaderNow()
+}
+
+func (h *CreditHandler) HandleGetPublisherGames(c *gin.Context) {
+	id, ok := parseCreditID(c, "Invalid publisher ID")
+	if !ok {
+		return
+	}
+
+	games, err := h.repo.GetPublisherGames(c.Request.Context(), middleware.UserID(c), id)
+	if err != nil {
+		respondCreditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, games)
+}
+
+// Replaces the designers, artists and publishers of the game and returns it
+func (h *CreditHandler) HandleSetBoardGameCredits(c *gin.Context) {
+	boardGameID, ok := parseCreditID(c, "Invalid board game ID")
+	if !ok {
+		return
+	}
+
+	var credits models.BoardGameCredits
+	if err := c.ShouldBindJSON(&credits); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	game, err := h.repo.SetBoardGameCredits(c.Request.Context(), boardGameID, &credits)
+	if err != nil {
+		respondCreditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, game)
+}
+
+func parseRole(c *gin.Context) (string, bool) {
+	role := c.Query("role")
+	switch role {
+	case "", models.RoleDesigner, models.RoleArtist:
+		return role, true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role: must be designer or artist"})
+		return "", false
+	}
+}
+
+func parseCreditID(c *gin.Context, message string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return 0, false
+	}
+	return id, true
+}
+
+func respondCreditError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrPersonNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Person not found"})
+	case errors.Is(err, repository.ErrPublisherNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Publisher not found"})
+	case errors.Is(err, repository.ErrBoardGameNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board game not found"})
+	case errors.Is(err, repository.ErrDuplicatePersonName), errors.Is(err, repository.ErrDuplicatePublisherName):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
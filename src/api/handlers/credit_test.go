@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandleCreatePerson_Created(t *testing.T) {
+	// Arrange
+	repo := &mockCreditRepo{}
+	handler := NewCreditHandler(repo)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/people", bytes.NewReader([]byte(`{"name": "Uwe Rosenberg"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleCreatePerson(ctx)
+
+	// Assert
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d %s", rec.Code, rec.Body)
+	}
+
+	if repo.createdPerson == nil || repo.createdPerson.Name != "Uwe Rosenberg" {
+		t.Fatalf("expected CreatePerson() to be called, got %+v", repo.createdPerson)
+	}
+}
+
+func TestHandleCreatePublisher_DuplicateName(t *testing.T) {
+	// Arrange
+	handler := NewCreditHandler(&mockCreditRepo{err: repository.ErrDuplicatePublisherName})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/publishers", bytes.NewReader([]byte(`{"name": "Lookout Games"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleCreatePublisher(ctx)
+
+	// Assert
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", rec.Code)
+	}
+}
+
+func TestHandleGetPeople_InvalidRole(t *testing.T) {
+	// Arrange
+	repo := &mockCreditRepo{}
+	handler := NewCreditHandler(repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/people?role=publisher", nil)
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleGetPeople(ctx)
+
+	// Assert
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+
+	if repo.role != "" {
+		t.Fatal("expected GetPeople() not to be called")
+	}
+}
+
+func TestHandleGetDesignerGames_OK(t *testing.T) {
+	// Arrange
+	repo := &mockCreditRepo{}
+	handler := NewCreditHandler(repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/designers/4/games", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "4"}}
+
+	// Act
+	handler.HandleGetDesignerGames(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d %s", rec.Code, rec.Body)
+	}
+
+	if repo.personID != 4 || repo.role != models.RoleDesigner {
+		t.Errorf("expected the designer games of person 4, got person %d as %q", repo.personID, repo.role)
+	}
+
+	var response []models.BoardGame
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response JSON: %v", err)
+	}
+
+	if len(response) != 1 || response[0].Name != "Agricola" {
+		t.Errorf("expected Agricola, got %+v", response)
+	}
+}
+
+func TestHandleGetPersonGames_NotFound(t *testing.T) {
+	// Arrange
+	handler := NewCreditHandler(&mockCreditRepo{err: repository.ErrPersonNotFound})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/people/99/games", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "99"}}
+
+	// Act
+	handler.HandleGetPersonGames(ctx)
+
+	// Assert
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestHandleSetBoardGameCredits_OK(t *testing.T) {
+	// Arrange
+	repo := &mockCreditRepo{}
+	handler := NewCreditHandler(repo)
+
+	body := `{"designers": [{"name": "Uwe Rosenberg"}], "artists": [{"id": 7}],
+		"publishers": [{"name": "Lookout Games", "year_published": 2007}]}`
+	req := httptest.NewRequest(http.MethodPut, "/api/boardgames/1/credits", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleSetBoardGameCredits(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d %s", rec.Code, rec.Body)
+	}
+
+	credits := repo.credits
+	if credits == nil || len(credits.Designers) != 1 || len(credits.Artists) != 1 || len(credits.Publishers) != 1 {
+		t.Fatalf("expected one designer, artist and publisher, got %+v", credits)
+	}
+
+	if credits.Artists[0].ID == nil || *credits.Artists[0].ID != 7 {
+		t.Errorf("expected artist 7, got %+v", credits.Artists[0])
+	}
+
+	if year := credits.Publishers[0].YearPublished; year == nil || *year != 2007 {
+		t.Errorf("expected year published 2007, got %v", year)
+	}
+}
+
+func TestHandleSetBoardGameCredits_BadRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"credit without id or name", `{"designers": [{}]}`},
+		{"year out of range", `{"publishers": [{"name": "Lookout Games", "year_published": 20}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mockCreditRepo{}
+			handler := NewCreditHandler(repo)
+
+			req := httptest.NewRequest(http.MethodPut, "/api/boardgames/1/credits", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+			ctx, rec := createTestContext(req)
+			ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			// Act
+			handler.HandleSetBoardGameCredits(ctx)
+
+			// Assert
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", rec.Code)
+			}
+
+			if repo.credits != nil {
+				t.Fatal("expected SetBoardGameCredits() not to be called")
+			}
+		})
+	}
+}
+
+type mockCreditRepo struct {
+	err           error
+	createdPerson *models.Person
+	personID      int64
+	role          string
+	credits       *models.BoardGameCredits
+}
+
+func (m *mockCreditRepo) CreatePerson(ctx context.Context, person *models.Person) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.createdPerson = person
+	person.ID = 1
+	return nil
+}
+
+func (m *mockCreditRepo) GetPeople(ctx context.Context, role string) ([]*models.Person, error) {
+	m.role = role
+	return []*models.Person{}, m.err
+}
+
+func (m *mockCreditRepo) GetPersonByID(ctx context.Context, id int64) (*models.Person, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.Person{ID: id, Name: "Uwe Rosenberg"}, nil
+}
+
+func (m *mockCreditRepo) UpdatePerson(ctx context.Context, person *models.Person) error {
+	return m.err
+}
+
+func (m *mockCreditRepo) DeletePerson(ctx context.Context, id int64) error {
+	return m.err
+}
+
+func (m *mockCreditRepo) GetPersonGames(ctx context.Context, userID int64, id int64, role string) ([]*models.BoardGame, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.personID = id
+	m.role = role
+	return []*models.BoardGame{{ID: 1, Name: "Agricola"}}, nil
+}
+
+func (m *mockCreditRepo) CreatePublisher(ctx context.Context, publisher *models.Publisher) error {
+	if m.err != nil {
+		return m.err
+	}
+	publisher.ID = 1
+	return nil
+}
+
+func (m *mockCreditRepo) GetPublishers(ctx context.Context) ([]*models.Publisher, error) {
+	return []*models.Publisher{}, m.err
+}
+
+func (m *mockCreditRepo) GetPublisherByID(ctx context.Context, id int64) (*models.Publisher, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.Publisher{ID: id, Name: "Lookout Games"}, nil
+}
+
+func (m *mockCreditRepo) UpdatePublisher(ctx context.Context, publisher *models.Publisher) error {
+	return m.err
+}
+
+func (m *mockCreditRepo) DeletePublisher(ctx context.Context, id int64) error {
+	return m.err
+}
+
+func (m *mockCreditRepo) GetPublisherGames(ctx context.Context, userID int64, id int64) ([]*models.BoardGame, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []*models.BoardGame{{ID: 1, Name: "Agricola"}}, nil
+}
+
+func (m *mockCreditRepo) SetBoardGameCredits(ctx context.Context, boardGameID int64, credits *models.BoardGameCredits) (*models.BoardGame, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.credits = credits
+	return &models.BoardGame{ID: boardGameID, Name: "Agricola"}, nil
+}
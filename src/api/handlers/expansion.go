@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/api/middleware"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+type ExpansionHandler struct {
+	repo repository.ExpansionRepo
+}
+
+func NewExpansionHandler(repo repository.ExpansionRepo) *ExpansionHandler {
+	return &ExpansionHandler{repo: repo}
+}
+
+// Makes the game in :id an expansion of {"base_game_id": ..., "relation": ...},
+// optionally with the max_players and play_time it brings
+func (h *ExpansionHandler) HandleSetBaseGame(c *gin.Context) {
+	id, ok := parseExpansionGameID(c)
+	if !ok {
+		return
+	}
+
+	var link models.ExpansionLink
+	if err := c.ShouldBindJSON(&link); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	game, err := h.repo.SetBaseGame(c.Request.Context(), middleware.UserID(c), id, &link)
+	if err != nil {
+		respondExpansionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, game)
+}
+
+func (h *ExpansionHandler) HandleRemoveBaseGame(c *gin.Context) {
+	id, ok := parseExpansionGameID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.RemoveBaseGame(c.Request.Context(), middleware.UserID(c), id); err != nil {
+		respondExpansionError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	c.Writer.WriteHeaderNow()
+}
+
+func (h *ExpansionHandler) HandleGetExpansions(c *gin.Context) {
+	id, ok := parseExpansionGameID(c)
+	if !ok {
+		return
+	}
+
+	games, err := h.repo.GetExpansions(c.Request.Context(), middleware.UserID(c), id)
+	if err != nil {
+		respondExpansionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, games)
+}
+
+func parseExpansionGameID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board game ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+func respondExpansionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrBoardGameNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board game not found"})
+	case errors.Is(err, repository.ErrNotAnExpansion):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrExpansionCycle):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
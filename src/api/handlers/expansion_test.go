@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandleSetBaseGame_OK(t *testing.T) {
+	// Arrange
+	repo := &mockExpansionRepo{}
+	handler := NewExpansionHandler(repo)
+
+	body := `{"base_game_id": 1, "relation": "expansion", "max_players": 6, "play_time": 150}`
+	req := httptest.NewRequest(http.MethodPut, "/api/boardgames/2/base", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "2"}}
+
+	// Act
+	handler.HandleSetBaseGame(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d %s", rec.Code, rec.Body)
+	}
+
+	if repo.expansionID != 2 || repo.link == nil || repo.link.BaseGameID != 1 {
+		t.Fatalf("expected game 2 to expand game 1, got %d %+v", repo.expansionID, repo.link)
+	}
+
+	if repo.link.MaxPlayers == nil || *repo.link.MaxPlayers != 6 {
+		t.Errorf("expected max players 6, got %v", repo.link.MaxPlayers)
+	}
+
+	var response models.BoardGame
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response JSON: %v", err)
+	}
+
+	if response.BaseGame == nil || response.BaseGame.ID != 1 {
+		t.Errorf("expected the base game in the response, got %+v", response.BaseGame)
+	}
+}
+
+func TestHandleSetBaseGame_BadRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		repoErr error
+	}{
+		{name: "missing base game", body: `{"relation": "promo"}`},
+		{name: "unknown relation", body: `{"base_game_id": 1, "relation": "sequel"}`},
+		{name: "cycle", body: `{"base_game_id": 3, "relation": "expansion"}`, repoErr: repository.ErrExpansionCycle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := NewExpansionHandler(&mockExpansionRepo{err: tt.repoErr})
+
+			req := httptest.NewRequest(http.MethodPut, "/api/boardgames/2/base", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+			ctx, rec := createTestContext(req)
+			ctx.Params = gin.Params{{Key: "id", Value: "2"}}
+
+			// Act
+			handler.HandleSetBaseGame(ctx)
+
+			// Assert
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestHandleRemoveBaseGame_NotAnExpansion(t *testing.T) {
+	// Arrange
+	handler := NewExpansionHandler(&mockExpansionRepo{err: repository.ErrNotAnExpansion})
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/boardgames/1/base", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleRemoveBaseGame(ctx)
+
+	// Assert
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestHandleGetExpansions_OK(t *testing.T) {
+	// Arrange
+	handler := NewExpansionHandler(&mockExpansionRepo{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/boardgames/1/expansions", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleGetExpansions(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var response []models.BoardGame
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response JSON: %v", err)
+	}
+
+	if len(response) != 1 || response[0].Name != "Farmers of the Moor" {
+		t.Errorf("expected one expansion, got %+v", response)
+	}
+}
+
+type mockExpansionRepo struct {
+	err         error
+	expansionID int64
+	link        *models.ExpansionLink
+}
+
+func (m *mockExpansionRepo) SetBaseGame(ctx context.Context, userID int64, expansionID int64, link *models.ExpansionLink) (*models.BoardGame, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.expansionID = expansionID
+	m.link = link
+	return &models.BoardGame{
+		ID:       expansionID,
+		Name:     "Farmers of the Moor",
+		BaseGame: &models.ExpansionRef{ID: link.BaseGameID, Name: "Agricola", Relation: link.Relation},
+	}, nil
+}
+
+func (m *mockExpansionRepo) RemoveBaseGame(ctx context.Context, userID int64, expansionID int64) error {
+	return m.err
+}
+
+func (m *mockExpansionRepo) GetExpansions(ctx context.Context, userID int64, baseGameID int64) ([]*models.BoardGame, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []*models.BoardGame{{ID: 2, Name: "Farmers of the Moor"}}, nil
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/api/middleware"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/auth"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// How long an invitation can be accepted
+const InvitationTTL = 7 * 24 * time.Hour
+
+// Households, their members and invitations, always as the signed in user
+type HouseholdHandler struct {
+	repo repository.HouseholdRepo
+}
+
+func NewHouseholdHandler(repo repository.HouseholdRepo) *HouseholdHandler {
+	return &HouseholdHandler{repo: repo}
+}
+
+// The signed in user becomes the owner
+func (h *HouseholdHandler) HandleCreateHousehold(c *gin.Context) {
+	name, ok := bindHouseholdName(c)
+	if !ok {
+		return
+	}
+
+	household := models.Household{Name: name}
+	if err := h.repo.Create(c.Request.Context(), middleware.UserID(c), &household); err != nil {
+		respondHouseholdError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, household)
+}
+
+// Households of the signed in user with their role in each
+func (h *HouseholdHandler) HandleGetHouseholds(c *gin.Context) {
+	households, err := h.repo.GetAll(c.Request.Context(), middleware.UserID(c))
+	if err != nil {
+		respondHouseholdError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, households)
+}
+
+func (h *HouseholdHandler) HandleGetHouseholdByID(c *gin.Context) {
+	id, ok := parseHouseholdID(c)
+	if !ok {
+		return
+	}
+
+	household, err := h.repo.GetByID(c.Request.Context(), middleware.UserID(c), id)
+	if err != nil {
+		respondHouseholdError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, household)
+}
+
+// Owners only
+func (h *HouseholdHandler) HandleRenameHousehold(c *gin.Context) {
+	id, ok := parseHouseholdID(c)
+	if !ok {
+		return
+	}
+
+	name, ok := bindHouseholdName(c)
+	if !ok {
+		return
+	}
+
+	household, err := h.repo.Rename(c.Request.Context(), middleware.UserID(c), id, name)
+	if err != nil {
+		respondHouseholdError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, household)
+}
+
+// Owners only. The token in the answer is shown once, whoever accepts it
+// joins with the role.
+func (h *HouseholdHandler) HandleCreateInvitation(c *gin.Context) {
+	id, ok := parseHouseholdID(c)
+	if !ok {
+		return
+	}
+
+	var input models.HouseholdRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, tokenHash, err := auth.NewToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	invitation, err := h.repo.CreateInvitation(c.Request.Context(), middleware.UserID(c), id, input.Role, tokenHash, InvitationTTL)
+	if err != nil {
+		respondHouseholdError(c, err)
+		return
+	}
+
+	invitation.Token = token
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// Joins the household of the invitation, which then stops working
+func (h *HouseholdHandler) HandleAcceptInvitation(c *gin.Context) {
+	var input models.InvitationAcceptance
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	household, err := h.repo.AcceptInvitation(c.Request.Context(), middleware.UserID(c), auth.HashToken(input.Token))
+	if err != nil {
+		respondHouseholdError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, household)
+}
+
+// Owners only, a household always keeps an owner
+func (h *HouseholdHandler) HandleSetMemberRole(c *gin.Context) {
+	id, ok := parseHouseholdID(c)
+	if !ok {
+		return
+	}
+	memberID, ok := parseMemberID(c)
+	if !ok {
+		return
+	}
+
+	var input models.HouseholdRoleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	household, err := h.repo.SetMemberRole(c.Request.Context(), middleware.UserID(c), id, memberID, input.Role)
+	if err != nil {
+		respondHouseholdError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, household)
+}
+
+// Owners remove anyone, other members can only leave
+func (h *HouseholdHandler) HandleRemoveMember(c *gin.Context) {
+	id, ok := parseHouseholdID(c)
+	if !ok {
+		return
+	}
+	memberID, ok := parseMemberID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.RemoveMember(c.Request.Context(), middleware.UserID(c), id, memberID); err != nil {
+		respondHouseholdError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	c.Writer.WriteHeaderNow() // Same as board game delete, force the 204
+}
+
+func bindHouseholdName(c *gin.Context) (string, bool) {
+	var input models.HouseholdInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", false
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return "", false
+	}
+	return name, true
+}
+
+func parseHouseholdID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid household ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+func parseMemberID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+func respondHouseholdError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrHouseholdNotFound), errors.Is(err, repository.ErrMemberNotFound),
+		errors.Is(err, repository.ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrAlreadyMember), errors.Is(err, repository.ErrLastOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
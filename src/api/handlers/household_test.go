@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/auth"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandleCreateHousehold(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"named", `{"name": " The Lovelaces "}`, http.StatusCreated},
+		{"blank name", `{"name": "   "}`, http.StatusBadRequest},
+		{"no name", `{}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mockHouseholdRepo{}
+			handler := NewHouseholdHandler(repo)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/households", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			ctx, rec := createTestContext(req)
+
+			// Act
+			handler.HandleCreateHousehold(ctx)
+
+			// Assert
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, rec.Code)
+			}
+			if tt.status == http.StatusCreated && (repo.userID != testUser.ID || repo.name != "The Lovelaces") {
+				t.Errorf("expected a trimmed household for user %d, got %q for %d", testUser.ID, repo.name, repo.userID)
+			}
+		})
+	}
+}
+
+func TestHandleCreateInvitation_OK(t *testing.T) {
+	// Arrange
+	repo := &mockHouseholdRepo{}
+	handler := NewHouseholdHandler(repo)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/households/3/invitations", strings.NewReader(`{"role": "viewer"}`))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "3"}}
+
+	// Act
+	handler.HandleCreateInvitation(ctx)
+
+	// Assert
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d %s", rec.Code, rec.Body)
+	}
+
+	var invitation models.Invitation
+	if err := json.Unmarshal(rec.Body.Bytes(), &invitation); err != nil {
+		t.Fatalf("failed to unmarshal response JSON: %v", err)
+	}
+	if invitation.Token == "" || repo.tokenHash != auth.HashToken(invitation.Token) {
+		t.Errorf("expected the invitation to be stored by the hash of its token")
+	}
+	if repo.role != models.RoleViewer || repo.householdID != 3 {
+		t.Errorf("expected a viewer invitation to household 3, got %q to %d", repo.role, repo.householdID)
+	}
+}
+
+func TestHandleCreateInvitation_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		err    error
+		status int
+	}{
+		{"unknown role", `{"role": "admin"}`, nil, http.StatusBadRequest},
+		{"not an owner", `{"role": "editor"}`, repository.ErrForbidden, http.StatusForbidden},
+		{"not a member", `{"role": "editor"}`, repository.ErrHouseholdNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := NewHouseholdHandler(&mockHouseholdRepo{err: tt.err})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/households/3/invitations", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			ctx, rec := createTestContext(req)
+			ctx.Params = gin.Params{{Key: "id", Value: "3"}}
+
+			// Act
+			handler.HandleCreateInvitation(ctx)
+
+			// Assert
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+}
+
+func TestHandleAcceptInvitation(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"open invitation", nil, http.StatusOK},
+		{"used or expired", repository.ErrInvitationNotFound, http.StatusNotFound},
+		{"already a member", repository.ErrAlreadyMember, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mockHouseholdRepo{err: tt.err}
+			handler := NewHouseholdHandler(repo)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/invitations/accept", strings.NewReader(`{"token": "abc"}`))
+			req.Header.Set("Content-Type", "application/json")
+			ctx, rec := createTestContext(req)
+
+			// Act
+			handler.HandleAcceptInvitation(ctx)
+
+			// Assert
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, rec.Code)
+			}
+			if repo.tokenHash != auth.HashToken("abc") {
+				t.Errorf("expected the invitation to be looked up by the hash of its token, got %q", repo.tokenHash)
+			}
+		})
+	}
+}
+
+func TestHandleRemoveMember(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"removed", nil, http.StatusNoContent},
+		{"last owner", repository.ErrLastOwner, http.StatusConflict},
+		{"not an owner", repository.ErrForbidden, http.StatusForbidden},
+		{"not a member", repository.ErrMemberNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mockHouseholdRepo{err: tt.err}
+			handler := NewHouseholdHandler(repo)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/households/3/members/8", nil)
+			ctx, rec := createTestContext(req)
+			ctx.Params = gin.Params{{Key: "id", Value: "3"}, {Key: "userId", Value: "8"}}
+
+			// Act
+			handler.HandleRemoveMember(ctx)
+
+			// Assert
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, rec.Code)
+			}
+			if repo.memberID != 8 {
+				t.Errorf("expected member 8 to be removed, got %d", repo.memberID)
+			}
+		})
+	}
+}
+
+// Records the arguments, every call fails with err when set
+type mockHouseholdRepo struct {
+	err error
+
+	userID      int64
+	householdID int64
+	memberID    int64
+	name        string
+	role        models.HouseholdRole
+	tokenHash   string
+}
+
+func (m *mockHouseholdRepo) household() *models.Household {
+	return &models.Household{ID: m.householdID, Name: m.name, Role: models.RoleOwner}
+}
+
+func (m *mockHouseholdRepo) Create(ctx context.Context, userID int64, household *models.Household) error {
+	m.userID, m.name = userID, household.Name
+	if m.err != nil {
+		return m.err
+	}
+	household.ID = 1
+	household.Role = models.RoleOwner
+	return nil
+}
+
+func (m *mockHouseholdRepo) GetAll(ctx context.Context, userID int64) ([]*models.Household, error) {
+	m.userID = userID
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []*models.Household{m.household()}, nil
+}
+
+func (m *mockHouseholdRepo) GetByID(ctx context.Context, userID int64, id int64) (*models.Household, error) {
+	m.userID, m.householdID = userID, id
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.household(), nil
+}
+
+func (m *mockHouseholdRepo) Rename(ctx context.Context, userID int64, id int64, name string) (*models.Household, error) {
+	m.userID, m.householdID, m.name = userID, id, name
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.household(), nil
+}
+
+func (m *mockHouseholdRepo) CreateInvitation(ctx context.Context, userID int64, householdID int64, role models.HouseholdRole, tokenHash string, ttl time.Duration) (*models.Invitation, error) {
+	m.userID, m.householdID, m.role, m.tokenHash = userID, householdID, role, tokenHash
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.Invitation{ID: 1, HouseholdID: householdID, Role: role, ExpiresAt: time.Now().Add(ttl)}, nil
+}
+
+func (m *mockHouseholdRepo) AcceptInvitation(ctx context.Context, userID int64, tokenHash string) (*models.Household, error) {
+	m.userID, m.tokenHash = userID, tokenHash
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.household(), nil
+}
+
+func (m *mockHouseholdRepo) SetMemberRole(ctx context.Context, userID int64, householdID int64, memberID int64, role models.HouseholdRole) (*models.Household, error) {
+	m.userID, m.householdID, m.memberID, m.role = userID, householdID, memberID, role
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.household(), nil
+}
+
+func (m *mockHouseholdRepo) RemoveMember(ctx context.Context, userID int64, householdID int64, memberID int64) error {
+	m.userID, m.householdID, m.memberID = userID, householdID, memberID
+	return m.err
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/api/middleware"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/bgg"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// Bundled covers make exports big, but not this big
+const maxImportFileSize = 64 * 1024 * 1024
+
+type ImportHandler struct {
+	bggImporter *bgg.Importer
+}
+
+func NewImportHandler(bggImporter *bgg.Importer) *ImportHandler {
+	return &ImportHandler{bggImporter: bggImporter}
+}
+
+// Imports a BoardGameGeek collection or thing export, sent as the "file" form
+// field or as the request body. ?dry_run=true only reports what would change.
+func (h *ImportHandler) HandleImportBGG(c *gin.Context) {
+	file, _, ok := openImportFile(c)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	report, err := h.bggImporter.Import(c.Request.Context(), middleware.UserID(c), file, c.Query("dry_run") == "true")
+	if err != nil {
+		switch {
+		case isFileTooLarge(err):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File too large (max 64MB)"})
+		case errors.Is(err, bgg.ErrInvalidFile):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, repository.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// The "file" form field or the request body, with the file name when there is one
+func openImportFile(c *gin.Context) (io.ReadCloser, string, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize)
+
+	if !strings.HasPrefix(c.ContentType(), "multipart/") {
+		return c.Request.Body, "", true
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		if isFileTooLarge(err) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File too large (max 64MB)"})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No file provided"})
+		}
+		return nil, "", false
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
+		return nil, "", false
+	}
+	return file, header.Filename, true
+}
+
+func isFileTooLarge(err error) bool {
+	var tooLarge *http.MaxBytesError
+	return errors.As(err, &tooLarge)
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/bgg"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+)
+
+const testThingExport = `<items><item type="boardgame" id="31260">
+	<name type="primary" value="Agricola"/><description>Farming</description>
+	<minplayers value="1"/><maxplayers value="5"/><playingtime value="150"/><minage value="12"/>
+	<image>%s</image></item></items>`
+
+func TestHandleImportBGG_DryRun(t *testing.T) {
+	// Arrange
+	repo := &mockImportRepo{}
+	imageRepo := &mockBoardGameImageRepo{}
+	handler := NewImportHandler(bgg.NewImporter(repo, imageRepo))
+
+	cover := base64.StdEncoding.EncodeToString(encodeTestImage(t, 20, 20, false))
+	body := strings.Replace(testThingExport, "%s", "data:image/png;base64,"+cover, 1)
+	req := httptest.NewRequest(http.MethodPost, "/api/import/bgg?dry_run=true", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/xml")
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleImportBGG(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d %s", rec.Code, rec.Body)
+	}
+
+	if !repo.dryRun || len(repo.games) != 1 || repo.games[0].Name != "Agricola" {
+		t.Fatalf("expected a dry run of Agricola, got %v %+v", repo.dryRun, repo.games)
+	}
+
+	if imageRepo.createCalled {
+		t.Error("expected no cover to be saved on a dry run")
+	}
+
+	var report models.ImportReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("failed to unmarshal response JSON: %v", err)
+	}
+
+	if !report.DryRun || len(report.Created) != 1 || report.Created[0].Cover != models.CoverAttached {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
+
+func TestHandleImportBGG_AttachesCover(t *testing.T) {
+	// Arrange
+	imageRepo := &mockBoardGameImageRepo{}
+	handler := NewImportHandler(bgg.NewImporter(&mockImportRepo{}, imageRepo))
+
+	cover := base64.StdEncoding.EncodeToString(encodeTestImage(t, 20, 20, false))
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("file", "agricola.xml")
+	part.Write([]byte(strings.Replace(testThingExport, "%s", "data:image/png;base64,"+cover, 1)))
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/import/bgg", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleImportBGG(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d %s", rec.Code, rec.Body)
+	}
+
+	if imageRepo.savedImage == nil || imageRepo.savedImage.BoardGameID != 7 || imageRepo.savedImage.ImageType != "cover" {
+		t.Fatalf("expected the cover of board game 7 to be saved, got %+v", imageRepo.savedImage)
+	}
+
+	if imageRepo.coverMode != repository.CoverModeReject {
+		t.Errorf("expected an existing cover to never be replaced, got %q", imageRepo.coverMode)
+	}
+}
+
+func TestHandleImportBGG_InvalidFile(t *testing.T) {
+	// Arrange
+	repo := &mockImportRepo{}
+	handler := NewImportHandler(bgg.NewImporter(repo, &mockBoardGameImageRepo{}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/import/bgg", strings.NewReader("name,players\nCatan,4"))
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleImportBGG(ctx)
+
+	// Assert
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+
+	if repo.games != nil {
+		t.Fatal("expected ImportBoardGames() not to be called")
+	}
+}
+
+// Creates every game with id 7, covers get attached
+type mockImportRepo struct {
+	err    error
+	games  []*models.ImportedBoardGame
+	dryRun bool
+}
+
+func (m *mockImportRepo) ImportBoardGames(ctx context.Context, userID int64, games []*models.ImportedBoardGame, dryRun bool) (*models.ImportReport, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.games = games
+	m.dryRun = dryRun
+
+	report := &models.ImportReport{DryRun: dryRun}
+	for _, game := range games {
+		entry := models.ImportEntry{BGGID: *game.BGGID, Name: game.Name, BoardGameID: 7}
+		if game.Cover != nil {
+			entry.Cover = models.CoverAttached
+		}
+		report.Created = append(report.Created, entry)
+	}
+	return report, nil
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/api/middleware"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+type LeaderboardHandler struct {
+	repo repository.RatingRepo
+}
+
+func NewLeaderboardHandler(repo repository.RatingRepo) *LeaderboardHandler {
+	return &LeaderboardHandler{repo: repo}
+}
+
+// Overall ratings of the players of the user's households, every game counts
+func (h *LeaderboardHandler) HandleGetLeaderboard(c *gin.Context) {
+	entries, err := h.repo.GetLeaderboard(c.Request.Context(), middleware.UserID(c), nil)
+	if err != nil {
+		respondLeaderboardError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// Ratings from the plays of the game in :id only
+func (h *LeaderboardHandler) HandleGetBoardGameLeaderboard(c *gin.Context) {
+	boardGameID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board game ID"})
+		return
+	}
+
+	entries, err := h.repo.GetLeaderboard(c.Request.Context(), middleware.UserID(c), &boardGameID)
+	if err != nil {
+		respondLeaderboardError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+func respondLeaderboardError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrBoardGameNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board game not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandleGetLeaderboard_OK(t *testing.T) {
+	// Arrange
+	repo := &mockRatingRepo{}
+	handler := NewLeaderboardHandler(repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/leaderboard", nil)
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleGetLeaderboard(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	if !repo.called || repo.boardGameID != nil || repo.userID != testUser.ID {
+		t.Fatal("expected GetLeaderboard() to be called for the overall ratings of the signed in user")
+	}
+
+	var response []models.LeaderboardEntry
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response JSON: %v", err)
+	}
+
+	if len(response) != 2 || response[0].Name != "Ana" || response[0].Rating != 1516 {
+		t.Errorf("expected Ana first with 1516, got %+v", response)
+	}
+}
+
+func TestHandleGetBoardGameLeaderboard_OK(t *testing.T) {
+	// Arrange
+	repo := &mockRatingRepo{}
+	handler := NewLeaderboardHandler(repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/boardgames/3/leaderboard", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "3"}}
+
+	// Act
+	handler.HandleGetBoardGameLeaderboard(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	if repo.boardGameID == nil || *repo.boardGameID != 3 {
+		t.Fatalf("expected the leaderboard of board game 3, got %v", repo.boardGameID)
+	}
+}
+
+func TestHandleGetBoardGameLeaderboard_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		err      error
+		expected int
+	}{
+		{"invalid id", "abc", nil, http.StatusBadRequest},
+		{"unknown board game", "999", repository.ErrBoardGameNotFound, http.StatusNotFound},
+		{"database error", "3", repository.ErrQueryFailed, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := NewLeaderboardHandler(&mockRatingRepo{err: tt.err})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/boardgames/"+tt.id+"/leaderboard", nil)
+			ctx, rec := createTestContext(req)
+			ctx.Params = gin.Params{{Key: "id", Value: tt.id}}
+
+			// Act
+			handler.HandleGetBoardGameLeaderboard(ctx)
+
+			// Assert
+			if rec.Code != tt.expected {
+				t.Fatalf("expected status %d, got %d", tt.expected, rec.Code)
+			}
+		})
+	}
+}
+
+type mockRatingRepo struct {
+	err         error
+	called      bool
+	userID      int64
+	boardGameID *int64
+}
+
+func (m *mockRatingRepo) GetLeaderboard(ctx context.Context, userID int64, boardGameID *int64) ([]*models.LeaderboardEntry, error) {
+	m.called = true
+	m.userID = userID
+	m.boardGameID = boardGameID
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []*models.LeaderboardEntry{
+		{Position: 1, PlayerID: 1, Name: "Ana", Rating: 1516, GamesPlayed: 1, Wins: 1, WinRate: 1},
+		{Position: 2, PlayerID: 2, Name: "Luis", Rating: 1484, GamesPlayed: 1},
+	}, nil
+}
+
+func (m *mockRatingRepo) Recompute(ctx context.Context) error {
+	return m.err
+}
+
+func (m *mockRatingRepo) RecomputeIfMissing(ctx context.Context) (bool, error) {
+	return false, m.err
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/api/middleware"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// Games lent to friends, as the signed in user
+type LoanHandler struct {
+	repo repository.LoanRepo
+}
+
+func NewLoanHandler(repo repository.LoanRepo) *LoanHandler {
+	return &LoanHandler{repo: repo}
+}
+
+// Lends the :id game, answers 409 while it is already away
+func (h *LoanHandler) HandleLendBoardGame(c *gin.Context) {
+	boardGameID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board game ID"})
+		return
+	}
+
+	var input models.LoanInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input.BorrowerName = strings.TrimSpace(input.BorrowerName)
+	if input.BorrowerName == "" && input.BorrowerUserID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Borrower name or user ID is required"})
+		return
+	}
+	if input.LentAt != nil && input.DueAt != nil && input.DueAt.Before(*input.LentAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": repository.ErrInvalidLoanDates.Error()})
+		return
+	}
+
+	loan, err := h.repo.Lend(c.Request.Context(), middleware.UserID(c), boardGameID, &input)
+	if err != nil {
+		respondLoanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, loan)
+}
+
+// Every loan of the :id game, the current one first
+func (h *LoanHandler) HandleGetBoardGameLoans(c *gin.Context) {
+	boardGameID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board game ID"})
+		return
+	}
+
+	loans, err := h.repo.GetForBoardGame(c.Request.Context(), middleware.UserID(c), boardGameID)
+	if err != nil {
+		respondLoanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, loans)
+}
+
+// Takes the game back, with an optional note on its condition
+func (h *LoanHandler) HandleReturnLoan(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid loan ID"})
+		return
+	}
+
+	// The body is optional, an empty one returns the game now
+	var input models.LoanReturn
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	input.ConditionNote = strings.TrimSpace(input.ConditionNote)
+
+	loan, err := h.repo.Return(c.Request.Context(), middleware.UserID(c), id, &input)
+	if err != nil {
+		respondLoanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, loan)
+}
+
+// ?status=active (default), overdue, returned or all
+func (h *LoanHandler) HandleGetLoans(c *gin.Context) {
+	loans, err := h.repo.GetAll(c.Request.Context(), middleware.UserID(c), c.Query("status"))
+	if err != nil {
+		respondLoanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, loans)
+}
+
+func respondLoanError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrLoanNotFound), errors.Is(err, repository.ErrBoardGameNotFound),
+		errors.Is(err, repository.ErrBorrowerNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrBoardGameLent), errors.Is(err, repository.ErrLoanReturned):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrInvalidLoanDates), errors.Is(err, repository.ErrInvalidLoanStatus):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandleLendBoardGame_OK(t *testing.T) {
+	// Arrange
+	repo := &mockLoanRepo{}
+	handler := NewLoanHandler(repo)
+
+	body := `{"borrower_name": " Ada ", "due_at": "2026-11-01T00:00:00Z"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/boardgames/7/loans", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "7"}}
+
+	// Act
+	handler.HandleLendBoardGame(ctx)
+
+	// Assert
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d %s", rec.Code, rec.Body)
+	}
+	if repo.userID != testUser.ID || repo.boardGameID != 7 {
+		t.Errorf("expected game 7 lent by user %d, got %d by %d", testUser.ID, repo.boardGameID, repo.userID)
+	}
+	if repo.lent.BorrowerName != "Ada" || repo.lent.DueAt == nil {
+		t.Errorf("expected a trimmed borrower and a due date, got %+v", repo.lent)
+	}
+
+	var loan models.Loan
+	if err := json.Unmarshal(rec.Body.Bytes(), &loan); err != nil {
+		t.Fatalf("failed to unmarshal response JSON: %v", err)
+	}
+	if loan.ID != 1 || loan.BorrowerName != "Ada" {
+		t.Errorf("unexpected loan %+v", loan)
+	}
+}
+
+func TestHandleLendBoardGame_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"no borrower", `{"due_at": "2026-11-01T00:00:00Z"}`},
+		{"blank borrower", `{"borrower_name": "   "}`},
+		{"due before lent", `{"borrower_name": "Ada", "lent_at": "2026-10-01T00:00:00Z", "due_at": "2026-09-01T00:00:00Z"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mockLoanRepo{}
+			handler := NewLoanHandler(repo)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/boardgames/7/loans", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			ctx, rec := createTestContext(req)
+			ctx.Params = gin.Params{{Key: "id", Value: "7"}}
+
+			// Act
+			handler.HandleLendBoardGame(ctx)
+
+			// Assert
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", rec.Code)
+			}
+			if repo.lent != nil {
+				t.Error("expected no loan to be created")
+			}
+		})
+	}
+}
+
+func TestHandleReturnLoan(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		err    error
+		status int
+	}{
+		{"with a note", `{"condition_note": " Box corner dented "}`, nil, http.StatusOK},
+		{"without a body", "", nil, http.StatusOK},
+		{"already back", "", repository.ErrLoanReturned, http.StatusConflict},
+		{"viewer", "", repository.ErrForbidden, http.StatusForbidden},
+		{"unknown loan", "", repository.ErrLoanNotFound, http.StatusNotFound},
+		{"returned before lent", `{"returned_at": "2001-01-01T00:00:00Z"}`, repository.ErrInvalidLoanDates, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mockLoanRepo{err: tt.err}
+			handler := NewLoanHandler(repo)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/loans/3/return", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			ctx, rec := createTestContext(req)
+			ctx.Params = gin.Params{{Key: "id", Value: "3"}}
+
+			// Act
+			handler.HandleReturnLoan(ctx)
+
+			// Assert
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d %s", tt.status, rec.Code, rec.Body)
+			}
+			if repo.userID != testUser.ID || repo.loanID != 3 {
+				t.Errorf("expected loan 3 returned by user %d, got %d by %d", testUser.ID, repo.loanID, repo.userID)
+			}
+			if tt.name == "with a note" && repo.returned.ConditionNote != "Box corner dented" {
+				t.Errorf("expected a trimmed note, got %q", repo.returned.ConditionNote)
+			}
+		})
+	}
+}
+
+func TestHandleGetLoans(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		err    error
+		status int
+	}{
+		{"active by default", "/api/loans", nil, http.StatusOK},
+		{"overdue", "/api/loans?status=overdue", nil, http.StatusOK},
+		{"unknown status", "/api/loans?status=lost", repository.ErrInvalidLoanStatus, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mockLoanRepo{err: tt.err}
+			handler := NewLoanHandler(repo)
+
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			ctx, rec := createTestContext(req)
+
+			// Act
+			handler.HandleGetLoans(ctx)
+
+			// Assert
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, rec.Code)
+			}
+			if expected := req.URL.Query().Get("status"); repo.status != expected {
+				t.Errorf("expected status %q to be passed on, got %q", expected, repo.status)
+			}
+		})
+	}
+}
+
+func TestHandleGetBoardGameLoans_NotFound(t *testing.T) {
+	// Arrange
+	repo := &mockLoanRepo{err: repository.ErrBoardGameNotFound}
+	handler := NewLoanHandler(repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/boardgames/7/loans", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "7"}}
+
+	// Act
+	handler.HandleGetBoardGameLoans(ctx)
+
+	// Assert
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+	if repo.boardGameID != 7 {
+		t.Errorf("expected the loans of game 7, got %d", repo.boardGameID)
+	}
+}
+
+// Records the arguments, every call fails with err when set
+type mockLoanRepo struct {
+	err error
+
+	userID      int64
+	boardGameID int64
+	loanID      int64
+	status      string
+	lent        *models.LoanInput
+	returned    *models.LoanReturn
+}
+
+func (m *mockLoanRepo) Lend(ctx context.Context, userID int64, boardGameID int64, input *models.LoanInput) (*models.Loan, error) {
+	m.userID, m.boardGameID, m.lent = userID, boardGameID, input
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.Loan{ID: 1, BoardGameID: boardGameID, BorrowerName: input.BorrowerName, LentAt: time.Now(), DueAt: input.DueAt}, nil
+}
+
+func (m *mockLoanRepo) Return(ctx context.Context, userID int64, id int64, input *models.LoanReturn) (*models.Loan, error) {
+	m.userID, m.loanID, m.returned = userID, id, input
+	if m.err != nil {
+		return nil, m.err
+	}
+	now := time.Now()
+	return &models.Loan{ID: id, ReturnedAt: &now, ConditionNote: input.ConditionNote}, nil
+}
+
+func (m *mockLoanRepo) GetAll(ctx context.Context, userID int64, status string) ([]*models.Loan, error) {
+	m.userID, m.status = userID, status
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []*models.Loan{}, nil
+}
+
+func (m *mockLoanRepo) GetForBoardGame(ctx context.Context, userID int64, boardGameID int64) ([]*models.Loan, error) {
+	m.userID, m.boardGameID = userID, boardGameID
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []*models.Loan{}, nil
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/api/middleware"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+type PlaySessionHandler struct {
+	repo repository.PlaySessionRepo
+}
+
+func NewPlaySessionHandler(repo repository.PlaySessionRepo) *PlaySessionHandler {
+	return &PlaySessionHandler{repo: repo}
+}
+
+// Logs a play of the game in :id
+func (h *PlaySessionHandler) HandleCreatePlaySession(c *gin.Context) {
+	boardGameID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board game ID"})
+		return
+	}
+
+	session, ok := bindPlaySession(c)
+	if !ok {
+		return
+	}
+	session.BoardGameID = &boardGameID
+
+	if err := h.repo.Create(c.Request.Context(), session); err != nil {
+		respondPlaySessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, session)
+}
+
+// Play history of one game, most recent first
+func (h *PlaySessionHandler) HandleGetPlaySessions(c *gin.Context) {
+	boardGameID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board game ID"})
+		return
+	}
+
+	sessions, err := h.repo.GetAllForBoardGame(c.Request.Context(), boardGameID)
+	if err != nil {
+		respondPlaySessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// Latest plays in the user's households, deleted games included: /api/plays?limit=20
+func (h *PlaySessionHandler) HandleGetRecentPlaySessions(c *gin.Context) {
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit: must be a positive number"})
+			return
+		}
+		limit = value
+	}
+
+	sessions, err := h.repo.GetRecent(c.Request.Context(), middleware.UserID(c), limit)
+	if err != nil {
+		respondPlaySessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+func (h *PlaySessionHandler) HandleGetPlaySessionByID(c *gin.Context) {
+	boardGameID, playID, ok := parsePlaySessionIDs(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.repo.GetByID(c.Request.Context(), boardGameID, playID)
+	if err != nil {
+		respondPlaySessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// Replaces the whole session, players included
+func (h *PlaySessionHandler) HandleUpdatePlaySession(c *gin.Context) {
+	boardGameID, playID, ok := parsePlaySessionIDs(c)
+	if !ok {
+		return
+	}
+
+	session, ok := bindPlaySession(c)
+	if !ok {
+		return
+	}
+	session.ID = playID
+	session.BoardGameID = &boardGameID
+
+	if err := h.repo.Update(c.Request.Context(), session); err != nil {
+		respondPlaySessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+func (h *PlaySessionHandler) HandleDeletePlaySession(c *gin.Context) {
+	boardGameID, playID, ok := parsePlaySessionIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), boardGameID, playID); err != nil {
+		respondPlaySessionError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	c.Writer.WriteHeaderNow() // Same as board game delete, force the 204
+}
+
+func parsePlaySessionIDs(c *gin.Context) (int64, int64, bool) {
+	boardGameID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board game ID"})
+		return 0, 0, false
+	}
+
+	playID, err := strconv.ParseInt(c.Param("playId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid play session ID"})
+		return 0, 0, false
+	}
+
+	return boardGameID, playID, true
+}
+
+// Binds and validates the body, writes the 400 itself
+func bindPlaySession(c *gin.Context) (*models.PlaySession, bool) {
+	var session models.PlaySession
+	if err := c.ShouldBindJSON(&session); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	firstPlayers := 0
+	ranked := false
+	teamRanks := map[int]int{}
+	playerIDs := map[int64]bool{}
+	names := map[string]bool{}
+	for i := range session.Players {
+		player := &session.Players[i]
+
+		// Everyone takes part once, whatever the case of their name
+		player.Name = strings.TrimSpace(player.Name)
+		if player.PlayerID == nil && player.Name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Players need a player_id or a name"})
+			return nil, false
+		}
+		if player.PlayerID != nil {
+			if playerIDs[*player.PlayerID] {
+				c.JSON(http.StatusBadRequest, gin.H{"error": repository.ErrDuplicateParticipant.Error()})
+				return nil, false
+			}
+			playerIDs[*player.PlayerID] = true
+		} else {
+			if names[strings.ToLower(player.Name)] {
+				c.JSON(http.StatusBadRequest, gin.H{"error": repository.ErrDuplicateParticipant.Error()})
+				return nil, false
+			}
+			names[strings.ToLower(player.Name)] = true
+		}
+
+		if player.FirstPlayer {
+			firstPlayers++
+		}
+		if player.Rank == nil {
+			continue
+		}
+		ranked = true
+		if player.Team != nil {
+			if rank, ok := teamRanks[*player.Team]; ok && rank != *player.Rank {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Players of the same team must have the same rank"})
+				return nil, false
+			}
+			teamRanks[*player.Team] = *player.Rank
+		}
+	}
+	if firstPlayers > 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only one player can be the first player"})
+		return nil, false
+	}
+
+	// With finishing positions the winners are whoever finished first, as long
+	// as someone finished behind them. Unranked players finish last.
+	if ranked {
+		best := 0
+		for _, player := range session.Players {
+			if player.Rank != nil && (best == 0 || *player.Rank < best) {
+				best = *player.Rank
+			}
+		}
+		beaten := false
+		for _, player := range session.Players {
+			beaten = beaten || player.Rank == nil || *player.Rank > best
+		}
+		for i := range session.Players {
+			rank := session.Players[i].Rank
+			session.Players[i].Winner = beaten && rank != nil && *rank == best
+		}
+	}
+
+	return &session, true
+}
+
+func respondPlaySessionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrBoardGameNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board game not found"})
+	case errors.Is(err, repository.ErrPlaySessionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Play session not found"})
+	case errors.Is(err, repository.ErrPlayerNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown player_id"})
+	case errors.Is(err, repository.ErrDuplicateParticipant):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+const validPlaySessionBody = `{
+	"played_at": "2024-05-01T19:30:00Z",
+	"duration_minutes": 75,
+	"location": "Game night at Ana's",
+	"players": [
+		{"name": "Ana", "score": 10, "winner": true, "first_player": true},
+		{"name": "Luis", "score": 8}
+	]
+}`
+
+func TestHandleCreatePlaySession_Created(t *testing.T) {
+	// Arrange
+	repo := &mockPlaySessionRepo{}
+	handler := NewPlaySessionHandler(repo)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/boardgames/3/plays", bytes.NewReader([]byte(validPlaySessionBody)))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "3"}}
+
+	// Act
+	handler.HandleCreatePlaySession(ctx)
+
+	// Assert
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d %s", rec.Code, rec.Body)
+	}
+
+	if repo.created == nil || *repo.created.BoardGameID != 3 {
+		t.Fatal("expected Create() to be called for board game 3")
+	}
+
+	var response models.PlaySession
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response JSON: %v", err)
+	}
+
+	if len(response.Players) != 2 || !response.Players[0].Winner || *response.Players[1].Score != 8 {
+		t.Errorf("expected both players with scores, got %+v", response.Players)
+	}
+}
+
+func TestHandleCreatePlaySession_BadRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"no players", `{"played_at": "2024-05-01T19:30:00Z", "players": []}`},
+		{"missing date", `{"players": [{"name": "Ana"}]}`},
+		{"player without name", `{"played_at": "2024-05-01T19:30:00Z", "players": [{"score": 3}]}`},
+		{"two first players", `{"played_at": "2024-05-01T19:30:00Z", "players": [
+			{"name": "Ana", "first_player": true}, {"name": "Luis", "first_player": true}]}`},
+		{"negative duration", `{"played_at": "2024-05-01T19:30:00Z", "duration_minutes": -5, "players": [{"name": "Ana"}]}`},
+		{"zero rank", `{"played_at": "2024-05-01T19:30:00Z", "players": [{"name": "Ana", "rank": 0}]}`},
+		{"teammates with different ranks", `{"played_at": "2024-05-01T19:30:00Z", "players": [
+			{"name": "Ana", "team": 1, "rank": 1}, {"name": "Luis", "team": 1, "rank": 2}]}`},
+		{"blank name", `{"played_at": "2024-05-01T19:30:00Z", "players": [{"name": "   "}]}`},
+		{"same player id twice", `{"played_at": "2024-05-01T19:30:00Z", "players": [
+			{"player_id": 4, "rank": 1}, {"player_id": 4, "rank": 2}]}`},
+		{"same name in another case", `{"played_at": "2024-05-01T19:30:00Z", "players": [
+			{"name": "Ana"}, {"name": " ana "}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mockPlaySessionRepo{}
+			handler := NewPlaySessionHandler(repo)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/boardgames/3/plays", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+			ctx, rec := createTestContext(req)
+			ctx.Params = gin.Params{{Key: "id", Value: "3"}}
+
+			// Act
+			handler.HandleCreatePlaySession(ctx)
+
+			// Assert
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", rec.Code)
+			}
+
+			if repo.created != nil {
+				t.Fatal("expected Create() not to be called")
+			}
+		})
+	}
+}
+
+func TestHandleCreatePlaySession_WinnersFromRanks(t *testing.T) {
+	// Arrange
+	repo := &mockPlaySessionRepo{}
+	handler := NewPlaySessionHandler(repo)
+
+	body := `{"played_at": "2024-05-01T19:30:00Z", "players": [
+		{"name": "Ana", "rank": 2, "winner": true},
+		{"name": "Luis", "rank": 1},
+		{"name": "Eva", "rank": 1}
+	]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/boardgames/3/plays", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "3"}}
+
+	// Act
+	handler.HandleCreatePlaySession(ctx)
+
+	// Assert
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d %s", rec.Code, rec.Body)
+	}
+
+	players := repo.created.Players
+	if players[0].Winner || !players[1].Winner || !players[2].Winner {
+		t.Errorf("expected the two players ranked first to be the winners, got %+v", players)
+	}
+}
+
+func TestHandleCreatePlaySession_WinnersFromBestRank(t *testing.T) {
+	tests := []struct {
+		name    string
+		players string
+		winners []bool
+	}{
+		{"ranked from second", `[{"name": "Ana", "rank": 2}, {"name": "Luis", "rank": 3}]`, []bool{true, false}},
+		{"everyone tied", `[{"name": "Ana", "rank": 1, "winner": true}, {"name": "Luis", "rank": 1}]`, []bool{false, false}},
+		{"unranked finish last", `[{"name": "Ana", "rank": 1}, {"name": "Luis"}]`, []bool{true, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mockPlaySessionRepo{}
+			handler := NewPlaySessionHandler(repo)
+
+			body := `{"played_at": "2024-05-01T19:30:00Z", "players": ` + tt.players + `}`
+			req := httptest.NewRequest(http.MethodPost, "/api/boardgames/3/plays", bytes.NewReader([]byte(body)))
+			req.Header.Set("Content-Type", "application/json")
+			ctx, rec := createTestContext(req)
+			ctx.Params = gin.Params{{Key: "id", Value: "3"}}
+
+			// Act
+			handler.HandleCreatePlaySession(ctx)
+
+			// Assert
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("expected status 201, got %d %s", rec.Code, rec.Body)
+			}
+
+			for i, winner := range tt.winners {
+				if repo.created.Players[i].Winner != winner {
+					t.Errorf("expected player %d winner=%v, got %+v", i, winner, repo.created.Players)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleCreatePlaySession_BoardGameNotFound(t *testing.T) {
+	// Arrange
+	repo := &mockPlaySessionRepo{err: repository.ErrBoardGameNotFound}
+	handler := NewPlaySessionHandler(repo)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/boardgames/999/plays", bytes.NewReader([]byte(validPlaySessionBody)))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "999"}}
+
+	// Act
+	handler.HandleCreatePlaySession(ctx)
+
+	// Assert
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestHandleGetPlaySessions_OK(t *testing.T) {
+	// Arrange
+	handler := NewPlaySessionHandler(&mockPlaySessionRepo{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/boardgames/3/plays", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "3"}}
+
+	// Act
+	handler.HandleGetPlaySessions(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var response []models.PlaySession
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response JSON: %v", err)
+	}
+
+	if len(response) != 1 || response[0].BoardGameName != "Catan" {
+		t.Errorf("expected one Catan session, got %+v", response)
+	}
+}
+
+func TestHandleGetRecentPlaySessions_SignedInUser(t *testing.T) {
+	// Arrange
+	repo := &mockPlaySessionRepo{}
+	handler := NewPlaySessionHandler(repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/plays?limit=5", nil)
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleGetRecentPlaySessions(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	if repo.userID != testUser.ID {
+		t.Errorf("expected the plays of user %d, got %d", testUser.ID, repo.userID)
+	}
+}
+
+func TestHandleGetRecentPlaySessions_InvalidLimit(t *testing.T) {
+	// Arrange
+	handler := NewPlaySessionHandler(&mockPlaySessionRepo{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/plays?limit=-1", nil)
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleGetRecentPlaySessions(ctx)
+
+	// Assert
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestHandleGetPlaySessionByID_NotFound(t *testing.T) {
+	// Arrange
+	handler := NewPlaySessionHandler(&mockPlaySessionRepo{err: repository.ErrPlaySessionNotFound})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/boardgames/3/plays/42", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "3"}, {Key: "playId", Value: "42"}}
+
+	// Act
+	handler.HandleGetPlaySessionByID(ctx)
+
+	// Assert
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestHandleUpdatePlaySession_OK(t *testing.T) {
+	// Arrange
+	repo := &mockPlaySessionRepo{}
+	handler := NewPlaySessionHandler(repo)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/boardgames/3/plays/42", bytes.NewReader([]byte(validPlaySessionBody)))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "3"}, {Key: "playId", Value: "42"}}
+
+	// Act
+	handler.HandleUpdatePlaySession(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d %s", rec.Code, rec.Body)
+	}
+
+	if repo.updated == nil || repo.updated.ID != 42 || *repo.updated.BoardGameID != 3 {
+		t.Fatalf("expected Update() for session 42 of board game 3, got %+v", repo.updated)
+	}
+}
+
+func TestHandleDeletePlaySession_NoContent(t *testing.T) {
+	// Arrange
+	repo := &mockPlaySessionRepo{}
+	handler := NewPlaySessionHandler(repo)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/boardgames/3/plays/42", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "3"}, {Key: "playId", Value: "42"}}
+
+	// Act
+	handler.HandleDeletePlaySession(ctx)
+
+	// Assert
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", rec.Code)
+	}
+
+	if repo.deletedID != 42 {
+		t.Errorf("expected session 42 to be deleted, got %d", repo.deletedID)
+	}
+}
+
+type mockPlaySessionRepo struct {
+	err       error
+	userID    int64
+	created   *models.PlaySession
+	updated   *models.PlaySession
+	deletedID int64
+}
+
+func (m *mockPlaySessionRepo) Create(ctx context.Context, session *models.PlaySession) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.created = session
+	session.ID = 1
+	session.BoardGameName = "Catan"
+	return nil
+}
+
+func (m *mockPlaySessionRepo) GetAllForBoardGame(ctx context.Context, boardGameID int64) ([]*models.PlaySession, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []*models.PlaySession{
+		{ID: 1, BoardGameID: &boardGameID, BoardGameName: "Catan", Players: []models.PlaySessionPlayer{{Name: "Ana"}}},
+	}, nil
+}
+
+func (m *mockPlaySessionRepo) GetRecent(ctx context.Context, userID int64, limit int) ([]*models.PlaySession, error) {
+	m.userID = userID
+	return []*models.PlaySession{}, m.err
+}
+
+func (m *mockPlaySessionRepo) GetByID(ctx context.Context, boardGameID int64, id int64) (*models.PlaySession, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.PlaySession{ID: id, BoardGameID: &boardGameID, BoardGameName: "Catan"}, nil
+}
+
+func (m *mockPlaySessionRepo) Update(ctx context.Context, session *models.PlaySession) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.updated = session
+	return nil
+}
+
+func (m *mockPlaySessionRepo) Delete(ctx context.Context, boardGameID int64, id int64) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.deletedID = id
+	return nil
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/api/middleware"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/helpers"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+type PlayerHandler struct {
+	repo           repository.PlayerRepo
+	maxImagePixels int
+}
+
+func NewPlayerHandler(repo repository.PlayerRepo) *PlayerHandler {
+	return &PlayerHandler{repo: repo, maxImagePixels: helpers.DefaultMaxImagePixels}
+}
+
+// Avatars with more pixels than this are rejected before they get decoded
+func (h *PlayerHandler) SetMaxImagePixels(pixels int) {
+	h.maxImagePixels = pixels
+}
+
+func (h *PlayerHandler) HandleCreatePlayer(c *gin.Context) {
+	var player models.Player
+	if err := c.ShouldBindJSON(&player); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.repo.Create(c.Request.Context(), middleware.UserID(c), &player); err != nil {
+		respondPlayerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, player)
+}
+
+// Players of the user's households, sorted by name
+func (h *PlayerHandler) HandleGetPlayers(c *gin.Context) {
+	players, err := h.repo.GetAll(c.Request.Context(), middleware.UserID(c))
+	if err != nil {
+		respondPlayerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, players)
+}
+
+func (h *PlayerHandler) HandleGetPlayerByID(c *gin.Context) {
+	id, ok := parsePlayerID(c)
+	if !ok {
+		return
+	}
+
+	player, err := h.repo.GetByID(c.Request.Context(), middleware.UserID(c), id)
+	if err != nil {
+		respondPlayerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, player)
+}
+
+// Renames a player
+func (h *PlayerHandler) HandleUpdatePlayer(c *gin.Context) {
+	id, ok := parsePlayerID(c)
+	if !ok {
+		return
+	}
+
+	var player models.Player
+	if err := c.ShouldBindJSON(&player); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	player.ID = id
+
+	if err := h.repo.Update(c.Request.Context(), middleware.UserID(c), &player); err != nil {
+		respondPlayerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, player)
+}
+
+func (h *PlayerHandler) HandleDeletePlayer(c *gin.Context) {
+	id, ok := parsePlayerID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), middleware.UserID(c), id); err != nil {
+		respondPlayerError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	c.Writer.WriteHeaderNow() // Same as board game delete, force the 204
+}
+
+// Upload an avatar (multipart "avatar"), it goes through the same checks as
+// board game images and is stored as a thumbnail
+func (h *PlayerHandler) HandleUploadPlayerAvatar(c *gin.Context) {
+	id, ok := parsePlayerID(c)
+	if !ok {
+		return
+	}
+
+	file, err := c.FormFile("avatar")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No avatar provided"})
+		return
+	}
+
+	const maxFileSize = 10 * 1024 * 1024 // 10MB
+	openedFile, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read image"})
+		return
+	}
+	defer openedFile.Close()
+
+	imageData, err := io.ReadAll(io.LimitReader(openedFile, maxFileSize+1))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read image data"})
+		return
+	}
+	if len(imageData) > maxFileSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File too large (max 10MB)"})
+		return
+	}
+
+	imageData, mimeType, err := helpers.SanitizeImage(imageData, h.maxImagePixels)
+	if err != nil {
+		if errors.Is(err, helpers.ErrUnsupportedImage) || errors.Is(err, helpers.ErrImageTooLarge) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read image data"})
+		return
+	}
+
+	thumbnailData, err := helpers.GenerateThumbnail(imageData, mimeType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate thumbnail"})
+		return
+	}
+
+	err = h.repo.SetAvatar(c.Request.Context(), middleware.UserID(c), id, thumbnailData, helpers.ThumbnailMimeType(mimeType))
+	if err != nil {
+		respondPlayerError(c, err)
+		return
+	}
+
+	player, err := h.repo.GetByID(c.Request.Context(), middleware.UserID(c), id)
+	if err != nil {
+		respondPlayerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, player)
+}
+
+func (h *PlayerHandler) HandleGetPlayerAvatar(c *gin.Context) {
+	id, ok := parsePlayerID(c)
+	if !ok {
+		return
+	}
+
+	avatar, err := h.repo.GetAvatar(c.Request.Context(), middleware.UserID(c), id)
+	if err != nil {
+		respondPlayerError(c, err)
+		return
+	}
+
+	serveImageData(c, avatar.Data, avatar.MimeType, avatar.ContentHash, "", avatar.UpdatedAt)
+}
+
+func (h *PlayerHandler) HandleDeletePlayerAvatar(c *gin.Context) {
+	id, ok := parsePlayerID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteAvatar(c.Request.Context(), middleware.UserID(c), id); err != nil {
+		respondPlayerError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	c.Writer.WriteHeaderNow()
+}
+
+// Games played, win rate overall and per game, favourite game and longest win streak
+func (h *PlayerHandler) HandleGetPlayerStats(c *gin.Context) {
+	id, ok := parsePlayerID(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.repo.GetStats(c.Request.Context(), middleware.UserID(c), id)
+	if err != nil {
+		respondPlayerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+func parsePlayerID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid player ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+func respondPlayerError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrPlayerNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Player not found"})
+	case errors.Is(err, repository.ErrImageNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Avatar not found"})
+	case errors.Is(err, repository.ErrHouseholdNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrDuplicatePlayerName):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
@@ -0,0 +1,374 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandleCreatePlayer_Created(t *testing.T) {
+	// Arrange
+	repo := &mockPlayerRepo{}
+	handler := NewPlayerHandler(repo)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/players", bytes.NewReader([]byte(`{"name": "Ana"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleCreatePlayer(ctx)
+
+	// Assert
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d %s", rec.Code, rec.Body)
+	}
+
+	if repo.created == nil || repo.created.Name != "Ana" || repo.userID != testUser.ID {
+		t.Fatalf("expected Create() to be called with Ana by user %d, got %+v by %d", testUser.ID, repo.created, repo.userID)
+	}
+}
+
+func TestHandleCreatePlayer_DuplicateName(t *testing.T) {
+	// Arrange
+	handler := NewPlayerHandler(&mockPlayerRepo{err: repository.ErrDuplicatePlayerName})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/players", bytes.NewReader([]byte(`{"name": "ana"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleCreatePlayer(ctx)
+
+	// Assert
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", rec.Code)
+	}
+}
+
+func TestHandleCreatePlayer_MissingName(t *testing.T) {
+	// Arrange
+	repo := &mockPlayerRepo{}
+	handler := NewPlayerHandler(repo)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/players", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleCreatePlayer(ctx)
+
+	// Assert
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+
+	if repo.created != nil {
+		t.Fatal("expected Create() not to be called")
+	}
+}
+
+func TestHandleGetPlayerByID_NotFound(t *testing.T) {
+	// Arrange
+	handler := NewPlayerHandler(&mockPlayerRepo{err: repository.ErrPlayerNotFound})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/players/99", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "99"}}
+
+	// Act
+	handler.HandleGetPlayerByID(ctx)
+
+	// Assert
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestHandleGetPlayerStats_OK(t *testing.T) {
+	// Arrange
+	handler := NewPlayerHandler(&mockPlayerRepo{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/players/1/stats", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleGetPlayerStats(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var response models.PlayerStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response JSON: %v", err)
+	}
+
+	if response.GamesPlayed != 4 || response.LongestWinStreak != 2 || response.FavouriteGame == nil {
+		t.Errorf("expected the stats from the repository, got %+v", response)
+	}
+}
+
+func TestHandleUploadPlayerAvatar_OK(t *testing.T) {
+	// Arrange
+	repo := &mockPlayerRepo{}
+	handler := NewPlayerHandler(repo)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("avatar", "ana.png")
+	part.Write(encodeTestImage(t, 400, 400, false))
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/players/1/avatar", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleUploadPlayerAvatar(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d %s", rec.Code, rec.Body)
+	}
+
+	if len(repo.avatar) == 0 || repo.avatarMimeType != "image/png" {
+		t.Fatalf("expected a PNG avatar to be stored, got %d bytes of %q", len(repo.avatar), repo.avatarMimeType)
+	}
+}
+
+func TestHandleUploadPlayerAvatar_NotAnImage(t *testing.T) {
+	// Arrange
+	repo := &mockPlayerRepo{}
+	handler := NewPlayerHandler(repo)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("avatar", "ana.png")
+	part.Write([]byte("definitely not an image"))
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/players/1/avatar", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleUploadPlayerAvatar(ctx)
+
+	// Assert
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+
+	if repo.avatar != nil {
+		t.Fatal("expected SetAvatar() not to be called")
+	}
+}
+
+func TestHandleDeletePlayerAvatar_NoAvatar(t *testing.T) {
+	// Arrange
+	handler := NewPlayerHandler(&mockPlayerRepo{err: repository.ErrImageNotFound})
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/players/1/avatar", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleDeletePlayerAvatar(ctx)
+
+	// Assert
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestHandleGetPlayerAvatar_PrivateCache(t *testing.T) {
+	tests := []struct {
+		name                 string
+		query                string
+		expectedCacheControl string
+	}{
+		{"unversioned", "", "private, no-cache"},
+		{"current version", "?v=f00dcafe", "private, max-age=31536000, immutable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := NewPlayerHandler(&mockPlayerRepo{avatar: []byte("avatar"), avatarMimeType: "image/png"})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/players/1/avatar"+tt.query, nil)
+			ctx, rec := createTestContext(req)
+			ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			// Act
+			handler.HandleGetPlayerAvatar(ctx)
+
+			// Assert
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", rec.Code)
+			}
+
+			if rec.Header().Get("Cache-Control") != tt.expectedCacheControl {
+				t.Errorf("expected Cache-Control '%s', got '%s'", tt.expectedCacheControl, rec.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
+
+func TestHandleDeletePlayer_NoContent(t *testing.T) {
+	// Arrange
+	repo := &mockPlayerRepo{}
+	handler := NewPlayerHandler(repo)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/players/7", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "7"}}
+
+	// Act
+	handler.HandleDeletePlayer(ctx)
+
+	// Assert
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", rec.Code)
+	}
+
+	if repo.deletedID != 7 {
+		t.Errorf("expected player 7 to be deleted, got %d", repo.deletedID)
+	}
+}
+
+func TestHandlePlayerChanges_Denied(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		err    error
+		status int
+	}{
+		{"viewer renames", http.MethodPut, `{"name": "Ana"}`, repository.ErrForbidden, http.StatusForbidden},
+		{"viewer deletes", http.MethodDelete, "", repository.ErrForbidden, http.StatusForbidden},
+		{"player of another household", http.MethodPut, `{"name": "Ana"}`, repository.ErrPlayerNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mockPlayerRepo{err: tt.err}
+			handler := NewPlayerHandler(repo)
+
+			req := httptest.NewRequest(tt.method, "/api/players/7", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+			ctx, rec := createTestContext(req)
+			ctx.Params = gin.Params{{Key: "id", Value: "7"}}
+
+			// Act
+			if tt.method == http.MethodPut {
+				handler.HandleUpdatePlayer(ctx)
+			} else {
+				handler.HandleDeletePlayer(ctx)
+			}
+
+			// Assert
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, rec.Code)
+			}
+			if repo.userID != testUser.ID {
+				t.Errorf("expected the change to be made as user %d, got %d", testUser.ID, repo.userID)
+			}
+		})
+	}
+}
+
+type mockPlayerRepo struct {
+	err            error
+	userID         int64
+	created        *models.Player
+	deletedID      int64
+	avatar         []byte
+	avatarMimeType string
+}
+
+func (m *mockPlayerRepo) Create(ctx context.Context, userID int64, player *models.Player) error {
+	m.userID = userID
+	if m.err != nil {
+		return m.err
+	}
+	m.created = player
+	player.ID = 1
+	return nil
+}
+
+func (m *mockPlayerRepo) GetAll(ctx context.Context, userID int64) ([]*models.Player, error) {
+	return []*models.Player{}, m.err
+}
+
+func (m *mockPlayerRepo) GetByID(ctx context.Context, userID int64, id int64) (*models.Player, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.Player{ID: id, Name: "Ana"}, nil
+}
+
+func (m *mockPlayerRepo) Update(ctx context.Context, userID int64, player *models.Player) error {
+	m.userID = userID
+	return m.err
+}
+
+func (m *mockPlayerRepo) Delete(ctx context.Context, userID int64, id int64) error {
+	m.userID = userID
+	if m.err != nil {
+		return m.err
+	}
+	m.deletedID = id
+	return nil
+}
+
+func (m *mockPlayerRepo) SetAvatar(ctx context.Context, userID int64, id int64, data []byte, mimeType string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.avatar = data
+	m.avatarMimeType = mimeType
+	return nil
+}
+
+func (m *mockPlayerRepo) GetAvatar(ctx context.Context, userID int64, id int64) (*models.PlayerAvatar, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.PlayerAvatar{Data: m.avatar, MimeType: m.avatarMimeType, ContentHash: "f00dcafe", UpdatedAt: time.Now()}, nil
+}
+
+func (m *mockPlayerRepo) DeleteAvatar(ctx context.Context, userID int64, id int64) error {
+	return m.err
+}
+
+func (m *mockPlayerRepo) GetStats(ctx context.Context, userID int64, id int64) (*models.PlayerStats, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	catan := &models.PlayerGameStats{BoardGameName: "Catan", Plays: 3, Wins: 2, WinRate: 2.0 / 3}
+	return &models.PlayerStats{
+		PlayerID:         id,
+		Name:             "Ana",
+		GamesPlayed:      4,
+		Wins:             2,
+		WinRate:          0.5,
+		FavouriteGame:    catan,
+		LongestWinStreak: 2,
+		PerGame:          []*models.PlayerGameStats{catan},
+	}, nil
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/api/middleware"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+	repo repository.TagRepo
+}
+
+func NewTagHandler(repo repository.TagRepo) *TagHandler {
+	return &TagHandler{repo: repo}
+}
+
+func (h *TagHandler) HandleCreateTag(c *gin.Context) {
+	var tag models.Tag
+	if err := c.ShouldBindJSON(&tag); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.repo.Create(c.Request.Context(), middleware.UserID(c), &tag); err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+// Every tag with how many games have it, ?kind=mechanic for one kind only
+func (h *TagHandler) HandleGetTags(c *gin.Context) {
+	kind := c.Query("kind")
+	switch kind {
+	case "", models.TagKindCategory, models.TagKindMechanic, models.TagKindTheme:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid kind: must be category, mechanic or theme"})
+		return
+	}
+
+	tags, err := h.repo.GetAll(c.Request.Context(), middleware.UserID(c), kind)
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+func (h *TagHandler) HandleGetTagByID(c *gin.Context) {
+	id, ok := parseTagID(c, "id")
+	if !ok {
+		return
+	}
+
+	tag, err := h.repo.GetByID(c.Request.Context(), middleware.UserID(c), id)
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// Renames a tag or changes its kind
+func (h *TagHandler) HandleUpdateTag(c *gin.Context) {
+	id, ok := parseTagID(c, "id")
+	if !ok {
+		return
+	}
+
+	var tag models.Tag
+	if err := c.ShouldBindJSON(&tag); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tag.ID = id
+
+	if err := h.repo.Update(c.Request.Context(), middleware.UserID(c), &tag); err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+func (h *TagHandler) HandleDeleteTag(c *gin.Context) {
+	id, ok := parseTagID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), id); err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	c.Writer.WriteHeaderNow() // Same as board game delete, force the 204
+}
+
+// Folds the tag in :id into {"into": <tag id>}, its games get the target tag
+func (h *TagHandler) HandleMergeTag(c *gin.Context) {
+	id, ok := parseTagID(c, "id")
+	if !ok {
+		return
+	}
+
+	var request struct {
+		Into int64 `json:"into" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	target, err := h.repo.Merge(c.Request.Context(), middleware.UserID(c), id, request.Into)
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, target)
+}
+
+func (h *TagHandler) HandleGetBoardGameTags(c *gin.Context) {
+	boardGameID, ok := parseTagBoardGameID(c)
+	if !ok {
+		return
+	}
+
+	tags, err := h.repo.GetForBoardGame(c.Request.Context(), middleware.UserID(c), boardGameID)
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// Adds {"tag_ids": [...]} to the game and returns all of its tags
+func (h *TagHandler) HandleAssignBoardGameTags(c *gin.Context) {
+	boardGameID, ok := parseTagBoardGameID(c)
+	if !ok {
+		return
+	}
+
+	var request struct {
+		TagIDs []int64 `json:"tag_ids" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.repo.Assign(c.Request.Context(), boardGameID, request.TagIDs); err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	tags, err := h.repo.GetForBoardGame(c.Request.Context(), middleware.UserID(c), boardGameID)
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+func (h *TagHandler) HandleUnassignBoardGameTag(c *gin.Context) {
+	boardGameID, ok := parseTagBoardGameID(c)
+	if !ok {
+		return
+	}
+
+	tagID, ok := parseTagID(c, "tagId")
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Unassign(c.Request.Context(), boardGameID, tagID); err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	c.Writer.WriteHeaderNow()
+}
+
+func parseTagID(c *gin.Context, param string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(param), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tag ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+func parseTagBoardGameID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board game ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+func respondTagError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrTagNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+	case errors.Is(err, repository.ErrBoardGameNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board game not found"})
+	case errors.Is(err, repository.ErrDuplicateTagName):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrInvalidTagMerge):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
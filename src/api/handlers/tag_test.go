@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandleCreateTag_Created(t *testing.T) {
+	// Arrange
+	repo := &mockTagRepo{}
+	handler := NewTagHandler(repo)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/tags", bytes.NewReader([]byte(`{"name": "Deck-building", "kind": "mechanic"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleCreateTag(ctx)
+
+	// Assert
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d %s", rec.Code, rec.Body)
+	}
+
+	if repo.created == nil || repo.created.Kind != models.TagKindMechanic {
+		t.Fatalf("expected Create() to be called with a mechanic, got %+v", repo.created)
+	}
+}
+
+func TestHandleCreateTag_BadRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"missing name", `{"kind": "theme"}`},
+		{"unknown kind", `{"name": "Space", "kind": "genre"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &mockTagRepo{}
+			handler := NewTagHandler(repo)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/tags", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+			ctx, rec := createTestContext(req)
+
+			// Act
+			handler.HandleCreateTag(ctx)
+
+			// Assert
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", rec.Code)
+			}
+
+			if repo.created != nil {
+				t.Fatal("expected Create() not to be called")
+			}
+		})
+	}
+}
+
+func TestHandleUpdateTag_DuplicateName(t *testing.T) {
+	// Arrange
+	handler := NewTagHandler(&mockTagRepo{err: repository.ErrDuplicateTagName})
+
+	req := httptest.NewRequest(http.MethodPut, "/api/tags/2", bytes.NewReader([]byte(`{"name": "Party", "kind": "category"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "2"}}
+
+	// Act
+	handler.HandleUpdateTag(ctx)
+
+	// Assert
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", rec.Code)
+	}
+}
+
+func TestHandleGetTags_InvalidKind(t *testing.T) {
+	// Arrange
+	handler := NewTagHandler(&mockTagRepo{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/tags?kind=genre", nil)
+	ctx, rec := createTestContext(req)
+
+	// Act
+	handler.HandleGetTags(ctx)
+
+	// Assert
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestHandleMergeTag_OK(t *testing.T) {
+	// Arrange
+	repo := &mockTagRepo{}
+	handler := NewTagHandler(repo)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/tags/3/merge", bytes.NewReader([]byte(`{"into": 5}`)))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "3"}}
+
+	// Act
+	handler.HandleMergeTag(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d %s", rec.Code, rec.Body)
+	}
+
+	if repo.mergedFrom != 3 || repo.mergedInto != 5 {
+		t.Errorf("expected tag 3 merged into 5, got %d into %d", repo.mergedFrom, repo.mergedInto)
+	}
+
+	var response models.Tag
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response JSON: %v", err)
+	}
+
+	if response.ID != 5 {
+		t.Errorf("expected the target tag back, got %+v", response)
+	}
+}
+
+func TestHandleMergeTag_IntoItself(t *testing.T) {
+	// Arrange
+	handler := NewTagHandler(&mockTagRepo{err: repository.ErrInvalidTagMerge})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/tags/3/merge", bytes.NewReader([]byte(`{"into": 3}`)))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "3"}}
+
+	// Act
+	handler.HandleMergeTag(ctx)
+
+	// Assert
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestHandleAssignBoardGameTags_OK(t *testing.T) {
+	// Arrange
+	repo := &mockTagRepo{}
+	handler := NewTagHandler(repo)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/boardgames/1/tags", bytes.NewReader([]byte(`{"tag_ids": [2, 3]}`)))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleAssignBoardGameTags(ctx)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d %s", rec.Code, rec.Body)
+	}
+
+	if len(repo.assigned) != 2 {
+		t.Errorf("expected two tags to be assigned, got %v", repo.assigned)
+	}
+}
+
+func TestHandleAssignBoardGameTags_UnknownTag(t *testing.T) {
+	// Arrange
+	handler := NewTagHandler(&mockTagRepo{err: repository.ErrTagNotFound})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/boardgames/1/tags", bytes.NewReader([]byte(`{"tag_ids": [99]}`)))
+	req.Header.Set("Content-Type", "application/json")
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	// Act
+	handler.HandleAssignBoardGameTags(ctx)
+
+	// Assert
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestHandleUnassignBoardGameTag_NoContent(t *testing.T) {
+	// Arrange
+	repo := &mockTagRepo{}
+	handler := NewTagHandler(repo)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/boardgames/1/tags/2", nil)
+	ctx, rec := createTestContext(req)
+	ctx.Params = gin.Params{{Key: "id", Value: "1"}, {Key: "tagId", Value: "2"}}
+
+	// Act
+	handler.HandleUnassignBoardGameTag(ctx)
+
+	// Assert
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", rec.Code)
+	}
+
+	if repo.unassigned != 2 {
+		t.Errorf("expected tag 2 to be removed, got %d", repo.unassigned)
+	}
+}
+
+type mockTagRepo struct {
+	err        error
+	created    *models.Tag
+	mergedFrom int64
+	mergedInto int64
+	assigned   []int64
+	unassigned int64
+}
+
+func (m *mockTagRepo) Create(ctx context.Context, userID int64, tag *models.Tag) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.created = tag
+	tag.ID = 1
+	return nil
+}
+
+func (m *mockTagRepo) GetAll(ctx context.Context, userID int64, kind string) ([]*models.Tag, error) {
+	return []*models.Tag{}, m.err
+}
+
+func (m *mockTagRepo) GetByID(ctx context.Context, userID int64, id int64) (*models.Tag, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.Tag{ID: id, Name: "Co-op", Kind: models.TagKindMechanic}, nil
+}
+
+func (m *mockTagRepo) Update(ctx context.Context, userID int64, tag *models.Tag) error {
+	return m.err
+}
+
+func (m *mockTagRepo) Delete(ctx context.Context, id int64) error {
+	return m.err
+}
+
+func (m *mockTagRepo) Merge(ctx context.Context, userID int64, sourceID int64, targetID int64) (*models.Tag, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.mergedFrom = sourceID
+	m.mergedInto = targetID
+	return &models.Tag{ID: targetID, Name: "Co-op", Kind: models.TagKindMechanic, GameCount: 4}, nil
+}
+
+func (m *mockTagRepo) GetForBoardGame(ctx context.Context, userID int64, boardGameID int64) ([]*models.Tag, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []*models.Tag{{ID: 2, Name: "Co-op", Kind: models.TagKindMechanic}}, nil
+}
+
+func (m *mockTagRepo) Assign(ctx context.Context, boardGameID int64, tagIDs []int64) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.assigned = tagIDs
+	return nil
+}
+
+func (m *mockTagRepo) Unassign(ctx context.Context, boardGameID int64, tagID int64) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.unassigned = tagID
+	return nil
+}
@@ -0,0 +1,207 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/auth"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// Name of the cookie holding the session token of browsers
+const SessionCookie = "session"
+
+// Where the signed in user is kept in the gin context
+const userKey = "user"
+
+// Where the API key of the request is kept, when it used one
+const apiKeyKey = "api_key"
+
+// Finds the user signed in with a session token, repository.UserRepo does
+type SessionLookup interface {
+	GetSessionUser(ctx context.Context, tokenHash string) (*models.User, error)
+}
+
+// Finds the user of an API key and records it as used, repository.APIKeyRepo does
+type APIKeyLookup interface {
+	GetAPIKeyUser(ctx context.Context, keyHash string) (*models.User, *models.APIKey, error)
+}
+
+// Authenticate answers 401 unless the request carries a valid session token,
+// as the session cookie or an Authorization: Bearer header, or a valid API
+// key as the Bearer token
+func Authenticate(sessions SessionLookup, keys APIKeyLookup) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := SessionToken(c)
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Sign in required"})
+			return
+		}
+
+		if auth.IsAPIKey(token) {
+			user, key, err := keys.GetAPIKeyUser(c.Request.Context(), auth.HashToken(token))
+			if err != nil {
+				if errors.Is(err, repository.ErrAPIKeyNotFound) {
+					c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "API key revoked or expired"})
+					return
+				}
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+				return
+			}
+
+			SetCurrentUser(c, user)
+			SetCurrentAPIKey(c, key)
+			c.Next()
+			return
+		}
+
+		user, err := sessions.GetSessionUser(c.Request.Context(), auth.HashToken(token))
+		if err != nil {
+			if errors.Is(err, repository.ErrSessionNotFound) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Session expired, sign in again"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		SetCurrentUser(c, user)
+		c.Next()
+	}
+}
+
+// RequireScope answers 403 to API keys without the scope, sessions can do
+// anything. Goes after Authenticate.
+func RequireScope(scope models.APIKeyScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key := CurrentAPIKey(c); key != nil && !key.HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key lacks the " + string(scope) + " scope"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSession answers 403 to API keys, for the routes no scope covers.
+// Goes after Authenticate.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if CurrentAPIKey(c) != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API keys cannot be used here, sign in instead"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireAdmin answers 403 to users who are not admins. Goes after Authenticate.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if user := CurrentUser(c); user == nil || !user.IsAdmin {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admins only"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// Role of a user in the household of a game, repository.BoardGameRepo does
+type BoardGameLookup interface {
+	Access(ctx context.Context, userID int64, id int64) (models.HouseholdRole, error)
+}
+
+// RequireBoardGame answers 404 when the :id board game is not in a household
+// of the signed in user, for routes under a game whose own tables are not scoped
+func RequireBoardGame(games BoardGameLookup) gin.HandlerFunc {
+	return requireBoardGame(games, false)
+}
+
+// RequireBoardGameEditor also answers 403 to viewers of the game's household,
+// for the routes changing the game
+func RequireBoardGameEditor(games BoardGameLookup) gin.HandlerFunc {
+	return requireBoardGame(games, true)
+}
+
+func requireBoardGame(games BoardGameLookup, edit bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid board game ID"})
+			return
+		}
+
+		role, err := games.Access(c.Request.Context(), UserID(c), id)
+		if err != nil {
+			if errors.Is(err, repository.ErrBoardGameNotFound) {
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if edit && !role.CanEdit() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": repository.ErrForbidden.Error()})
+			return
+		}
+		c.Next()
+	}
+}
+
+// The token of the request, the Authorization header first
+func SessionToken(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); header != "" {
+		scheme, token, ok := strings.Cut(header, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+
+	token, err := c.Cookie(SessionCookie)
+	if err != nil {
+		return ""
+	}
+	return token
+}
+
+func SetCurrentUser(c *gin.Context, user *models.User) {
+	c.Set(userKey, user)
+}
+
+// The signed in user, nil on routes without Authenticate
+func CurrentUser(c *gin.Context) *models.User {
+	value, ok := c.Get(userKey)
+	if !ok {
+		return nil
+	}
+	user, _ := value.(*models.User)
+	return user
+}
+
+func SetCurrentAPIKey(c *gin.Context, key *models.APIKey) {
+	c.Set(apiKeyKey, key)
+}
+
+// The API key the request signed in with, nil for sessions
+func CurrentAPIKey(c *gin.Context) *models.APIKey {
+	value, ok := c.Get(apiKeyKey)
+	if !ok {
+		return nil
+	}
+	key, _ := value.(*models.APIKey)
+	return key
+}
+
+// Id of the signed in user, 0 when nobody is
+func UserID(c *gin.Context) int64 {
+	if user := CurrentUser(c); user != nil {
+		return user.ID
+	}
+	return 0
+}
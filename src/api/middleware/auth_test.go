@@ -0,0 +1,232 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/auth"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// Routes a request through the middlewares to a handler answering 200
+// with the id of the signed in user
+func serve(req *http.Request, middlewares ...gin.HandlerFunc) (*httptest.ResponseRecorder, int64) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	var userID int64
+	handlers := append(middlewares, func(c *gin.Context) {
+		userID = UserID(c)
+		c.Status(http.StatusOK)
+	})
+	router.GET("/boardgames/:id", handlers...)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec, userID
+}
+
+func TestAuthenticate(t *testing.T) {
+	sessions := mockSessions{auth.HashToken("valid"): {ID: 7}}
+	keys := mockAPIKeys{auth.HashToken("mgs_valid"): {ID: 9}}
+
+	tests := []struct {
+		name   string
+		cookie string
+		header string
+		status int
+		userID int64
+	}{
+		{"session cookie", "valid", "", http.StatusOK, 7},
+		{"bearer token", "", "Bearer valid", http.StatusOK, 7},
+		{"bearer wins over the cookie", "valid", "Bearer expired", http.StatusUnauthorized, 0},
+		{"other scheme", "", "Basic valid", http.StatusUnauthorized, 0},
+		{"api key", "", "Bearer mgs_valid", http.StatusOK, 9},
+		{"revoked or expired api key", "", "Bearer mgs_revoked", http.StatusUnauthorized, 0},
+		{"expired session", "expired", "", http.StatusUnauthorized, 0},
+		{"no token", "", "", http.StatusUnauthorized, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := httptest.NewRequest(http.MethodGet, "/boardgames/1", nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: SessionCookie, Value: tt.cookie})
+			}
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+
+			// Act
+			rec, userID := serve(req, Authenticate(sessions, keys))
+
+			// Assert
+			if rec.Code != tt.status || userID != tt.userID {
+				t.Errorf("expected %d for user %d, got %d for user %d", tt.status, tt.userID, rec.Code, userID)
+			}
+		})
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	tests := []struct {
+		name   string
+		user   *models.User
+		status int
+	}{
+		{"admin", &models.User{ID: 1, IsAdmin: true}, http.StatusOK},
+		{"user", &models.User{ID: 2}, http.StatusForbidden},
+		{"nobody", nil, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			signIn := func(c *gin.Context) {
+				if tt.user != nil {
+					SetCurrentUser(c, tt.user)
+				}
+			}
+
+			// Act
+			rec, _ := serve(httptest.NewRequest(http.MethodGet, "/boardgames/1", nil), signIn, RequireAdmin())
+
+			// Assert
+			if rec.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+}
+
+func TestRequireBoardGame(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		edit   bool
+		status int
+	}{
+		{"editor reads", "/boardgames/1", false, http.StatusOK},
+		{"editor changes", "/boardgames/1", true, http.StatusOK},
+		{"viewer reads", "/boardgames/2", false, http.StatusOK},
+		{"viewer changes", "/boardgames/2", true, http.StatusForbidden},
+		{"game of another household", "/boardgames/3", false, http.StatusNotFound},
+		{"invalid id", "/boardgames/abc", false, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			games := mockShelf{7: {1: models.RoleEditor, 2: models.RoleViewer}}
+			signIn := func(c *gin.Context) { SetCurrentUser(c, &models.User{ID: 7}) }
+			require := RequireBoardGame(games)
+			if tt.edit {
+				require = RequireBoardGameEditor(games)
+			}
+
+			// Act
+			rec, _ := serve(httptest.NewRequest(http.MethodGet, tt.path, nil), signIn, require)
+
+			// Assert
+			if rec.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name   string
+		key    *models.APIKey
+		status int
+	}{
+		{"session", nil, http.StatusOK},
+		{"key with the scope", &models.APIKey{Scopes: []models.APIKeyScope{models.ScopeGamesRead, models.ScopeGamesWrite}}, http.StatusOK},
+		{"key without it", &models.APIKey{Scopes: []models.APIKeyScope{models.ScopeGamesRead}}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			signIn := signInWith(tt.key)
+
+			// Act
+			rec, _ := serve(httptest.NewRequest(http.MethodGet, "/boardgames/1", nil), signIn, RequireScope(models.ScopeGamesWrite))
+
+			// Assert
+			if rec.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+}
+
+func TestRequireSession(t *testing.T) {
+	tests := []struct {
+		name   string
+		key    *models.APIKey
+		status int
+	}{
+		{"session", nil, http.StatusOK},
+		{"key", &models.APIKey{Scopes: []models.APIKeyScope{models.ScopeGamesWrite}}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			rec, _ := serve(httptest.NewRequest(http.MethodGet, "/boardgames/1", nil), signInWith(tt.key), RequireSession())
+
+			// Assert
+			if rec.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+}
+
+// Signs user 7 in, with the API key when there is one
+func signInWith(key *models.APIKey) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		SetCurrentUser(c, &models.User{ID: 7})
+		if key != nil {
+			SetCurrentAPIKey(c, key)
+		}
+	}
+}
+
+// Users by token hash
+type mockSessions map[string]*models.User
+
+func (m mockSessions) GetSessionUser(ctx context.Context, tokenHash string) (*models.User, error) {
+	if user, ok := m[tokenHash]; ok {
+		return user, nil
+	}
+	return nil, repository.ErrSessionNotFound
+}
+
+// Roles by board game id by user id
+type mockShelf map[int64]map[int64]models.HouseholdRole
+
+func (m mockShelf) Access(ctx context.Context, userID int64, id int64) (models.HouseholdRole, error) {
+	role, ok := m[userID][id]
+	if !ok {
+		return "", repository.ErrBoardGameNotFound
+	}
+	return role, nil
+}
+
+// Users by API key hash, every key has the games:read scope
+type mockAPIKeys map[string]*models.User
+
+func (m mockAPIKeys) GetAPIKeyUser(ctx context.Context, keyHash string) (*models.User, *models.APIKey, error) {
+	if user, ok := m[keyHash]; ok {
+		return user, &models.APIKey{Scopes: []models.APIKeyScope{models.ScopeGamesRead}}, nil
+	}
+	return nil, nil, repository.ErrAPIKeyNotFound
+}
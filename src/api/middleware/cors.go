@@ -8,8 +8,9 @@ func Cors(origins string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", origins)
 		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept")
-		c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE,UPDATE")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, Range, If-None-Match, If-Modified-Since")
+		c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, PATCH, DELETE,UPDATE")
+		c.Header("Access-Control-Expose-Headers", "X-Total-Count, X-Next-Cursor, ETag, Content-Range")
 		c.Set("content-type", "application/json")
 		c.Next()
 	}
@@ -1,31 +1,319 @@
 package router
 
 import (
+	"github.com/eddiarnoldo/my-game-shelf/src/api/middleware"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
 	"github.com/gin-gonic/gin"
 )
 
 type BoardGameHandlerInterface interface {
 	HandleBoardGameCreate(c *gin.Context)
 	HandleGetBoardGames(c *gin.Context)
+	HandleSearchBoardGames(c *gin.Context)
+	HandleRecommendTonight(c *gin.Context)
 	HandleGetBoardGameByID(c *gin.Context)
+	HandleBoardGameUpdate(c *gin.Context)
+	HandleBoardGamePatch(c *gin.Context)
 	HandleBoardGameDelete(c *gin.Context)
 	HandleUploadBoardGameImage(c *gin.Context)
 	HandleGetBoardGameCoverImage(c *gin.Context)
+	HandleListBoardGameImages(c *gin.Context)
+	HandleGetBoardGameImage(c *gin.Context)
+	HandleGetBoardGameImageThumbnail(c *gin.Context)
+	HandleDeleteBoardGameImage(c *gin.Context)
+	HandleReorderBoardGameImages(c *gin.Context)
 }
 
-func RegisterRoutes(router *gin.Engine, boardGameHandler BoardGameHandlerInterface) {
+type PlaySessionHandlerInterface interface {
+	HandleCreatePlaySession(c *gin.Context)
+	HandleGetPlaySessions(c *gin.Context)
+	HandleGetRecentPlaySessions(c *gin.Context)
+	HandleGetPlaySessionByID(c *gin.Context)
+	HandleUpdatePlaySession(c *gin.Context)
+	HandleDeletePlaySession(c *gin.Context)
+}
+
+type PlayerHandlerInterface interface {
+	HandleCreatePlayer(c *gin.Context)
+	HandleGetPlayers(c *gin.Context)
+	HandleGetPlayerByID(c *gin.Context)
+	HandleUpdatePlayer(c *gin.Context)
+	HandleDeletePlayer(c *gin.Context)
+	HandleUploadPlayerAvatar(c *gin.Context)
+	HandleGetPlayerAvatar(c *gin.Context)
+	HandleDeletePlayerAvatar(c *gin.Context)
+	HandleGetPlayerStats(c *gin.Context)
+}
+
+type LeaderboardHandlerInterface interface {
+	HandleGetLeaderboard(c *gin.Context)
+	HandleGetBoardGameLeaderboard(c *gin.Context)
+}
+
+type TagHandlerInterface interface {
+	HandleCreateTag(c *gin.Context)
+	HandleGetTags(c *gin.Context)
+	HandleGetTagByID(c *gin.Context)
+	HandleUpdateTag(c *gin.Context)
+	HandleDeleteTag(c *gin.Context)
+	HandleMergeTag(c *gin.Context)
+	HandleGetBoardGameTags(c *gin.Context)
+	HandleAssignBoardGameTags(c *gin.Context)
+	HandleUnassignBoardGameTag(c *gin.Context)
+}
+
+type CreditHandlerInterface interface {
+	HandleCreatePerson(c *gin.Context)
+	HandleGetPeople(c *gin.Context)
+	HandleGetPersonByID(c *gin.Context)
+	HandleUpdatePerson(c *gin.Context)
+	HandleDeletePerson(c *gin.Context)
+	HandleGetPersonGames(c *gin.Context)
+	HandleGetDesigners(c *gin.Context)
+	HandleGetDesignerGames(c *gin.Context)
+	HandleGetArtists(c *gin.Context)
+	HandleGetArtistGames(c *gin.Context)
+	HandleCreatePublisher(c *gin.Context)
+	HandleGetPublishers(c *gin.Context)
+	HandleGetPublisherByID(c *gin.Context)
+	HandleUpdatePublisher(c *gin.Context)
+	HandleDeletePublisher(c *gin.Context)
+	HandleGetPublisherGames(c *gin.Context)
+	HandleSetBoardGameCredits(c *gin.Context)
+}
+
+type ExpansionHandlerInterface interface {
+	HandleSetBaseGame(c *gin.Context)
+	HandleRemoveBaseGame(c *gin.Context)
+	HandleGetExpansions(c *gin.Context)
+}
+
+type ImportHandlerInterface interface {
+	HandleImportBGG(c *gin.Context)
+}
+
+type CollectionHandlerInterface interface {
+	HandleExport(c *gin.Context)
+	HandleImport(c *gin.Context)
+}
+
+type BackupHandlerInterface interface {
+	HandleBackup(c *gin.Context)
+}
+
+type AuthHandlerInterface interface {
+	HandleRegister(c *gin.Context)
+	HandleLogin(c *gin.Context)
+	HandleLogout(c *gin.Context)
+	HandleMe(c *gin.Context)
+	HandleProviders(c *gin.Context)
+	HandleOIDCLogin(c *gin.Context)
+	HandleOIDCCallback(c *gin.Context)
+}
+
+type HouseholdHandlerInterface interface {
+	HandleCreateHousehold(c *gin.Context)
+	HandleGetHouseholds(c *gin.Context)
+	HandleGetHouseholdByID(c *gin.Context)
+	HandleRenameHousehold(c *gin.Context)
+	HandleCreateInvitation(c *gin.Context)
+	HandleAcceptInvitation(c *gin.Context)
+	HandleSetMemberRole(c *gin.Context)
+	HandleRemoveMember(c *gin.Context)
+}
+
+type APIKeyHandlerInterface interface {
+	HandleCreateAPIKey(c *gin.Context)
+	HandleGetAPIKeys(c *gin.Context)
+	HandleRevokeAPIKey(c *gin.Context)
+}
+
+type LoanHandlerInterface interface {
+	HandleLendBoardGame(c *gin.Context)
+	HandleGetBoardGameLoans(c *gin.Context)
+	HandleReturnLoan(c *gin.Context)
+	HandleGetLoans(c *gin.Context)
+}
+
+// One handler per part of the API
+type Handlers struct {
+	BoardGame   BoardGameHandlerInterface
+	PlaySession PlaySessionHandlerInterface
+	Player      PlayerHandlerInterface
+	Leaderboard LeaderboardHandlerInterface
+	Tag         TagHandlerInterface
+	Credit      CreditHandlerInterface
+	Expansion   ExpansionHandlerInterface
+	Import      ImportHandlerInterface
+	Collection  CollectionHandlerInterface
+	Backup      BackupHandlerInterface
+	Auth        AuthHandlerInterface
+	Household   HouseholdHandlerInterface
+	APIKey      APIKeyHandlerInterface
+	Loan        LoanHandlerInterface
+
+	// Answers 401 unless signed in or sent a valid API key, see middleware.Authenticate
+	Authenticate gin.HandlerFunc
+	// Answers 404 when the :id game is not in a household of the user, see middleware.RequireBoardGame
+	RequireBoardGame gin.HandlerFunc
+	// Also answers 403 to viewers, see middleware.RequireBoardGameEditor
+	RequireBoardGameEditor gin.HandlerFunc
+}
+
+func RegisterRoutes(router *gin.Engine, handlers Handlers) {
+	boardGameHandler := handlers.BoardGame
+	playSessionHandler := handlers.PlaySession
+	playerHandler := handlers.Player
+	leaderboardHandler := handlers.Leaderboard
+	tagHandler := handlers.Tag
+	creditHandler := handlers.Credit
+	expansionHandler := handlers.Expansion
+	importHandler := handlers.Import
+	collectionHandler := handlers.Collection
+	backupHandler := handlers.Backup
+	authHandler := handlers.Auth
+	householdHandler := handlers.Household
+	apiKeyHandler := handlers.APIKey
+	loanHandler := handlers.Loan
+
+	// Tags and credits are shared, and plays are stored with their game: routes
+	// under a game check the user can see it, or change it. Players, recent
+	// plays and ratings are scoped by the repositories.
+	ownedGame := handlers.RequireBoardGame
+	editedGame := handlers.RequireBoardGameEditor
+
 	api := router.Group("/api")
 	{
-		api.POST("/boardgame", boardGameHandler.HandleBoardGameCreate)
-		api.GET("/boardgames", boardGameHandler.HandleGetBoardGames)
-		api.GET("/boardgames/:id", boardGameHandler.HandleGetBoardGameByID)
-		api.DELETE("/boardgames/:id", boardGameHandler.HandleBoardGameDelete)
-		api.POST("/boardgame/:id/images", boardGameHandler.HandleUploadBoardGameImage)
-		api.GET("/boardgame/:id/images/cover", boardGameHandler.HandleGetBoardGameCoverImage)
-		/*
-			GET  /api/boardgame/:id/images/cover        → Get cover image (raw bytes)
-			GET  /api/boardgame/images/:imageId         → Get any image by ID (raw bytes)
-			DELETE /api/boardgame/images/:imageId       → Delete image
-		*/
+		// Accounts, the only routes open to anyone
+		api.POST("/auth/register", authHandler.HandleRegister)
+		api.POST("/auth/login", authHandler.HandleLogin)
+		api.GET("/auth/providers", authHandler.HandleProviders)
+		api.GET("/auth/oidc/login", authHandler.HandleOIDCLogin)
+		api.GET("/auth/oidc/callback", authHandler.HandleOIDCCallback)
+	}
+
+	// Everything else needs a signed in user and works on their households.
+	// Games and their images also take API keys, each route needing its scope.
+	authenticated := api.Group("", handlers.Authenticate)
+	signedIn := authenticated.Group("", middleware.RequireSession())
+
+	// Tags, people and publishers are shared by every household, only admins
+	// change or remove the ones already there
+	adminOnly := middleware.RequireAdmin()
+
+	readGames := middleware.RequireScope(models.ScopeGamesRead)
+	writeGames := middleware.RequireScope(models.ScopeGamesWrite)
+	writeImages := middleware.RequireScope(models.ScopeImagesWrite)
+	{
+		authenticated.POST("/boardgame", writeGames, boardGameHandler.HandleBoardGameCreate)
+		authenticated.GET("/boardgames", readGames, boardGameHandler.HandleGetBoardGames)
+		authenticated.GET("/boardgames/search", readGames, boardGameHandler.HandleSearchBoardGames)
+		authenticated.GET("/recommendations/tonight", readGames, boardGameHandler.HandleRecommendTonight)
+		authenticated.GET("/boardgames/:id", readGames, boardGameHandler.HandleGetBoardGameByID)
+		authenticated.PUT("/boardgames/:id", writeGames, boardGameHandler.HandleBoardGameUpdate)
+		authenticated.PATCH("/boardgames/:id", writeGames, boardGameHandler.HandleBoardGamePatch)
+		authenticated.DELETE("/boardgames/:id", writeGames, boardGameHandler.HandleBoardGameDelete)
+		authenticated.POST("/boardgame/:id/images", writeImages, boardGameHandler.HandleUploadBoardGameImage)
+		authenticated.GET("/boardgame/:id/images", readGames, boardGameHandler.HandleListBoardGameImages)
+		authenticated.PUT("/boardgame/:id/images/order", writeImages, boardGameHandler.HandleReorderBoardGameImages)
+		authenticated.GET("/boardgame/:id/images/cover", readGames, boardGameHandler.HandleGetBoardGameCoverImage)
+		authenticated.GET("/boardgame/images/:imageId", readGames, boardGameHandler.HandleGetBoardGameImage)
+		authenticated.GET("/boardgame/images/:imageId/thumbnail", readGames, boardGameHandler.HandleGetBoardGameImageThumbnail)
+		authenticated.DELETE("/boardgame/images/:imageId", writeImages, boardGameHandler.HandleDeleteBoardGameImage)
+
+		// Expansions
+		authenticated.PUT("/boardgames/:id/base", writeGames, expansionHandler.HandleSetBaseGame)
+		authenticated.DELETE("/boardgames/:id/base", writeGames, expansionHandler.HandleRemoveBaseGame)
+		authenticated.GET("/boardgames/:id/expansions", readGames, expansionHandler.HandleGetExpansions)
+	}
+
+	// Sessions only, no scope covers these
+	{
+		signedIn.POST("/auth/logout", authHandler.HandleLogout)
+		signedIn.GET("/auth/me", authHandler.HandleMe)
+
+		// API keys
+		signedIn.POST("/keys", apiKeyHandler.HandleCreateAPIKey)
+		signedIn.GET("/keys", apiKeyHandler.HandleGetAPIKeys)
+		signedIn.DELETE("/keys/:id", apiKeyHandler.HandleRevokeAPIKey)
+
+		// Households
+		signedIn.POST("/households", householdHandler.HandleCreateHousehold)
+		signedIn.GET("/households", householdHandler.HandleGetHouseholds)
+		signedIn.GET("/households/:id", householdHandler.HandleGetHouseholdByID)
+		signedIn.PUT("/households/:id", householdHandler.HandleRenameHousehold)
+		signedIn.POST("/households/:id/invitations", householdHandler.HandleCreateInvitation)
+		signedIn.PUT("/households/:id/members/:userId", householdHandler.HandleSetMemberRole)
+		signedIn.DELETE("/households/:id/members/:userId", householdHandler.HandleRemoveMember)
+		signedIn.POST("/invitations/accept", householdHandler.HandleAcceptInvitation)
+
+		// Play sessions
+		signedIn.GET("/plays", playSessionHandler.HandleGetRecentPlaySessions)
+		signedIn.POST("/boardgames/:id/plays", editedGame, playSessionHandler.HandleCreatePlaySession)
+		signedIn.GET("/boardgames/:id/plays", ownedGame, playSessionHandler.HandleGetPlaySessions)
+		signedIn.GET("/boardgames/:id/plays/:playId", ownedGame, playSessionHandler.HandleGetPlaySessionByID)
+		signedIn.PUT("/boardgames/:id/plays/:playId", editedGame, playSessionHandler.HandleUpdatePlaySession)
+		signedIn.DELETE("/boardgames/:id/plays/:playId", editedGame, playSessionHandler.HandleDeletePlaySession)
+
+		// Players
+		signedIn.POST("/players", playerHandler.HandleCreatePlayer)
+		signedIn.GET("/players", playerHandler.HandleGetPlayers)
+		signedIn.GET("/players/:id", playerHandler.HandleGetPlayerByID)
+		signedIn.PUT("/players/:id", playerHandler.HandleUpdatePlayer)
+		signedIn.DELETE("/players/:id", playerHandler.HandleDeletePlayer)
+		signedIn.POST("/players/:id/avatar", playerHandler.HandleUploadPlayerAvatar)
+		signedIn.GET("/players/:id/avatar", playerHandler.HandleGetPlayerAvatar)
+		signedIn.DELETE("/players/:id/avatar", playerHandler.HandleDeletePlayerAvatar)
+		signedIn.GET("/players/:id/stats", playerHandler.HandleGetPlayerStats)
+
+		// Ratings
+		signedIn.GET("/leaderboard", leaderboardHandler.HandleGetLeaderboard)
+		signedIn.GET("/boardgames/:id/leaderboard", ownedGame, leaderboardHandler.HandleGetBoardGameLeaderboard)
+
+		// Tags
+		signedIn.POST("/tags", tagHandler.HandleCreateTag)
+		signedIn.GET("/tags", tagHandler.HandleGetTags)
+		signedIn.GET("/tags/:id", tagHandler.HandleGetTagByID)
+		signedIn.PUT("/tags/:id", adminOnly, tagHandler.HandleUpdateTag)
+		signedIn.DELETE("/tags/:id", adminOnly, tagHandler.HandleDeleteTag)
+		signedIn.POST("/tags/:id/merge", adminOnly, tagHandler.HandleMergeTag)
+		signedIn.GET("/boardgames/:id/tags", ownedGame, tagHandler.HandleGetBoardGameTags)
+		signedIn.POST("/boardgames/:id/tags", editedGame, tagHandler.HandleAssignBoardGameTags)
+		signedIn.DELETE("/boardgames/:id/tags/:tagId", editedGame, tagHandler.HandleUnassignBoardGameTag)
+
+		// Designers, artists and publishers
+		signedIn.POST("/people", creditHandler.HandleCreatePerson)
+		signedIn.GET("/people", creditHandler.HandleGetPeople)
+		signedIn.GET("/people/:id", creditHandler.HandleGetPersonByID)
+		signedIn.PUT("/people/:id", adminOnly, creditHandler.HandleUpdatePerson)
+		signedIn.DELETE("/people/:id", adminOnly, creditHandler.HandleDeletePerson)
+		signedIn.GET("/people/:id/games", creditHandler.HandleGetPersonGames)
+		signedIn.GET("/designers", creditHandler.HandleGetDesigners)
+		signedIn.GET("/designers/:id/games", creditHandler.HandleGetDesignerGames)
+		signedIn.GET("/artists", creditHandler.HandleGetArtists)
+		signedIn.GET("/artists/:id/games", creditHandler.HandleGetArtistGames)
+		signedIn.POST("/publishers", creditHandler.HandleCreatePublisher)
+		signedIn.GET("/publishers", creditHandler.HandleGetPublishers)
+		signedIn.GET("/publishers/:id", creditHandler.HandleGetPublisherByID)
+		signedIn.PUT("/publishers/:id", adminOnly, creditHandler.HandleUpdatePublisher)
+		signedIn.DELETE("/publishers/:id", adminOnly, creditHandler.HandleDeletePublisher)
+		signedIn.GET("/publishers/:id/games", creditHandler.HandleGetPublisherGames)
+		signedIn.PUT("/boardgames/:id/credits", editedGame, creditHandler.HandleSetBoardGameCredits)
+
+		// Loans, the repository checks the role in the household of the game
+		signedIn.GET("/loans", loanHandler.HandleGetLoans)
+		signedIn.POST("/loans/:id/return", loanHandler.HandleReturnLoan)
+		signedIn.POST("/boardgames/:id/loans", loanHandler.HandleLendBoardGame)
+		signedIn.GET("/boardgames/:id/loans", loanHandler.HandleGetBoardGameLoans)
+
+		// Imports
+		signedIn.POST("/import/bgg", importHandler.HandleImportBGG)
+		signedIn.GET("/export", collectionHandler.HandleExport)
+		signedIn.POST("/import", collectionHandler.HandleImport)
+	}
+
+	admin := signedIn.Group("/admin", middleware.RequireAdmin())
+	{
+		admin.GET("/backup", backupHandler.HandleBackup)
 	}
 }
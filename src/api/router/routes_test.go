@@ -5,6 +5,8 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/eddiarnoldo/my-game-shelf/src/api/middleware"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
 	"github.com/gin-gonic/gin"
 )
 
@@ -31,20 +33,1278 @@ func TestRegisterRoutes(t *testing.T) {
 				return m.handleGetBoardGamesCalled
 			},
 		},
+		{
+			name:   "GET /api/boardgames/search calls HandleSearchBoardGames",
+			method: http.MethodGet,
+			path:   "/api/boardgames/search?q=catan",
+			checkCalled: func(m *mockBoardGameHandler) bool {
+				return m.handleSearchBoardGamesCalled
+			},
+		},
+		{
+			name:   "GET /api/recommendations/tonight calls HandleRecommendTonight",
+			method: http.MethodGet,
+			path:   "/api/recommendations/tonight?players=4",
+			checkCalled: func(m *mockBoardGameHandler) bool {
+				return m.handleRecommendTonightCalled
+			},
+		},
 		{
 			name:   "GET /api/boardgames/:id calls HandleGetBoardGameByID",
 			method: http.MethodGet,
-			path:   "/api/boardgames/1",
-			checkCalled: func(m *mockBoardGameHandler) bool {
-				return m.handleGetBoardGameByIDCalled
+			path:   "/api/boardgames/1",
+			checkCalled: func(m *mockBoardGameHandler) bool {
+				return m.handleGetBoardGameByIDCalled
+			},
+		},
+		{
+			name:   "PUT /api/boardgames/:id calls HandleBoardGameUpdate",
+			method: http.MethodPut,
+			path:   "/api/boardgames/1",
+			checkCalled: func(m *mockBoardGameHandler) bool {
+				return m.handleBoardGameUpdateCalled
+			},
+		},
+		{
+			name:   "PATCH /api/boardgames/:id calls HandleBoardGamePatch",
+			method: http.MethodPatch,
+			path:   "/api/boardgames/1",
+			checkCalled: func(m *mockBoardGameHandler) bool {
+				return m.handleBoardGamePatchCalled
+			},
+		},
+		{
+			name:   "DELETE /api/boardgames/:id calls HandleBoardGameDelete",
+			method: http.MethodDelete,
+			path:   "/api/boardgames/1",
+			checkCalled: func(m *mockBoardGameHandler) bool {
+				return m.handleBoardGameDeleteCalled
+			},
+		},
+		{
+			name:   "GET /api/boardgame/:id/images calls HandleListBoardGameImages",
+			method: http.MethodGet,
+			path:   "/api/boardgame/1/images",
+			checkCalled: func(m *mockBoardGameHandler) bool {
+				return m.handleListBoardGameImagesCalled
+			},
+		},
+		{
+			name:   "PUT /api/boardgame/:id/images/order calls HandleReorderBoardGameImages",
+			method: http.MethodPut,
+			path:   "/api/boardgame/1/images/order",
+			checkCalled: func(m *mockBoardGameHandler) bool {
+				return m.handleReorderBoardGameImagesCalled
+			},
+		},
+		{
+			name:   "GET /api/boardgame/images/:imageId calls HandleGetBoardGameImage",
+			method: http.MethodGet,
+			path:   "/api/boardgame/images/5",
+			checkCalled: func(m *mockBoardGameHandler) bool {
+				return m.handleGetBoardGameImageCalled
+			},
+		},
+		{
+			name:   "GET /api/boardgame/images/:imageId/thumbnail calls HandleGetBoardGameImageThumbnail",
+			method: http.MethodGet,
+			path:   "/api/boardgame/images/5/thumbnail",
+			checkCalled: func(m *mockBoardGameHandler) bool {
+				return m.handleGetBoardGameImageThumbnailCalled
+			},
+		},
+		{
+			name:   "DELETE /api/boardgame/images/:imageId calls HandleDeleteBoardGameImage",
+			method: http.MethodDelete,
+			path:   "/api/boardgame/images/5",
+			checkCalled: func(m *mockBoardGameHandler) bool {
+				return m.handleDeleteBoardGameImageCalled
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			mockHandler := &mockBoardGameHandler{}
+
+			handlers := mockHandlers()
+			handlers.BoardGame = mockHandler
+			RegisterRoutes(router, handlers)
+
+			// Act
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			if rec.Code == http.StatusNotFound {
+				t.Fatal("expected route to be registered, got 404")
+			}
+
+			if !tt.checkCalled(mockHandler) {
+				t.Fatal("expected handler method to be called")
+			}
+		})
+	}
+}
+
+func TestRegisterRoutes_PlaySessions(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		checkCalled func(*mockPlaySessionHandler) bool
+	}{
+		{
+			name:   "GET /api/plays calls HandleGetRecentPlaySessions",
+			method: http.MethodGet,
+			path:   "/api/plays",
+			checkCalled: func(m *mockPlaySessionHandler) bool {
+				return m.handleGetRecentPlaySessionsCalled
+			},
+		},
+		{
+			name:   "POST /api/boardgames/:id/plays calls HandleCreatePlaySession",
+			method: http.MethodPost,
+			path:   "/api/boardgames/1/plays",
+			checkCalled: func(m *mockPlaySessionHandler) bool {
+				return m.handleCreatePlaySessionCalled
+			},
+		},
+		{
+			name:   "GET /api/boardgames/:id/plays calls HandleGetPlaySessions",
+			method: http.MethodGet,
+			path:   "/api/boardgames/1/plays",
+			checkCalled: func(m *mockPlaySessionHandler) bool {
+				return m.handleGetPlaySessionsCalled
+			},
+		},
+		{
+			name:   "GET /api/boardgames/:id/plays/:playId calls HandleGetPlaySessionByID",
+			method: http.MethodGet,
+			path:   "/api/boardgames/1/plays/2",
+			checkCalled: func(m *mockPlaySessionHandler) bool {
+				return m.handleGetPlaySessionByIDCalled
+			},
+		},
+		{
+			name:   "PUT /api/boardgames/:id/plays/:playId calls HandleUpdatePlaySession",
+			method: http.MethodPut,
+			path:   "/api/boardgames/1/plays/2",
+			checkCalled: func(m *mockPlaySessionHandler) bool {
+				return m.handleUpdatePlaySessionCalled
+			},
+		},
+		{
+			name:   "DELETE /api/boardgames/:id/plays/:playId calls HandleDeletePlaySession",
+			method: http.MethodDelete,
+			path:   "/api/boardgames/1/plays/2",
+			checkCalled: func(m *mockPlaySessionHandler) bool {
+				return m.handleDeletePlaySessionCalled
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			mockHandler := &mockPlaySessionHandler{}
+
+			handlers := mockHandlers()
+			handlers.PlaySession = mockHandler
+			RegisterRoutes(router, handlers)
+
+			// Act
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			if rec.Code == http.StatusNotFound {
+				t.Fatal("expected route to be registered, got 404")
+			}
+
+			if !tt.checkCalled(mockHandler) {
+				t.Fatal("expected handler method to be called")
+			}
+		})
+	}
+}
+
+func TestRegisterRoutes_Players(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		checkCalled func(*mockPlayerHandler) bool
+	}{
+		{
+			name:   "POST /api/players calls HandleCreatePlayer",
+			method: http.MethodPost,
+			path:   "/api/players",
+			checkCalled: func(m *mockPlayerHandler) bool {
+				return m.handleCreatePlayerCalled
+			},
+		},
+		{
+			name:   "GET /api/players calls HandleGetPlayers",
+			method: http.MethodGet,
+			path:   "/api/players",
+			checkCalled: func(m *mockPlayerHandler) bool {
+				return m.handleGetPlayersCalled
+			},
+		},
+		{
+			name:   "GET /api/players/:id calls HandleGetPlayerByID",
+			method: http.MethodGet,
+			path:   "/api/players/1",
+			checkCalled: func(m *mockPlayerHandler) bool {
+				return m.handleGetPlayerByIDCalled
+			},
+		},
+		{
+			name:   "PUT /api/players/:id calls HandleUpdatePlayer",
+			method: http.MethodPut,
+			path:   "/api/players/1",
+			checkCalled: func(m *mockPlayerHandler) bool {
+				return m.handleUpdatePlayerCalled
+			},
+		},
+		{
+			name:   "DELETE /api/players/:id calls HandleDeletePlayer",
+			method: http.MethodDelete,
+			path:   "/api/players/1",
+			checkCalled: func(m *mockPlayerHandler) bool {
+				return m.handleDeletePlayerCalled
+			},
+		},
+		{
+			name:   "POST /api/players/:id/avatar calls HandleUploadPlayerAvatar",
+			method: http.MethodPost,
+			path:   "/api/players/1/avatar",
+			checkCalled: func(m *mockPlayerHandler) bool {
+				return m.handleUploadPlayerAvatarCalled
+			},
+		},
+		{
+			name:   "GET /api/players/:id/avatar calls HandleGetPlayerAvatar",
+			method: http.MethodGet,
+			path:   "/api/players/1/avatar",
+			checkCalled: func(m *mockPlayerHandler) bool {
+				return m.handleGetPlayerAvatarCalled
+			},
+		},
+		{
+			name:   "DELETE /api/players/:id/avatar calls HandleDeletePlayerAvatar",
+			method: http.MethodDelete,
+			path:   "/api/players/1/avatar",
+			checkCalled: func(m *mockPlayerHandler) bool {
+				return m.handleDeletePlayerAvatarCalled
+			},
+		},
+		{
+			name:   "GET /api/players/:id/stats calls HandleGetPlayerStats",
+			method: http.MethodGet,
+			path:   "/api/players/1/stats",
+			checkCalled: func(m *mockPlayerHandler) bool {
+				return m.handleGetPlayerStatsCalled
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			mockHandler := &mockPlayerHandler{}
+
+			handlers := mockHandlers()
+			handlers.Player = mockHandler
+			RegisterRoutes(router, handlers)
+
+			// Act
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			if rec.Code == http.StatusNotFound {
+				t.Fatal("expected route to be registered, got 404")
+			}
+
+			if !tt.checkCalled(mockHandler) {
+				t.Fatal("expected handler method to be called")
+			}
+		})
+	}
+}
+
+func TestRegisterRoutes_Leaderboards(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		checkCalled func(*mockLeaderboardHandler) bool
+	}{
+		{
+			name:   "GET /api/leaderboard calls HandleGetLeaderboard",
+			method: http.MethodGet,
+			path:   "/api/leaderboard",
+			checkCalled: func(m *mockLeaderboardHandler) bool {
+				return m.handleGetLeaderboardCalled
+			},
+		},
+		{
+			name:   "GET /api/boardgames/:id/leaderboard calls HandleGetBoardGameLeaderboard",
+			method: http.MethodGet,
+			path:   "/api/boardgames/1/leaderboard",
+			checkCalled: func(m *mockLeaderboardHandler) bool {
+				return m.handleGetBoardGameLeaderboardCalled
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			mockHandler := &mockLeaderboardHandler{}
+
+			handlers := mockHandlers()
+			handlers.Leaderboard = mockHandler
+			RegisterRoutes(router, handlers)
+
+			// Act
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			if rec.Code == http.StatusNotFound {
+				t.Fatal("expected route to be registered, got 404")
+			}
+
+			if !tt.checkCalled(mockHandler) {
+				t.Fatal("expected handler method to be called")
+			}
+		})
+	}
+}
+
+func TestRegisterRoutes_Tags(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		checkCalled func(*mockTagHandler) bool
+	}{
+		{
+			name:   "POST /api/tags calls HandleCreateTag",
+			method: http.MethodPost,
+			path:   "/api/tags",
+			checkCalled: func(m *mockTagHandler) bool {
+				return m.handleCreateTagCalled
+			},
+		},
+		{
+			name:   "GET /api/tags calls HandleGetTags",
+			method: http.MethodGet,
+			path:   "/api/tags?kind=mechanic",
+			checkCalled: func(m *mockTagHandler) bool {
+				return m.handleGetTagsCalled
+			},
+		},
+		{
+			name:   "GET /api/tags/:id calls HandleGetTagByID",
+			method: http.MethodGet,
+			path:   "/api/tags/1",
+			checkCalled: func(m *mockTagHandler) bool {
+				return m.handleGetTagByIDCalled
+			},
+		},
+		{
+			name:   "PUT /api/tags/:id calls HandleUpdateTag",
+			method: http.MethodPut,
+			path:   "/api/tags/1",
+			checkCalled: func(m *mockTagHandler) bool {
+				return m.handleUpdateTagCalled
+			},
+		},
+		{
+			name:   "DELETE /api/tags/:id calls HandleDeleteTag",
+			method: http.MethodDelete,
+			path:   "/api/tags/1",
+			checkCalled: func(m *mockTagHandler) bool {
+				return m.handleDeleteTagCalled
+			},
+		},
+		{
+			name:   "POST /api/tags/:id/merge calls HandleMergeTag",
+			method: http.MethodPost,
+			path:   "/api/tags/1/merge",
+			checkCalled: func(m *mockTagHandler) bool {
+				return m.handleMergeTagCalled
+			},
+		},
+		{
+			name:   "GET /api/boardgames/:id/tags calls HandleGetBoardGameTags",
+			method: http.MethodGet,
+			path:   "/api/boardgames/1/tags",
+			checkCalled: func(m *mockTagHandler) bool {
+				return m.handleGetBoardGameTagsCalled
+			},
+		},
+		{
+			name:   "POST /api/boardgames/:id/tags calls HandleAssignBoardGameTags",
+			method: http.MethodPost,
+			path:   "/api/boardgames/1/tags",
+			checkCalled: func(m *mockTagHandler) bool {
+				return m.handleAssignBoardGameTagsCalled
+			},
+		},
+		{
+			name:   "DELETE /api/boardgames/:id/tags/:tagId calls HandleUnassignBoardGameTag",
+			method: http.MethodDelete,
+			path:   "/api/boardgames/1/tags/2",
+			checkCalled: func(m *mockTagHandler) bool {
+				return m.handleUnassignBoardGameTagCalled
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			mockHandler := &mockTagHandler{}
+
+			handlers := mockHandlers()
+			handlers.Tag = mockHandler
+			RegisterRoutes(router, handlers)
+
+			// Act
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			if rec.Code == http.StatusNotFound {
+				t.Fatal("expected route to be registered, got 404")
+			}
+
+			if !tt.checkCalled(mockHandler) {
+				t.Fatal("expected handler method to be called")
+			}
+		})
+	}
+}
+
+func TestRegisterRoutes_Credits(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		checkCalled func(*mockCreditHandler) bool
+	}{
+		{
+			name:   "POST /api/people calls HandleCreatePerson",
+			method: http.MethodPost,
+			path:   "/api/people",
+			checkCalled: func(m *mockCreditHandler) bool {
+				return m.handleCreatePersonCalled
+			},
+		},
+		{
+			name:   "GET /api/people calls HandleGetPeople",
+			method: http.MethodGet,
+			path:   "/api/people",
+			checkCalled: func(m *mockCreditHandler) bool {
+				return m.handleGetPeopleCalled
+			},
+		},
+		{
+			name:   "GET /api/people/:id calls HandleGetPersonByID",
+			method: http.MethodGet,
+			path:   "/api/people/1",
+			checkCalled: func(m *mockCreditHandler) bool {
+				return m.handleGetPersonByIDCalled
+			},
+		},
+		{
+			name:   "PUT /api/people/:id calls HandleUpdatePerson",
+			method: http.MethodPut,
+			path:   "/api/people/1",
+			checkCalled: func(m *mockCreditHandler) bool {
+				return m.handleUpdatePersonCalled
+			},
+		},
+		{
+			name:   "DELETE /api/people/:id calls HandleDeletePerson",
+			method: http.MethodDelete,
+			path:   "/api/people/1",
+			checkCalled: func(m *mockCreditHandler) bool {
+				return m.handleDeletePersonCalled
+			},
+		},
+		{
+			name:   "GET /api/people/:id/games calls HandleGetPersonGames",
+			method: http.MethodGet,
+			path:   "/api/people/1/games",
+			checkCalled: func(m *mockCreditHandler) bool {
+				return m.handleGetPersonGamesCalled
+			},
+		},
+		{
+			name:   "GET /api/designers calls HandleGetDesigners",
+			method: http.MethodGet,
+			path:   "/api/designers",
+			checkCalled: func(m *mockCreditHandler) bool {
+				return m.handleGetDesignersCalled
+			},
+		},
+		{
+			name:   "GET /api/designers/:id/games calls HandleGetDesignerGames",
+			method: http.MethodGet,
+			path:   "/api/designers/1/games",
+			checkCalled: func(m *mockCreditHandler) bool {
+				return m.handleGetDesignerGamesCalled
+			},
+		},
+		{
+			name:   "GET /api/artists calls HandleGetArtists",
+			method: http.MethodGet,
+			path:   "/api/artists",
+			checkCalled: func(m *mockCreditHandler) bool {
+				return m.handleGetArtistsCalled
+			},
+		},
+		{
+			name:   "GET /api/artists/:id/games calls HandleGetArtistGames",
+			method: http.MethodGet,
+			path:   "/api/artists/1/games",
+			checkCalled: func(m *mockCreditHandler) bool {
+				return m.handleGetArtistGamesCalled
+			},
+		},
+		{
+			name:   "POST /api/publishers calls HandleCreatePublisher",
+			method: http.MethodPost,
+			path:   "/api/publishers",
+			checkCalled: func(m *mockCreditHandler) bool {
+				return m.handleCreatePublisherCalled
+			},
+		},
+		{
+			name:   "GET /api/publishers calls HandleGetPublishers",
+			method: http.MethodGet,
+			path:   "/api/publishers",
+			checkCalled: func(m *mockCreditHandler) bool {
+				return m.handleGetPublishersCalled
+			},
+		},
+		{
+			name:   "GET /api/publishers/:id calls HandleGetPublisherByID",
+			method: http.MethodGet,
+			path:   "/api/publishers/1",
+			checkCalled: func(m *mockCreditHandler) bool {
+				return m.handleGetPublisherByIDCalled
+			},
+		},
+		{
+			name:   "PUT /api/publishers/:id calls HandleUpdatePublisher",
+			method: http.MethodPut,
+			path:   "/api/publishers/1",
+			checkCalled: func(m *mockCreditHandler) bool {
+				return m.handleUpdatePublisherCalled
+			},
+		},
+		{
+			name:   "DELETE /api/publishers/:id calls HandleDeletePublisher",
+			method: http.MethodDelete,
+			path:   "/api/publishers/1",
+			checkCalled: func(m *mockCreditHandler) bool {
+				return m.handleDeletePublisherCalled
+			},
+		},
+		{
+			name:   "GET /api/publishers/:id/games calls HandleGetPublisherGames",
+			method: http.MethodGet,
+			path:   "/api/publishers/1/games",
+			checkCalled: func(m *mockCreditHandler) bool {
+				return m.handleGetPublisherGamesCalled
+			},
+		},
+		{
+			name:   "PUT /api/boardgames/:id/credits calls HandleSetBoardGameCredits",
+			method: http.MethodPut,
+			path:   "/api/boardgames/1/credits",
+			checkCalled: func(m *mockCreditHandler) bool {
+				return m.handleSetBoardGameCreditsCalled
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			mockHandler := &mockCreditHandler{}
+
+			handlers := mockHandlers()
+			handlers.Credit = mockHandler
+			RegisterRoutes(router, handlers)
+
+			// Act
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			if rec.Code == http.StatusNotFound {
+				t.Fatal("expected route to be registered, got 404")
+			}
+
+			if !tt.checkCalled(mockHandler) {
+				t.Fatal("expected handler method to be called")
+			}
+		})
+	}
+}
+
+func TestRegisterRoutes_Expansions(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		checkCalled func(*mockExpansionHandler) bool
+	}{
+		{
+			name:   "PUT /api/boardgames/:id/base calls HandleSetBaseGame",
+			method: http.MethodPut,
+			path:   "/api/boardgames/1/base",
+			checkCalled: func(m *mockExpansionHandler) bool {
+				return m.handleSetBaseGameCalled
+			},
+		},
+		{
+			name:   "DELETE /api/boardgames/:id/base calls HandleRemoveBaseGame",
+			method: http.MethodDelete,
+			path:   "/api/boardgames/1/base",
+			checkCalled: func(m *mockExpansionHandler) bool {
+				return m.handleRemoveBaseGameCalled
+			},
+		},
+		{
+			name:   "GET /api/boardgames/:id/expansions calls HandleGetExpansions",
+			method: http.MethodGet,
+			path:   "/api/boardgames/1/expansions",
+			checkCalled: func(m *mockExpansionHandler) bool {
+				return m.handleGetExpansionsCalled
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			mockHandler := &mockExpansionHandler{}
+
+			handlers := mockHandlers()
+			handlers.Expansion = mockHandler
+			RegisterRoutes(router, handlers)
+
+			// Act
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			if rec.Code == http.StatusNotFound {
+				t.Fatal("expected route to be registered, got 404")
+			}
+
+			if !tt.checkCalled(mockHandler) {
+				t.Fatal("expected handler method to be called")
+			}
+		})
+	}
+}
+
+func TestRegisterRoutes_Imports(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		checkCalled func(*mockImportHandler) bool
+	}{
+		{
+			name:   "POST /api/import/bgg calls HandleImportBGG",
+			method: http.MethodPost,
+			path:   "/api/import/bgg",
+			checkCalled: func(m *mockImportHandler) bool {
+				return m.handleImportBGGCalled
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			mockHandler := &mockImportHandler{}
+
+			handlers := mockHandlers()
+			handlers.Import = mockHandler
+			RegisterRoutes(router, handlers)
+
+			// Act
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			if rec.Code == http.StatusNotFound {
+				t.Fatal("expected route to be registered, got 404")
+			}
+
+			if !tt.checkCalled(mockHandler) {
+				t.Fatal("expected handler method to be called")
+			}
+		})
+	}
+}
+
+func TestRegisterRoutes_Collection(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		checkCalled func(*mockCollectionHandler) bool
+	}{
+		{
+			name:   "GET /api/export calls HandleExport",
+			method: http.MethodGet,
+			path:   "/api/export",
+			checkCalled: func(m *mockCollectionHandler) bool {
+				return m.handleExportCalled
+			},
+		},
+		{
+			name:   "POST /api/import calls HandleImport",
+			method: http.MethodPost,
+			path:   "/api/import",
+			checkCalled: func(m *mockCollectionHandler) bool {
+				return m.handleImportCalled
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			mockHandler := &mockCollectionHandler{}
+
+			handlers := mockHandlers()
+			handlers.Collection = mockHandler
+			RegisterRoutes(router, handlers)
+
+			// Act
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			if rec.Code == http.StatusNotFound {
+				t.Fatal("expected route to be registered, got 404")
+			}
+
+			if !tt.checkCalled(mockHandler) {
+				t.Fatal("expected handler method to be called")
+			}
+		})
+	}
+}
+
+func TestRegisterRoutes_Backup(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	mockHandler := &mockBackupHandler{}
+
+	handlers := mockHandlers()
+	handlers.Backup = mockHandler
+	RegisterRoutes(router, handlers)
+
+	// Act
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/backup", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	// Assert
+	if rec.Code == http.StatusNotFound {
+		t.Fatal("expected route to be registered, got 404")
+	}
+
+	if !mockHandler.handleBackupCalled {
+		t.Fatal("expected handler method to be called")
+	}
+}
+
+func TestRegisterRoutes_Auth(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		checkCalled func(*mockAuthHandler) bool
+	}{
+		{
+			name:   "POST /api/auth/register calls HandleRegister",
+			method: http.MethodPost,
+			path:   "/api/auth/register",
+			checkCalled: func(m *mockAuthHandler) bool {
+				return m.handleRegisterCalled
+			},
+		},
+		{
+			name:   "POST /api/auth/login calls HandleLogin",
+			method: http.MethodPost,
+			path:   "/api/auth/login",
+			checkCalled: func(m *mockAuthHandler) bool {
+				return m.handleLoginCalled
+			},
+		},
+		{
+			name:   "POST /api/auth/logout calls HandleLogout",
+			method: http.MethodPost,
+			path:   "/api/auth/logout",
+			checkCalled: func(m *mockAuthHandler) bool {
+				return m.handleLogoutCalled
+			},
+		},
+		{
+			name:   "GET /api/auth/providers calls HandleProviders",
+			method: http.MethodGet,
+			path:   "/api/auth/providers",
+			checkCalled: func(m *mockAuthHandler) bool {
+				return m.handleProvidersCalled
+			},
+		},
+		{
+			name:   "GET /api/auth/oidc/login calls HandleOIDCLogin",
+			method: http.MethodGet,
+			path:   "/api/auth/oidc/login",
+			checkCalled: func(m *mockAuthHandler) bool {
+				return m.handleOIDCLoginCalled
+			},
+		},
+		{
+			name:   "GET /api/auth/oidc/callback calls HandleOIDCCallback",
+			method: http.MethodGet,
+			path:   "/api/auth/oidc/callback",
+			checkCalled: func(m *mockAuthHandler) bool {
+				return m.handleOIDCCallbackCalled
+			},
+		},
+		{
+			name:   "GET /api/auth/me calls HandleMe",
+			method: http.MethodGet,
+			path:   "/api/auth/me",
+			checkCalled: func(m *mockAuthHandler) bool {
+				return m.handleMeCalled
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			mockHandler := &mockAuthHandler{}
+
+			handlers := mockHandlers()
+			handlers.Auth = mockHandler
+			RegisterRoutes(router, handlers)
+
+			// Act
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			if rec.Code == http.StatusNotFound {
+				t.Fatal("expected route to be registered, got 404")
+			}
+
+			if !tt.checkCalled(mockHandler) {
+				t.Fatal("expected handler method to be called")
+			}
+		})
+	}
+}
+
+func TestRegisterRoutes_SignInRequired(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	boardGameHandler := &mockBoardGameHandler{}
+	authHandler := &mockAuthHandler{}
+
+	handlers := mockHandlers()
+	handlers.BoardGame = boardGameHandler
+	handlers.Auth = authHandler
+	handlers.Authenticate = func(c *gin.Context) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+	}
+	RegisterRoutes(router, handlers)
+
+	// Act
+	games := httptest.NewRecorder()
+	router.ServeHTTP(games, httptest.NewRequest(http.MethodGet, "/api/boardgames", nil))
+	login := httptest.NewRecorder()
+	router.ServeHTTP(login, httptest.NewRequest(http.MethodPost, "/api/auth/login", nil))
+
+	// Assert
+	if games.Code != http.StatusUnauthorized || boardGameHandler.handleGetBoardGamesCalled {
+		t.Errorf("expected the shelf to need signing in, got %d", games.Code)
+	}
+
+	if !authHandler.handleLoginCalled {
+		t.Error("expected signing in to be open")
+	}
+}
+
+func TestRegisterRoutes_AdminOnly(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	mockHandler := &mockBackupHandler{}
+
+	handlers := mockHandlers()
+	handlers.Backup = mockHandler
+	handlers.Authenticate = func(c *gin.Context) {
+		middleware.SetCurrentUser(c, &models.User{ID: 2})
+	}
+	RegisterRoutes(router, handlers)
+
+	// Act
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/backup", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	// Assert
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403, got %d", rec.Code)
+	}
+
+	if mockHandler.handleBackupCalled {
+		t.Fatal("expected the backup not to run for a user who is not admin")
+	}
+}
+
+func TestRegisterRoutes_OwnedGame(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		status int
+	}{
+		{http.MethodPost, "/api/boardgames/1/plays", http.StatusForbidden},
+		{http.MethodGet, "/api/boardgames/1/plays", http.StatusNotFound},
+		{http.MethodPut, "/api/boardgames/1/plays/2", http.StatusForbidden},
+		{http.MethodDelete, "/api/boardgames/1/plays/2", http.StatusForbidden},
+		{http.MethodGet, "/api/boardgames/1/leaderboard", http.StatusNotFound},
+		{http.MethodGet, "/api/boardgames/1/tags", http.StatusNotFound},
+		{http.MethodPost, "/api/boardgames/1/tags", http.StatusForbidden},
+		{http.MethodDelete, "/api/boardgames/1/tags/2", http.StatusForbidden},
+		{http.MethodPut, "/api/boardgames/1/credits", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+
+			// Reads check membership, writes check the role
+			handlers := mockHandlers()
+			handlers.RequireBoardGame = func(c *gin.Context) {
+				c.AbortWithStatus(http.StatusNotFound)
+			}
+			handlers.RequireBoardGameEditor = func(c *gin.Context) {
+				c.AbortWithStatus(http.StatusForbidden)
+			}
+			RegisterRoutes(router, handlers)
+
+			// Act
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			if rec.Code != tt.status {
+				t.Fatalf("expected the game check to answer %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+}
+
+func TestRegisterRoutes_Households(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		checkCalled func(*mockHouseholdHandler) bool
+	}{
+		{
+			name:   "POST /api/households calls HandleCreateHousehold",
+			method: http.MethodPost,
+			path:   "/api/households",
+			checkCalled: func(m *mockHouseholdHandler) bool {
+				return m.handleCreateHouseholdCalled
+			},
+		},
+		{
+			name:   "GET /api/households calls HandleGetHouseholds",
+			method: http.MethodGet,
+			path:   "/api/households",
+			checkCalled: func(m *mockHouseholdHandler) bool {
+				return m.handleGetHouseholdsCalled
+			},
+		},
+		{
+			name:   "GET /api/households/:id calls HandleGetHouseholdByID",
+			method: http.MethodGet,
+			path:   "/api/households/1",
+			checkCalled: func(m *mockHouseholdHandler) bool {
+				return m.handleGetHouseholdByIDCalled
+			},
+		},
+		{
+			name:   "PUT /api/households/:id calls HandleRenameHousehold",
+			method: http.MethodPut,
+			path:   "/api/households/1",
+			checkCalled: func(m *mockHouseholdHandler) bool {
+				return m.handleRenameHouseholdCalled
+			},
+		},
+		{
+			name:   "POST /api/households/:id/invitations calls HandleCreateInvitation",
+			method: http.MethodPost,
+			path:   "/api/households/1/invitations",
+			checkCalled: func(m *mockHouseholdHandler) bool {
+				return m.handleCreateInvitationCalled
+			},
+		},
+		{
+			name:   "PUT /api/households/:id/members/:userId calls HandleSetMemberRole",
+			method: http.MethodPut,
+			path:   "/api/households/1/members/2",
+			checkCalled: func(m *mockHouseholdHandler) bool {
+				return m.handleSetMemberRoleCalled
+			},
+		},
+		{
+			name:   "DELETE /api/households/:id/members/:userId calls HandleRemoveMember",
+			method: http.MethodDelete,
+			path:   "/api/households/1/members/2",
+			checkCalled: func(m *mockHouseholdHandler) bool {
+				return m.handleRemoveMemberCalled
+			},
+		},
+		{
+			name:   "POST /api/invitations/accept calls HandleAcceptInvitation",
+			method: http.MethodPost,
+			path:   "/api/invitations/accept",
+			checkCalled: func(m *mockHouseholdHandler) bool {
+				return m.handleAcceptInvitationCalled
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			mockHandler := &mockHouseholdHandler{}
+
+			handlers := mockHandlers()
+			handlers.Household = mockHandler
+			RegisterRoutes(router, handlers)
+
+			// Act
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			if rec.Code == http.StatusNotFound {
+				t.Fatal("expected route to be registered, got 404")
+			}
+			if !tt.checkCalled(mockHandler) {
+				t.Error("expected handler to be called")
+			}
+		})
+	}
+}
+
+func TestRegisterRoutes_APIKeys(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		checkCalled func(*mockAPIKeyHandler) bool
+	}{
+		{
+			name:   "POST /api/keys calls HandleCreateAPIKey",
+			method: http.MethodPost,
+			path:   "/api/keys",
+			checkCalled: func(m *mockAPIKeyHandler) bool {
+				return m.handleCreateAPIKeyCalled
+			},
+		},
+		{
+			name:   "GET /api/keys calls HandleGetAPIKeys",
+			method: http.MethodGet,
+			path:   "/api/keys",
+			checkCalled: func(m *mockAPIKeyHandler) bool {
+				return m.handleGetAPIKeysCalled
+			},
+		},
+		{
+			name:   "DELETE /api/keys/:id calls HandleRevokeAPIKey",
+			method: http.MethodDelete,
+			path:   "/api/keys/1",
+			checkCalled: func(m *mockAPIKeyHandler) bool {
+				return m.handleRevokeAPIKeyCalled
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			mockHandler := &mockAPIKeyHandler{}
+
+			handlers := mockHandlers()
+			handlers.APIKey = mockHandler
+			RegisterRoutes(router, handlers)
+
+			// Act
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			if rec.Code == http.StatusNotFound {
+				t.Fatal("expected route to be registered, got 404")
+			}
+			if !tt.checkCalled(mockHandler) {
+				t.Error("expected handler to be called")
+			}
+		})
+	}
+}
+
+func TestRegisterRoutes_APIKeyScopes(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		status int
+	}{
+		{http.MethodGet, "/api/boardgames", http.StatusOK},
+		{http.MethodGet, "/api/boardgame/images/1", http.StatusOK},
+		{http.MethodGet, "/api/boardgames/1/expansions", http.StatusOK},
+		{http.MethodPut, "/api/boardgames/1", http.StatusOK},
+		{http.MethodPost, "/api/boardgame/1/images", http.StatusForbidden},
+		{http.MethodDelete, "/api/boardgame/images/1", http.StatusForbidden},
+		{http.MethodGet, "/api/players", http.StatusForbidden},
+		{http.MethodGet, "/api/boardgames/1/plays", http.StatusForbidden},
+		{http.MethodPost, "/api/keys", http.StatusForbidden},
+		{http.MethodPost, "/api/boardgames/1/loans", http.StatusForbidden},
+		{http.MethodGet, "/api/admin/backup", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+
+			// An admin's key that reads and writes games, but not images
+			handlers := mockHandlers()
+			handlers.Authenticate = func(c *gin.Context) {
+				middleware.SetCurrentUser(c, &models.User{ID: 1, IsAdmin: true})
+				middleware.SetCurrentAPIKey(c, &models.APIKey{Scopes: []models.APIKeyScope{models.ScopeGamesRead, models.ScopeGamesWrite}})
+			}
+			RegisterRoutes(router, handlers)
+
+			// Act
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, rec.Code)
+			}
+		})
+	}
+}
+
+func TestRegisterRoutes_Loans(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		checkCalled func(*mockLoanHandler) bool
+	}{
+		{
+			name:   "POST /api/boardgames/:id/loans calls HandleLendBoardGame",
+			method: http.MethodPost,
+			path:   "/api/boardgames/1/loans",
+			checkCalled: func(m *mockLoanHandler) bool {
+				return m.handleLendBoardGameCalled
+			},
+		},
+		{
+			name:   "GET /api/boardgames/:id/loans calls HandleGetBoardGameLoans",
+			method: http.MethodGet,
+			path:   "/api/boardgames/1/loans",
+			checkCalled: func(m *mockLoanHandler) bool {
+				return m.handleGetBoardGameLoansCalled
 			},
 		},
 		{
-			name:   "DELETE /api/boardgames/:id calls HandleBoardGameDelete",
-			method: http.MethodDelete,
-			path:   "/api/boardgames/1",
-			checkCalled: func(m *mockBoardGameHandler) bool {
-				return m.handleBoardGameDeleteCalled
+			name:   "POST /api/loans/:id/return calls HandleReturnLoan",
+			method: http.MethodPost,
+			path:   "/api/loans/1/return",
+			checkCalled: func(m *mockLoanHandler) bool {
+				return m.handleReturnLoanCalled
+			},
+		},
+		{
+			name:   "GET /api/loans calls HandleGetLoans",
+			method: http.MethodGet,
+			path:   "/api/loans?status=overdue",
+			checkCalled: func(m *mockLoanHandler) bool {
+				return m.handleGetLoansCalled
 			},
 		},
 	}
@@ -54,9 +1314,11 @@ func TestRegisterRoutes(t *testing.T) {
 			// Arrange
 			gin.SetMode(gin.TestMode)
 			router := gin.New()
-			mockHandler := &mockBoardGameHandler{}
+			mockHandler := &mockLoanHandler{}
 
-			RegisterRoutes(router, mockHandler)
+			handlers := mockHandlers()
+			handlers.Loan = mockHandler
+			RegisterRoutes(router, handlers)
 
 			// Act
 			req := httptest.NewRequest(tt.method, tt.path, nil)
@@ -67,19 +1329,126 @@ func TestRegisterRoutes(t *testing.T) {
 			if rec.Code == http.StatusNotFound {
 				t.Fatal("expected route to be registered, got 404")
 			}
-
 			if !tt.checkCalled(mockHandler) {
-				t.Fatal("expected handler method to be called")
+				t.Error("expected handler to be called")
+			}
+		})
+	}
+}
+
+func TestRegisterRoutes_SharedCatalogAdminOnly(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPut, "/api/tags/1"},
+		{http.MethodDelete, "/api/tags/1"},
+		{http.MethodPost, "/api/tags/1/merge"},
+		{http.MethodPut, "/api/people/1"},
+		{http.MethodDelete, "/api/people/1"},
+		{http.MethodPut, "/api/publishers/1"},
+		{http.MethodDelete, "/api/publishers/1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			// Arrange: a viewer, who is no admin
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			tagHandler := &mockTagHandler{}
+			creditHandler := &mockCreditHandler{}
+
+			handlers := mockHandlers()
+			handlers.Tag = tagHandler
+			handlers.Credit = creditHandler
+			handlers.Authenticate = func(c *gin.Context) {
+				middleware.SetCurrentUser(c, &models.User{ID: 2})
+			}
+			RegisterRoutes(router, handlers)
+
+			// Act
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			if rec.Code != http.StatusForbidden {
+				t.Fatalf("expected status 403, got %d", rec.Code)
+			}
+
+			if *tagHandler != (mockTagHandler{}) || *creditHandler != (mockCreditHandler{}) {
+				t.Fatal("expected no handler to run for a user who is not admin")
 			}
 		})
 	}
 }
 
+func TestRegisterRoutes_SharedCatalogReadable(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	mockHandler := &mockTagHandler{}
+
+	handlers := mockHandlers()
+	handlers.Tag = mockHandler
+	handlers.Authenticate = func(c *gin.Context) {
+		middleware.SetCurrentUser(c, &models.User{ID: 2})
+	}
+	RegisterRoutes(router, handlers)
+
+	// Act
+	req := httptest.NewRequest(http.MethodGet, "/api/tags", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	// Assert
+	if !mockHandler.handleGetTagsCalled {
+		t.Fatalf("expected every user to list the tags, got %d", rec.Code)
+	}
+}
+
+// Every handler mocked, each test swaps in the one it checks
+func mockHandlers() Handlers {
+	return Handlers{
+		BoardGame:   &mockBoardGameHandler{},
+		PlaySession: &mockPlaySessionHandler{},
+		Player:      &mockPlayerHandler{},
+		Leaderboard: &mockLeaderboardHandler{},
+		Tag:         &mockTagHandler{},
+		Credit:      &mockCreditHandler{},
+		Expansion:   &mockExpansionHandler{},
+		Import:      &mockImportHandler{},
+		Collection:  &mockCollectionHandler{},
+		Backup:      &mockBackupHandler{},
+		Auth:        &mockAuthHandler{},
+		Household:   &mockHouseholdHandler{},
+		APIKey:      &mockAPIKeyHandler{},
+		Loan:        &mockLoanHandler{},
+
+		// Signed in as an admin, the real checks are swapped in where tested
+		Authenticate: func(c *gin.Context) {
+			middleware.SetCurrentUser(c, &models.User{ID: 1, IsAdmin: true})
+		},
+		RequireBoardGame:       func(c *gin.Context) {},
+		RequireBoardGameEditor: func(c *gin.Context) {},
+	}
+}
+
 type mockBoardGameHandler struct {
 	handleBoardGameCreateCalled  bool
 	handleGetBoardGamesCalled    bool
+	handleSearchBoardGamesCalled bool
+	handleRecommendTonightCalled bool
 	handleGetBoardGameByIDCalled bool
+	handleBoardGameUpdateCalled  bool
+	handleBoardGamePatchCalled   bool
 	handleBoardGameDeleteCalled  bool
+
+	handleListBoardGameImagesCalled        bool
+	handleGetBoardGameImageCalled          bool
+	handleGetBoardGameImageThumbnailCalled bool
+	handleDeleteBoardGameImageCalled       bool
+	handleReorderBoardGameImagesCalled     bool
 }
 
 func (m *mockBoardGameHandler) HandleBoardGameCreate(c *gin.Context) {
@@ -88,10 +1457,24 @@ func (m *mockBoardGameHandler) HandleBoardGameCreate(c *gin.Context) {
 func (m *mockBoardGameHandler) HandleGetBoardGames(c *gin.Context) {
 	m.handleGetBoardGamesCalled = true
 }
+func (m *mockBoardGameHandler) HandleSearchBoardGames(c *gin.Context) {
+	m.handleSearchBoardGamesCalled = true
+}
+func (m *mockBoardGameHandler) HandleRecommendTonight(c *gin.Context) {
+	m.handleRecommendTonightCalled = true
+}
 func (m *mockBoardGameHandler) HandleGetBoardGameByID(c *gin.Context) {
 	m.handleGetBoardGameByIDCalled = true
 }
 
+func (m *mockBoardGameHandler) HandleBoardGameUpdate(c *gin.Context) {
+	m.handleBoardGameUpdateCalled = true
+}
+
+func (m *mockBoardGameHandler) HandleBoardGamePatch(c *gin.Context) {
+	m.handleBoardGamePatchCalled = true
+}
+
 func (m *mockBoardGameHandler) HandleBoardGameDelete(c *gin.Context) {
 	m.handleBoardGameDeleteCalled = true
 }
@@ -104,3 +1487,423 @@ func (m *mockBoardGameHandler) HandleUploadBoardGameImage(c *gin.Context) {
 func (*mockBoardGameHandler) HandleGetBoardGameCoverImage(c *gin.Context) {
 	// Not needed for this test
 }
+
+func (m *mockBoardGameHandler) HandleListBoardGameImages(c *gin.Context) {
+	m.handleListBoardGameImagesCalled = true
+}
+
+func (m *mockBoardGameHandler) HandleGetBoardGameImage(c *gin.Context) {
+	m.handleGetBoardGameImageCalled = true
+}
+
+func (m *mockBoardGameHandler) HandleGetBoardGameImageThumbnail(c *gin.Context) {
+	m.handleGetBoardGameImageThumbnailCalled = true
+}
+
+func (m *mockBoardGameHandler) HandleDeleteBoardGameImage(c *gin.Context) {
+	m.handleDeleteBoardGameImageCalled = true
+}
+
+func (m *mockBoardGameHandler) HandleReorderBoardGameImages(c *gin.Context) {
+	m.handleReorderBoardGameImagesCalled = true
+}
+
+type mockPlaySessionHandler struct {
+	handleCreatePlaySessionCalled     bool
+	handleGetPlaySessionsCalled       bool
+	handleGetRecentPlaySessionsCalled bool
+	handleGetPlaySessionByIDCalled    bool
+	handleUpdatePlaySessionCalled     bool
+	handleDeletePlaySessionCalled     bool
+}
+
+func (m *mockPlaySessionHandler) HandleCreatePlaySession(c *gin.Context) {
+	m.handleCreatePlaySessionCalled = true
+}
+
+func (m *mockPlaySessionHandler) HandleGetPlaySessions(c *gin.Context) {
+	m.handleGetPlaySessionsCalled = true
+}
+
+func (m *mockPlaySessionHandler) HandleGetRecentPlaySessions(c *gin.Context) {
+	m.handleGetRecentPlaySessionsCalled = true
+}
+
+func (m *mockPlaySessionHandler) HandleGetPlaySessionByID(c *gin.Context) {
+	m.handleGetPlaySessionByIDCalled = true
+}
+
+func (m *mockPlaySessionHandler) HandleUpdatePlaySession(c *gin.Context) {
+	m.handleUpdatePlaySessionCalled = true
+}
+
+func (m *mockPlaySessionHandler) HandleDeletePlaySession(c *gin.Context) {
+	m.handleDeletePlaySessionCalled = true
+}
+
+type mockPlayerHandler struct {
+	handleCreatePlayerCalled       bool
+	handleGetPlayersCalled         bool
+	handleGetPlayerByIDCalled      bool
+	handleUpdatePlayerCalled       bool
+	handleDeletePlayerCalled       bool
+	handleUploadPlayerAvatarCalled bool
+	handleGetPlayerAvatarCalled    bool
+	handleDeletePlayerAvatarCalled bool
+	handleGetPlayerStatsCalled     bool
+}
+
+func (m *mockPlayerHandler) HandleCreatePlayer(c *gin.Context) {
+	m.handleCreatePlayerCalled = true
+}
+
+func (m *mockPlayerHandler) HandleGetPlayers(c *gin.Context) {
+	m.handleGetPlayersCalled = true
+}
+
+func (m *mockPlayerHandler) HandleGetPlayerByID(c *gin.Context) {
+	m.handleGetPlayerByIDCalled = true
+}
+
+func (m *mockPlayerHandler) HandleUpdatePlayer(c *gin.Context) {
+	m.handleUpdatePlayerCalled = true
+}
+
+func (m *mockPlayerHandler) HandleDeletePlayer(c *gin.Context) {
+	m.handleDeletePlayerCalled = true
+}
+
+func (m *mockPlayerHandler) HandleUploadPlayerAvatar(c *gin.Context) {
+	m.handleUploadPlayerAvatarCalled = true
+}
+
+func (m *mockPlayerHandler) HandleGetPlayerAvatar(c *gin.Context) {
+	m.handleGetPlayerAvatarCalled = true
+}
+
+func (m *mockPlayerHandler) HandleDeletePlayerAvatar(c *gin.Context) {
+	m.handleDeletePlayerAvatarCalled = true
+}
+
+func (m *mockPlayerHandler) HandleGetPlayerStats(c *gin.Context) {
+	m.handleGetPlayerStatsCalled = true
+}
+
+type mockLeaderboardHandler struct {
+	handleGetLeaderboardCalled          bool
+	handleGetBoardGameLeaderboardCalled bool
+}
+
+func (m *mockLeaderboardHandler) HandleGetLeaderboard(c *gin.Context) {
+	m.handleGetLeaderboardCalled = true
+}
+
+func (m *mockLeaderboardHandler) HandleGetBoardGameLeaderboard(c *gin.Context) {
+	m.handleGetBoardGameLeaderboardCalled = true
+}
+
+type mockTagHandler struct {
+	handleCreateTagCalled            bool
+	handleGetTagsCalled              bool
+	handleGetTagByIDCalled           bool
+	handleUpdateTagCalled            bool
+	handleDeleteTagCalled            bool
+	handleMergeTagCalled             bool
+	handleGetBoardGameTagsCalled     bool
+	handleAssignBoardGameTagsCalled  bool
+	handleUnassignBoardGameTagCalled bool
+}
+
+func (m *mockTagHandler) HandleCreateTag(c *gin.Context) {
+	m.handleCreateTagCalled = true
+}
+
+func (m *mockTagHandler) HandleGetTags(c *gin.Context) {
+	m.handleGetTagsCalled = true
+}
+
+func (m *mockTagHandler) HandleGetTagByID(c *gin.Context) {
+	m.handleGetTagByIDCalled = true
+}
+
+func (m *mockTagHandler) HandleUpdateTag(c *gin.Context) {
+	m.handleUpdateTagCalled = true
+}
+
+func (m *mockTagHandler) HandleDeleteTag(c *gin.Context) {
+	m.handleDeleteTagCalled = true
+}
+
+func (m *mockTagHandler) HandleMergeTag(c *gin.Context) {
+	m.handleMergeTagCalled = true
+}
+
+func (m *mockTagHandler) HandleGetBoardGameTags(c *gin.Context) {
+	m.handleGetBoardGameTagsCalled = true
+}
+
+func (m *mockTagHandler) HandleAssignBoardGameTags(c *gin.Context) {
+	m.handleAssignBoardGameTagsCalled = true
+}
+
+func (m *mockTagHandler) HandleUnassignBoardGameTag(c *gin.Context) {
+	m.handleUnassignBoardGameTagCalled = true
+}
+
+type mockCreditHandler struct {
+	handleCreatePersonCalled        bool
+	handleGetPeopleCalled           bool
+	handleGetPersonByIDCalled       bool
+	handleUpdatePersonCalled        bool
+	handleDeletePersonCalled        bool
+	handleGetPersonGamesCalled      bool
+	handleGetDesignersCalled        bool
+	handleGetDesignerGamesCalled    bool
+	handleGetArtistsCalled          bool
+	handleGetArtistGamesCalled      bool
+	handleCreatePublisherCalled     bool
+	handleGetPublishersCalled       bool
+	handleGetPublisherByIDCalled    bool
+	handleUpdatePublisherCalled     bool
+	handleDeletePublisherCalled     bool
+	handleGetPublisherGamesCalled   bool
+	handleSetBoardGameCreditsCalled bool
+}
+
+func (m *mockCreditHandler) HandleCreatePerson(c *gin.Context) {
+	m.handleCreatePersonCalled = true
+}
+
+func (m *mockCreditHandler) HandleGetPeople(c *gin.Context) {
+	m.handleGetPeopleCalled = true
+}
+
+func (m *mockCreditHandler) HandleGetPersonByID(c *gin.Context) {
+	m.handleGetPersonByIDCalled = true
+}
+
+func (m *mockCreditHandler) HandleUpdatePerson(c *gin.Context) {
+	m.handleUpdatePersonCalled = true
+}
+
+func (m *mockCreditHandler) HandleDeletePerson(c *gin.Context) {
+	m.handleDeletePersonCalled = true
+}
+
+func (m *mockCreditHandler) HandleGetPersonGames(c *gin.Context) {
+	m.handleGetPersonGamesCalled = true
+}
+
+func (m *mockCreditHandler) HandleGetDesigners(c *gin.Context) {
+	m.handleGetDesignersCalled = true
+}
+
+func (m *mockCreditHandler) HandleGetDesignerGames(c *gin.Context) {
+	m.handleGetDesignerGamesCalled = true
+}
+
+func (m *mockCreditHandler) HandleGetArtists(c *gin.Context) {
+	m.handleGetArtistsCalled = true
+}
+
+func (m *mockCreditHandler) HandleGetArtistGames(c *gin.Context) {
+	m.handleGetArtistGamesCalled = true
+}
+
+func (m *mockCreditHandler) HandleCreatePublisher(c *gin.Context) {
+	m.handleCreatePublisherCalled = true
+}
+
+func (m *mockCreditHandler) HandleGetPublishers(c *gin.Context) {
+	m.handleGetPublishersCalled = true
+}
+
+func (m *mockCreditHandler) HandleGetPublisherByID(c *gin.Context) {
+	m.handleGetPublisherByIDCalled = true
+}
+
+func (m *mockCreditHandler) HandleUpdatePublisher(c *gin.Context) {
+	m.handleUpdatePublisherCalled = true
+}
+
+func (m *mockCreditHandler) HandleDeletePublisher(c *gin.Context) {
+	m.handleDeletePublisherCalled = true
+}
+
+func (m *mockCreditHandler) HandleGetPublisherGames(c *gin.Context) {
+	m.handleGetPublisherGamesCalled = true
+}
+
+func (m *mockCreditHandler) HandleSetBoardGameCredits(c *gin.Context) {
+	m.handleSetBoardGameCreditsCalled = true
+}
+
+type mockExpansionHandler struct {
+	handleSetBaseGameCalled    bool
+	handleRemoveBaseGameCalled bool
+	handleGetExpansionsCalled  bool
+}
+
+func (m *mockExpansionHandler) HandleSetBaseGame(c *gin.Context) {
+	m.handleSetBaseGameCalled = true
+}
+
+func (m *mockExpansionHandler) HandleRemoveBaseGame(c *gin.Context) {
+	m.handleRemoveBaseGameCalled = true
+}
+
+func (m *mockExpansionHandler) HandleGetExpansions(c *gin.Context) {
+	m.handleGetExpansionsCalled = true
+}
+
+type mockImportHandler struct {
+	handleImportBGGCalled bool
+}
+
+func (m *mockImportHandler) HandleImportBGG(c *gin.Context) {
+	m.handleImportBGGCalled = true
+}
+
+type mockCollectionHandler struct {
+	handleExportCalled bool
+	handleImportCalled bool
+}
+
+func (m *mockCollectionHandler) HandleExport(c *gin.Context) {
+	m.handleExportCalled = true
+}
+
+func (m *mockCollectionHandler) HandleImport(c *gin.Context) {
+	m.handleImportCalled = true
+}
+
+type mockBackupHandler struct {
+	handleBackupCalled bool
+}
+
+func (m *mockBackupHandler) HandleBackup(c *gin.Context) {
+	m.handleBackupCalled = true
+}
+
+type mockAuthHandler struct {
+	handleRegisterCalled  bool
+	handleLoginCalled     bool
+	handleLogoutCalled    bool
+	handleMeCalled        bool
+	handleProvidersCalled bool
+
+	handleOIDCLoginCalled    bool
+	handleOIDCCallbackCalled bool
+}
+
+func (m *mockAuthHandler) HandleRegister(c *gin.Context) {
+	m.handleRegisterCalled = true
+}
+
+func (m *mockAuthHandler) HandleLogin(c *gin.Context) {
+	m.handleLoginCalled = true
+}
+
+func (m *mockAuthHandler) HandleLogout(c *gin.Context) {
+	m.handleLogoutCalled = true
+}
+
+func (m *mockAuthHandler) HandleMe(c *gin.Context) {
+	m.handleMeCalled = true
+}
+
+func (m *mockAuthHandler) HandleProviders(c *gin.Context) {
+	m.handleProvidersCalled = true
+}
+
+func (m *mockAuthHandler) HandleOIDCLogin(c *gin.Context) {
+	m.handleOIDCLoginCalled = true
+}
+
+func (m *mockAuthHandler) HandleOIDCCallback(c *gin.Context) {
+	m.handleOIDCCallbackCalled = true
+}
+
+type mockHouseholdHandler struct {
+	handleCreateHouseholdCalled  bool
+	handleGetHouseholdsCalled    bool
+	handleGetHouseholdByIDCalled bool
+	handleRenameHouseholdCalled  bool
+	handleCreateInvitationCalled bool
+	handleAcceptInvitationCalled bool
+	handleSetMemberRoleCalled    bool
+	handleRemoveMemberCalled     bool
+}
+
+func (m *mockHouseholdHandler) HandleCreateHousehold(c *gin.Context) {
+	m.handleCreateHouseholdCalled = true
+}
+
+func (m *mockHouseholdHandler) HandleGetHouseholds(c *gin.Context) {
+	m.handleGetHouseholdsCalled = true
+}
+
+func (m *mockHouseholdHandler) HandleGetHouseholdByID(c *gin.Context) {
+	m.handleGetHouseholdByIDCalled = true
+}
+
+func (m *mockHouseholdHandler) HandleRenameHousehold(c *gin.Context) {
+	m.handleRenameHouseholdCalled = true
+}
+
+func (m *mockHouseholdHandler) HandleCreateInvitation(c *gin.Context) {
+	m.handleCreateInvitationCalled = true
+}
+
+func (m *mockHouseholdHandler) HandleAcceptInvitation(c *gin.Context) {
+	m.handleAcceptInvitationCalled = true
+}
+
+func (m *mockHouseholdHandler) HandleSetMemberRole(c *gin.Context) {
+	m.handleSetMemberRoleCalled = true
+}
+
+func (m *mockHouseholdHandler) HandleRemoveMember(c *gin.Context) {
+	m.handleRemoveMemberCalled = true
+}
+
+type mockAPIKeyHandler struct {
+	handleCreateAPIKeyCalled bool
+	handleGetAPIKeysCalled   bool
+	handleRevokeAPIKeyCalled bool
+}
+
+func (m *mockAPIKeyHandler) HandleCreateAPIKey(c *gin.Context) {
+	m.handleCreateAPIKeyCalled = true
+}
+
+func (m *mockAPIKeyHandler) HandleGetAPIKeys(c *gin.Context) {
+	m.handleGetAPIKeysCalled = true
+}
+
+func (m *mockAPIKeyHandler) HandleRevokeAPIKey(c *gin.Context) {
+	m.handleRevokeAPIKeyCalled = true
+}
+
+type mockLoanHandler struct {
+	handleLendBoardGameCalled     bool
+	handleGetBoardGameLoansCalled bool
+	handleReturnLoanCalled        bool
+	handleGetLoansCalled          bool
+}
+
+func (m *mockLoanHandler) HandleLendBoardGame(c *gin.Context) {
+	m.handleLendBoardGameCalled = true
+}
+
+func (m *mockLoanHandler) HandleGetBoardGameLoans(c *gin.Context) {
+	m.handleGetBoardGameLoansCalled = true
+}
+
+func (m *mockLoanHandler) HandleReturnLoan(c *gin.Context) {
+	m.handleReturnLoanCalled = true
+}
+
+func (m *mockLoanHandler) HandleGetLoans(c *gin.Context) {
+	m.handleGetLoansCalled = true
+}
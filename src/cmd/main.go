@@ -2,14 +2,20 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/eddiarnoldo/my-game-shelf/src/api"
 	"github.com/eddiarnoldo/my-game-shelf/src/config"
 	"github.com/eddiarnoldo/my-game-shelf/src/db"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/backup"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/bgg"
 	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/storage"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/joho/godotenv"
@@ -37,15 +43,224 @@ func run() error {
 
 	defer dbPool.Close()
 
+	blobStore, err := initializeBlobStore()
+	if err != nil {
+		return err
+	}
+
 	// Initialize repositories
-	boardGameRepo := repository.NewBoardGameRepository(dbPool)
-	imageRepo := repository.NewBoardGameImageRepository(dbPool)
+	boardGameRepo := repository.NewBoardGameRepository(dbPool, blobStore)
+	imageRepo := repository.NewBoardGameImageRepository(dbPool, blobStore)
+	playSessionRepo := repository.NewPlaySessionRepository(dbPool)
+	playerRepo := repository.NewPlayerRepository(dbPool, blobStore)
+	ratingRepo := repository.NewRatingRepository(dbPool)
+	tagRepo := repository.NewTagRepository(dbPool)
+	creditRepo := repository.NewCreditRepository(dbPool)
+	expansionRepo := repository.NewExpansionRepository(dbPool)
+	importRepo := repository.NewImportRepository(dbPool)
+	collectionRepo := repository.NewCollectionRepository(dbPool, blobStore)
+	backupRepo := repository.NewBackupRepository(dbPool)
+	userRepo := repository.NewUserRepository(dbPool)
+	householdRepo := repository.NewHouseholdRepository(dbPool)
+	apiKeyRepo := repository.NewAPIKeyRepository(dbPool)
+	loanRepo := repository.NewLoanRepository(dbPool)
+
+	// Optional subcommand, default is to serve the API
+	command := "serve"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	switch command {
+	case "serve":
+		// Ratings thrown away by a migration are rebuilt before serving
+		if replayed, err := ratingRepo.RecomputeIfMissing(context.Background()); err != nil {
+			return err
+		} else if replayed {
+			log.Println("Replayed every play session to rebuild the ratings")
+		}
+
+		// Init server
+		repos := api.Repositories{
+			BoardGames:   boardGameRepo,
+			Images:       imageRepo,
+			PlaySessions: playSessionRepo,
+			Players:      playerRepo,
+			Ratings:      ratingRepo,
+			Tags:         tagRepo,
+			Credits:      creditRepo,
+			Expansions:   expansionRepo,
+			Imports:      importRepo,
+			Collections:  collectionRepo,
+			Backups:      backupRepo,
+			Users:        userRepo,
+			Households:   householdRepo,
+			APIKeys:      apiKeyRepo,
+			Loans:        loanRepo,
+			Blobs:        blobStore,
+		}
+		if err := api.InitServer(repos); err != nil {
+			return err
+		}
+	case "migrate-images":
+		return migrateImages(imageRepo)
+	case "recompute-ratings":
+		log.Println("Replaying every play session...")
+		return ratingRepo.Recompute(context.Background())
+	case "import-bgg":
+		return importBGG(bgg.NewImporter(importRepo, imageRepo), userRepo, os.Args[2:])
+	case "backup":
+		return writeBackup(backup.NewArchiver(backupRepo, blobStore), os.Args[2:])
+	case "restore":
+		return restoreBackup(backup.NewArchiver(backupRepo, blobStore), os.Args[2:])
+	default:
+		return fmt.Errorf("unknown command %q (available: serve, migrate-images, recompute-ratings, import-bgg, backup, restore)", command)
+	}
+
+	return nil
+}
+
+// Where the image bytes are stored, STORAGE_DRIVER is "local" (default) or "s3"
+func initializeBlobStore() (storage.BlobStore, error) {
+	driver := config.GetEnv("STORAGE_DRIVER", "local")
+
+	switch driver {
+	case "local":
+		path := config.GetEnv("STORAGE_PATH", "./data/images")
+		log.Printf("Storing images in %s", path)
+		return storage.NewLocalStore(path)
+	case "s3":
+		store, err := storage.NewS3Store(storage.S3Config{
+			Endpoint:  config.GetEnv("S3_ENDPOINT", ""),
+			Region:    config.GetEnv("S3_REGION", "us-east-1"),
+			Bucket:    config.GetEnv("S3_BUCKET", "my-game-shelf"),
+			AccessKey: config.GetEnv("S3_ACCESS_KEY", ""),
+			SecretKey: config.GetEnv("S3_SECRET_KEY", ""),
+			PathStyle: config.GetEnv("S3_PATH_STYLE", "true") == "true",
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := store.EnsureBucket(context.Background()); err != nil {
+			return nil, err
+		}
+		log.Printf("Storing images in S3 bucket %s", config.GetEnv("S3_BUCKET", "my-game-shelf"))
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_DRIVER %q", driver)
+	}
+}
+
+// Moves images still stored as BYTEA into the configured blob store
+func migrateImages(imageRepo *repository.BoardGameImageRepository) error {
+	log.Println("Moving images from the database to the blob store...")
 
-	// Init server
-	if err := api.InitServer(boardGameRepo, imageRepo); err != nil {
+	migrated, err := imageRepo.MigrateLegacyImages(context.Background())
+	log.Printf("Moved %d images", migrated)
+	if err != nil {
+		return err
+	}
+
+	hashed, err := imageRepo.BackfillContentHashes(context.Background())
+	log.Printf("Hashed %d images", hashed)
+
+	return err
+}
+
+// Imports a BoardGameGeek export file onto a user's shelf and prints the
+// report as JSON.
+// Usage: import-bgg --user <email> [--dry-run] <file>
+func importBGG(importer *bgg.Importer, users repository.UserRepo, args []string) error {
+	flags := flag.NewFlagSet("import-bgg", flag.ContinueOnError)
+	email := flags.String("user", "", "email of the user whose shelf gets the games")
+	dryRun := flags.Bool("dry-run", false, "only report what would change")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || *email == "" {
+		return fmt.Errorf("usage: import-bgg --user <email> [--dry-run] <file>")
+	}
+
+	user, err := users.GetByEmail(context.Background(), *email)
+	if err != nil {
+		return fmt.Errorf("user %s: %w", *email, err)
+	}
+
+	settings, err := api.LoadImageSettings()
+	if err != nil {
+		return err
+	}
+	importer.SetImageVariantWidths(settings.VariantWidths)
+	importer.SetMaxImagePixels(settings.MaxPixels)
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	report, err := importer.Import(context.Background(), user.ID, file, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Created %d, updated %d, %d conflicts", len(report.Created), len(report.Updated), len(report.Conflicts))
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// Writes a backup archive of every table and image.
+// Usage: backup [file], the file defaults to my-game-shelf-backup-<date>.tar.gz
+func writeBackup(archiver *backup.Archiver, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("usage: backup [file]")
+	}
+	path := fmt.Sprintf("my-game-shelf-backup-%s.tar.gz", time.Now().Format("2006-01-02"))
+	if len(args) == 1 {
+		path = args[0]
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := archiver.Write(context.Background(), file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	log.Printf("Backed up %d tables and %d files to %s", len(manifest.Tables), len(manifest.Blobs), path)
+	return nil
+}
+
+// Restores a backup archive into this database, which must be empty and
+// migrated to the version the backup was made at.
+// Usage: restore <file>
+func restoreBackup(archiver *backup.Archiver, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: restore <file>")
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	manifest, err := archiver.Restore(context.Background(), file)
+	if err != nil {
 		return err
 	}
 
+	log.Printf("Restored %d tables and %d files from the backup of %s",
+		len(manifest.Tables), len(manifest.Blobs), manifest.CreatedAt.Format(time.RFC3339))
 	return nil
 }
 
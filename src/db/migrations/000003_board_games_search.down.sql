@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_board_games_name_trgm;
+DROP INDEX IF EXISTS idx_board_games_search;
+ALTER TABLE board_games DROP COLUMN IF EXISTS search_vector;
+DROP EXTENSION IF EXISTS pg_trgm;
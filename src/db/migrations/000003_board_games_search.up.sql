@@ -0,0 +1,12 @@
+-- Trigram matching for typo tolerant searches ("catn" still finds "Catan")
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Full text document, names weigh more than descriptions
+ALTER TABLE board_games ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX idx_board_games_search ON board_games USING GIN (search_vector);
+CREATE INDEX idx_board_games_name_trgm ON board_games USING GIN (name gin_trgm_ops);
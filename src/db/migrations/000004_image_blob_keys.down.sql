@@ -0,0 +1,6 @@
+-- Fails on rows that only exist in the blob store, their bytes can't be restored from SQL
+ALTER TABLE board_game_images DROP CONSTRAINT IF EXISTS check_image_source;
+ALTER TABLE board_game_images ALTER COLUMN image_data SET NOT NULL;
+ALTER TABLE board_game_images
+    DROP COLUMN IF EXISTS thumbnail_key,
+    DROP COLUMN IF EXISTS image_key;
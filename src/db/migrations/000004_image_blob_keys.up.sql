@@ -0,0 +1,9 @@
+-- Image bytes move to the blob store, rows only keep the keys.
+-- Existing rows keep their BYTEA data until `go run ./cmd migrate-images` moves it.
+ALTER TABLE board_game_images
+    ADD COLUMN image_key TEXT,
+    ADD COLUMN thumbnail_key TEXT,
+    ALTER COLUMN image_data DROP NOT NULL;
+
+ALTER TABLE board_game_images ADD CONSTRAINT check_image_source
+    CHECK (image_data IS NOT NULL OR image_key IS NOT NULL);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS board_game_image_variants;
@@ -0,0 +1,11 @@
+-- Resized copies of every image, one row per width and format
+CREATE TABLE board_game_image_variants (
+    image_id INTEGER NOT NULL REFERENCES board_game_images(id) ON DELETE CASCADE,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    mime_type VARCHAR(50) NOT NULL,
+    storage_key TEXT NOT NULL,
+    byte_size INTEGER NOT NULL,
+    PRIMARY KEY (image_id, width, mime_type),
+    CONSTRAINT check_variant_size CHECK (width > 0 AND height > 0)
+);
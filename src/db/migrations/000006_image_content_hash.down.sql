@@ -0,0 +1 @@
+ALTER TABLE board_game_images DROP COLUMN IF EXISTS content_hash;
@@ -0,0 +1,7 @@
+-- SHA-256 of the original image, used for ETags and versioned image URLs.
+-- Images already in the blob store are hashed by `go run ./cmd migrate-images`.
+ALTER TABLE board_game_images ADD COLUMN content_hash TEXT;
+
+UPDATE board_game_images
+SET content_hash = encode(sha256(image_data), 'hex')
+WHERE image_data IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS play_session_players;
+DROP TABLE IF EXISTS play_sessions;
@@ -0,0 +1,32 @@
+-- One row per time a game was played. The game name is copied so the history
+-- survives deleting the game, board_game_id becomes NULL instead of cascading.
+CREATE TABLE play_sessions (
+    id SERIAL PRIMARY KEY,
+    board_game_id INTEGER REFERENCES board_games(id) ON DELETE SET NULL,
+    board_game_name VARCHAR(255) NOT NULL,
+    played_at TIMESTAMP NOT NULL,
+    duration_minutes INTEGER,
+    location VARCHAR(255),
+    notes TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT check_duration CHECK (duration_minutes IS NULL OR duration_minutes > 0)
+);
+
+CREATE INDEX idx_play_sessions_board_game ON play_sessions(board_game_id, played_at DESC);
+CREATE INDEX idx_play_sessions_played_at ON play_sessions(played_at DESC);
+
+-- Everyone at the table, in seating order
+CREATE TABLE play_session_players (
+    id SERIAL PRIMARY KEY,
+    play_session_id INTEGER NOT NULL REFERENCES play_sessions(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    player_name VARCHAR(100) NOT NULL,
+    score INTEGER,
+    winner BOOLEAN NOT NULL DEFAULT FALSE,
+    first_player BOOLEAN NOT NULL DEFAULT FALSE,
+    CONSTRAINT unique_player_position UNIQUE (play_session_id, position)
+);
+
+-- At most one first player per session
+CREATE UNIQUE INDEX idx_one_first_player ON play_session_players(play_session_id) WHERE first_player;
@@ -0,0 +1,2 @@
+ALTER TABLE play_session_players DROP COLUMN IF EXISTS player_id;
+DROP TABLE IF EXISTS players;
@@ -0,0 +1,30 @@
+-- People who play, one row per person whatever the capitalisation of their name
+CREATE TABLE players (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    avatar_key TEXT,
+    avatar_mime_type VARCHAR(50),
+    avatar_hash TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_players_name ON players(LOWER(name));
+
+-- Participants point to a player. player_name stays as it was at the time,
+-- so deleting a player keeps the play history readable.
+ALTER TABLE play_session_players
+    ADD COLUMN player_id INTEGER REFERENCES players(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_play_session_players_player ON play_session_players(player_id);
+
+-- Everyone already logged becomes a player
+INSERT INTO players (name)
+SELECT DISTINCT ON (LOWER(player_name)) player_name
+FROM play_session_players
+ORDER BY LOWER(player_name), id;
+
+UPDATE play_session_players psp
+SET player_id = p.id
+FROM players p
+WHERE LOWER(p.name) = LOWER(psp.player_name);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS player_ratings;
+
+ALTER TABLE play_session_players
+    DROP COLUMN IF EXISTS team,
+    DROP COLUMN IF EXISTS finish_rank;
@@ -0,0 +1,19 @@
+-- Finishing positions, players with the same rank tied. Teammates share a team number.
+ALTER TABLE play_session_players
+    ADD COLUMN finish_rank INTEGER CHECK (finish_rank > 0),
+    ADD COLUMN team INTEGER CHECK (team > 0);
+
+-- Elo rating of a player for one game, or overall when board_game_id is NULL.
+-- Derived from the play history, it can be rebuilt at any time.
+CREATE TABLE player_ratings (
+    id SERIAL PRIMARY KEY,
+    player_id INTEGER NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+    board_game_id INTEGER REFERENCES board_games(id) ON DELETE CASCADE,
+    rating DOUBLE PRECISION NOT NULL,
+    games_played INTEGER NOT NULL,
+    wins INTEGER NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_player_ratings_scope ON player_ratings(player_id, COALESCE(board_game_id, 0));
+CREATE INDEX idx_player_ratings_board_game ON player_ratings(board_game_id, rating DESC);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS board_game_tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,19 @@
+-- Tags group games by category ("party"), mechanic ("deck-building") or theme ("space").
+-- Games link to the tag id, renaming a tag does not touch them.
+CREATE TABLE tags (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('category', 'mechanic', 'theme')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_tags_name ON tags(LOWER(name));
+
+CREATE TABLE board_game_tags (
+    board_game_id INTEGER NOT NULL REFERENCES board_games(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (board_game_id, tag_id)
+);
+
+CREATE INDEX idx_board_game_tags_tag ON board_game_tags(tag_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS board_game_publishers;
+DROP TABLE IF EXISTS board_game_people;
+DROP TABLE IF EXISTS publishers;
+DROP TABLE IF EXISTS people;
@@ -0,0 +1,40 @@
+-- Designers and artists, one row per person whatever their role
+CREATE TABLE people (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(200) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_people_name ON people(LOWER(name));
+
+CREATE TABLE publishers (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(200) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_publishers_name ON publishers(LOWER(name));
+
+-- The same person can be both designer and artist of a game
+CREATE TABLE board_game_people (
+    board_game_id INTEGER NOT NULL REFERENCES board_games(id) ON DELETE CASCADE,
+    person_id INTEGER NOT NULL REFERENCES people(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('designer', 'artist')),
+    position INTEGER NOT NULL,
+    PRIMARY KEY (board_game_id, person_id, role)
+);
+
+CREATE INDEX idx_board_game_people_person ON board_game_people(person_id, role);
+
+-- The year is the one of that publisher's edition
+CREATE TABLE board_game_publishers (
+    board_game_id INTEGER NOT NULL REFERENCES board_games(id) ON DELETE CASCADE,
+    publisher_id INTEGER NOT NULL REFERENCES publishers(id) ON DELETE CASCADE,
+    year_published INTEGER CHECK (year_published BETWEEN 1000 AND 9999),
+    position INTEGER NOT NULL,
+    PRIMARY KEY (board_game_id, publisher_id)
+);
+
+CREATE INDEX idx_board_game_publishers_publisher ON board_game_publishers(publisher_id);
@@ -0,0 +1,12 @@
+-- An expansion has one base game, which can itself be an expansion.
+-- A base game cannot be deleted while it still has expansions.
+CREATE TABLE board_game_expansions (
+    expansion_id INTEGER PRIMARY KEY REFERENCES board_games(id) ON DELETE CASCADE,
+    base_game_id INTEGER NOT NULL REFERENCES board_games(id),
+    relation VARCHAR(30) NOT NULL CHECK (relation IN ('expansion', 'standalone_expansion', 'promo')),
+    max_players INTEGER CHECK (max_players > 0),
+    play_time INTEGER CHECK (play_time > 0),
+    CHECK (expansion_id <> base_game_id)
+);
+
+CREATE INDEX idx_board_game_expansions_base ON board_game_expansions(base_game_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_board_games_bgg_id;
+ALTER TABLE board_games
+    DROP COLUMN IF EXISTS bgg_id,
+    DROP COLUMN IF EXISTS year_published;
@@ -0,0 +1,6 @@
+-- BoardGameGeek imports match games by their BGG id, so importing twice updates them
+ALTER TABLE board_games
+    ADD COLUMN year_published INTEGER,
+    ADD COLUMN bgg_id INTEGER;
+
+CREATE UNIQUE INDEX idx_board_games_bgg_id ON board_games(bgg_id);
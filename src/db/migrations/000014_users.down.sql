@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_board_games_bgg_id;
+DROP INDEX IF EXISTS idx_board_games_owner;
+ALTER TABLE board_games DROP COLUMN IF EXISTS owner_id;
+CREATE UNIQUE INDEX idx_board_games_bgg_id ON board_games(bgg_id);
+
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,34 @@
+-- Accounts and the sessions they sign in with. Only hashes are stored.
+CREATE TABLE users (
+    id SERIAL PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    display_name VARCHAR(100) NOT NULL,
+    password_hash TEXT NOT NULL,
+    is_admin BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_users_email ON users(LOWER(email));
+
+CREATE TABLE sessions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_sessions_token_hash ON sessions(token_hash);
+CREATE INDEX idx_sessions_user ON sessions(user_id);
+
+-- Every game belongs to one shelf. Games from before accounts existed have
+-- no owner until the first user registers and claims them.
+ALTER TABLE board_games
+    ADD COLUMN owner_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_board_games_owner ON board_games(owner_id);
+
+-- Two users can both own the same BGG game
+DROP INDEX IF EXISTS idx_board_games_bgg_id;
+CREATE UNIQUE INDEX idx_board_games_bgg_id ON board_games(owner_id, bgg_id);
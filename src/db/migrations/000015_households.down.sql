@@ -0,0 +1,20 @@
+ALTER TABLE board_games
+    ADD COLUMN owner_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+
+-- Games go back to the first owner of their household
+UPDATE board_games SET owner_id = (
+    SELECT user_id FROM household_members
+    WHERE household_members.household_id = board_games.household_id AND role = 'owner'
+    ORDER BY joined_at, user_id LIMIT 1
+);
+
+DROP INDEX IF EXISTS idx_board_games_bgg_id;
+DROP INDEX IF EXISTS idx_board_games_household;
+ALTER TABLE board_games DROP COLUMN IF EXISTS household_id;
+
+CREATE INDEX idx_board_games_owner ON board_games(owner_id);
+CREATE UNIQUE INDEX idx_board_games_bgg_id ON board_games(owner_id, bgg_id);
+
+DROP TABLE IF EXISTS household_invitations;
+DROP TABLE IF EXISTS household_members;
+DROP TABLE IF EXISTS households;
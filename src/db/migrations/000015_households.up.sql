@@ -0,0 +1,57 @@
+-- Households own the games, their members share them with a role each:
+-- owners manage the household, editors change its games, viewers only look.
+CREATE TABLE households (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE household_members (
+    household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(10) NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    joined_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (household_id, user_id)
+);
+
+CREATE INDEX idx_household_members_user ON household_members(user_id);
+
+-- Single use, only the hash of the token is stored
+CREATE TABLE household_invitations (
+    id SERIAL PRIMARY KEY,
+    household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    role VARCHAR(10) NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    token_hash TEXT NOT NULL,
+    invited_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    accepted_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_household_invitations_token_hash ON household_invitations(token_hash);
+
+ALTER TABLE board_games
+    ADD COLUMN household_id INTEGER REFERENCES households(id) ON DELETE CASCADE;
+
+-- Every user gets a household of their own, holding the games they owned
+DO $$
+DECLARE
+    account RECORD;
+    household INTEGER;
+BEGIN
+    FOR account IN SELECT id, display_name FROM users ORDER BY id LOOP
+        INSERT INTO households (name) VALUES (LEFT(account.display_name || '''s shelf', 100))
+            RETURNING id INTO household;
+        INSERT INTO household_members (household_id, user_id, role) VALUES (household, account.id, 'owner');
+        UPDATE board_games SET household_id = household WHERE owner_id = account.id;
+    END LOOP;
+END $$;
+
+DROP INDEX IF EXISTS idx_board_games_bgg_id;
+DROP INDEX IF EXISTS idx_board_games_owner;
+ALTER TABLE board_games DROP COLUMN owner_id;
+
+CREATE INDEX idx_board_games_household ON board_games(household_id);
+CREATE UNIQUE INDEX idx_board_games_bgg_id ON board_games(household_id, bgg_id);
@@ -0,0 +1,20 @@
+-- Games lent out. The borrower is a name, or a user whose name is copied in
+-- so the loan keeps it once they are gone.
+CREATE TABLE loans (
+    id SERIAL PRIMARY KEY,
+    board_game_id INTEGER NOT NULL REFERENCES board_games(id) ON DELETE CASCADE,
+    borrower_name VARCHAR(100) NOT NULL,
+    borrower_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    lent_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    due_at TIMESTAMP,
+    returned_at TIMESTAMP,
+    condition_note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT check_loan_dates CHECK (
+        (due_at IS NULL OR due_at >= lent_at) AND (returned_at IS NULL OR returned_at >= lent_at))
+);
+
+-- A game is lent to one borrower at a time
+CREATE UNIQUE INDEX idx_loans_active ON loans(board_game_id) WHERE returned_at IS NULL;
+CREATE INDEX idx_loans_board_game ON loans(board_game_id);
@@ -0,0 +1,16 @@
+-- Players with the same name are merged back into the oldest one
+UPDATE play_session_players psp
+SET player_id = keep.id
+FROM players p,
+    LATERAL (SELECT MIN(id) AS id FROM players WHERE LOWER(name) = LOWER(p.name)) keep
+WHERE p.id = psp.player_id AND keep.id <> p.id;
+
+DELETE FROM players p
+WHERE EXISTS (SELECT 1 FROM players o WHERE LOWER(o.name) = LOWER(p.name) AND o.id < p.id);
+
+DROP INDEX IF EXISTS idx_players_name;
+CREATE UNIQUE INDEX idx_players_name ON players(LOWER(name));
+ALTER TABLE players DROP COLUMN IF EXISTS household_id;
+
+DROP INDEX IF EXISTS idx_play_sessions_household;
+ALTER TABLE play_sessions DROP COLUMN IF EXISTS household_id;
@@ -0,0 +1,70 @@
+-- Players and plays belong to a household like the games: its members see
+-- them, its editors change them. A session keeps the household of its game
+-- once the game is deleted.
+ALTER TABLE play_sessions
+    ADD COLUMN household_id INTEGER REFERENCES households(id) ON DELETE CASCADE;
+
+UPDATE play_sessions s
+SET household_id = g.household_id
+FROM board_games g
+WHERE g.id = s.board_game_id;
+
+-- Plays of games deleted earlier go to the household where their players
+-- played the other games, when that is a single one
+UPDATE play_sessions s
+SET household_id = (
+    SELECT MIN(o.household_id)
+    FROM play_session_players psp
+    JOIN play_session_players op ON op.player_id = psp.player_id
+    JOIN play_sessions o ON o.id = op.play_session_id
+    WHERE psp.play_session_id = s.id AND o.household_id IS NOT NULL
+    HAVING COUNT(DISTINCT o.household_id) = 1
+)
+WHERE s.household_id IS NULL;
+
+-- The other ones could belong to anyone and keep a NULL household on purpose,
+-- no member query matches them so nobody sees them
+
+CREATE INDEX idx_play_sessions_household ON play_sessions(household_id, played_at DESC);
+
+ALTER TABLE players
+    ADD COLUMN household_id INTEGER REFERENCES households(id) ON DELETE CASCADE;
+
+-- Names are unique per household, before the copies below share them
+DROP INDEX idx_players_name;
+CREATE UNIQUE INDEX idx_players_name ON players(household_id, LOWER(name));
+
+-- A player stays in the first household they played in and is copied, without
+-- the avatar, into every other one, taking the plays logged there along
+DO $$
+DECLARE
+    link RECORD;
+    copied INTEGER;
+BEGIN
+    FOR link IN SELECT DISTINCT psp.player_id, s.household_id
+        FROM play_session_players psp
+        JOIN play_sessions s ON s.id = psp.play_session_id
+        WHERE psp.player_id IS NOT NULL AND s.household_id IS NOT NULL
+        ORDER BY psp.player_id, s.household_id
+    LOOP
+        UPDATE players SET household_id = link.household_id
+            WHERE id = link.player_id AND household_id IS NULL;
+        IF NOT FOUND THEN
+            INSERT INTO players (name, household_id, created_at, updated_at)
+                SELECT name, link.household_id, created_at, updated_at FROM players WHERE id = link.player_id
+                RETURNING id INTO copied;
+            UPDATE play_session_players psp SET player_id = copied
+                FROM play_sessions s
+                WHERE s.id = psp.play_session_id AND psp.player_id = link.player_id
+                    AND s.household_id = link.household_id;
+        END IF;
+    END LOOP;
+END $$;
+
+-- Players without a play in any household stay out of every roster like the
+-- plays above, a member adds them again by name
+
+-- Ratings of the copied players still count every household. They are derived
+-- from the plays, the server replays them when it starts, see
+-- RatingRepository.RecomputeIfMissing.
+DELETE FROM player_ratings;
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Cheap enough to keep the tests fast
+var testParams = Params{Memory: 1024, Time: 1, Threads: 1, SaltLen: 16, KeyLen: 32}
+
+func TestHashPassword_Verify(t *testing.T) {
+	// Arrange
+	hash, err := HashPasswordWith("correct horse battery staple", testParams)
+	if err != nil {
+		t.Fatalf("HashPassword() failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		want     bool
+	}{
+		{"same password", "correct horse battery staple", true},
+		{"other password", "correct horse battery stable", false},
+		{"empty password", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			ok, err := VerifyPassword(tt.password, hash)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("VerifyPassword() failed: %v", err)
+			}
+			if ok != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, ok)
+			}
+		})
+	}
+}
+
+func TestHashPassword_Format(t *testing.T) {
+	// Act
+	first, _ := HashPasswordWith("secret password", testParams)
+	second, _ := HashPasswordWith("secret password", testParams)
+
+	// Assert
+	if !strings.HasPrefix(first, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("expected a PHC argon2id string, got %q", first)
+	}
+	if first == second {
+		t.Error("expected a new salt for every hash")
+	}
+}
+
+func TestVerifyPassword_UsesStoredParams(t *testing.T) {
+	// Arrange
+	hash, _ := HashPasswordWith("secret password", Params{Memory: 2048, Time: 2, Threads: 2, SaltLen: 8, KeyLen: 16})
+
+	// Act
+	ok, err := VerifyPassword("secret password", hash)
+
+	// Assert
+	if err != nil || !ok {
+		t.Errorf("expected the hash to verify with its own params, got %v %v", ok, err)
+	}
+}
+
+func TestVerifyPassword_InvalidHash(t *testing.T) {
+	tests := []string{
+		"",
+		"plain text",
+		"$argon2i$v=19$m=1024,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=0,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$not base64!$a2V5",
+	}
+
+	for _, hash := range tests {
+		t.Run(hash, func(t *testing.T) {
+			// Act
+			_, err := VerifyPassword("secret password", hash)
+
+			// Assert
+			if !errors.Is(err, ErrInvalidHash) {
+				t.Errorf("expected ErrInvalidHash, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNewToken(t *testing.T) {
+	// Act
+	token, hash, err := NewToken()
+	other, _, _ := NewToken()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("NewToken() failed: %v", err)
+	}
+	if len(token) != 43 || token == other {
+		t.Errorf("expected a new 32 byte token, got %q", token)
+	}
+	if hash != HashToken(token) || hash == token {
+		t.Errorf("expected the stored hash to be HashToken(token), got %q", hash)
+	}
+}
+
+func TestNewAPIKey(t *testing.T) {
+	// Act
+	key, hash, hint, err := NewAPIKey()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("NewAPIKey() failed: %v", err)
+	}
+	if !IsAPIKey(key) || len(key) != len(APIKeyPrefix)+43 {
+		t.Errorf("expected a prefixed 32 byte key, got %q", key)
+	}
+	if hash != HashToken(key) {
+		t.Errorf("expected the stored hash to be HashToken(key), got %q", hash)
+	}
+	if !strings.HasPrefix(key, hint) || hint == key {
+		t.Errorf("expected the hint to be the start of the key, got %q", hint)
+	}
+}
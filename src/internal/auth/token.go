@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// 32 random bytes, as much entropy as the hash that is stored
+const tokenBytes = 32
+
+// NewToken returns a random token for the client and the hash to store
+func NewToken() (token string, hash string, err error) {
+	data := make([]byte, tokenBytes)
+	if _, err := rand.Read(data); err != nil {
+		return "", "", err
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(data)
+	return token, HashToken(token), nil
+}
+
+// HashToken is what a token is looked up by. Tokens are random, a plain
+// SHA-256 is enough, unlike passwords.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Every API key starts with it, to tell them apart from session tokens
+const APIKeyPrefix = "mgs_"
+
+// Characters of a key kept in clear to recognize it in a list
+const apiKeyHintLength = len(APIKeyPrefix) + 6
+
+// NewAPIKey returns a random API key for the client, the hash to store and
+// the start of the key to show in lists
+func NewAPIKey() (key string, hash string, hint string, err error) {
+	token, _, err := NewToken()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	key = APIKeyPrefix + token
+	return key, HashToken(key), key[:apiKeyHintLength], nil
+}
+
+// IsAPIKey tells API keys from session tokens
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
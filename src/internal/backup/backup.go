@@ -0,0 +1,321 @@
+// Package backup writes the whole shelf, every table and every image file,
+// to one tar.gz archive and restores such an archive into an empty database.
+//
+// The archive holds tables/<table>.jsonl, blobs/<blob key> and, last so the
+// archive can be streamed, manifest.json listing every file with its SHA-256.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/storage"
+)
+
+const (
+	FormatName    = "my-game-shelf-backup"
+	FormatVersion = 1 // Bumped when the archive layout changes
+
+	manifestFile = "manifest.json"
+	tablesDir    = "tables/"
+	blobsDir     = "blobs/"
+)
+
+var (
+	ErrInvalidArchive = errors.New("Not a my-game-shelf backup")
+	ErrHashMismatch   = errors.New("Backup file does not match its manifest")
+)
+
+type Manifest struct {
+	Format           string          `json:"format"`
+	Version          int             `json:"version"`
+	CreatedAt        time.Time       `json:"created_at"`
+	MigrationVersion int64           `json:"migration_version"` // Schema the tables were dumped from
+	Tables           []ManifestTable `json:"tables"`            // In restore order
+	Blobs            []ManifestBlob  `json:"blobs"`
+}
+
+type ManifestTable struct {
+	Name   string `json:"name"`
+	File   string `json:"file"`
+	Rows   int    `json:"rows"`
+	SHA256 string `json:"sha256"`
+}
+
+type ManifestBlob struct {
+	Key    string `json:"key"`
+	File   string `json:"file"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+type Archiver struct {
+	repo  repository.BackupRepo
+	store storage.BlobStore
+}
+
+func NewArchiver(repo repository.BackupRepo, store storage.BlobStore) *Archiver {
+	return &Archiver{repo: repo, store: store}
+}
+
+// Streams a backup to w. Nothing is written when the database cannot be
+// backed up, e.g. repository.ErrLegacyImages, so callers can still report it.
+func (a *Archiver) Write(ctx context.Context, w io.Writer) (*Manifest, error) {
+	manifest := &Manifest{
+		Format:    FormatName,
+		Version:   FormatVersion,
+		CreatedAt: time.Now().UTC(),
+		Tables:    []ManifestTable{},
+		Blobs:     []ManifestBlob{},
+	}
+
+	// Created lazily, gzip writes its header as soon as it gets a byte
+	var archive *tar.Writer
+	var compressed *gzip.Writer
+	writeFile := func(name string, data []byte) error {
+		if archive == nil {
+			compressed = gzip.NewWriter(w)
+			archive = tar.NewWriter(compressed)
+		}
+
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0o644,
+			Size:    int64(len(data)),
+			ModTime: manifest.CreatedAt,
+		}
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := archive.Write(data)
+		return err
+	}
+
+	dump, err := a.repo.Dump(ctx, func(table *models.TableDump) error {
+		file := tablesDir + table.Name + ".jsonl"
+		manifest.Tables = append(manifest.Tables, ManifestTable{
+			Name:   table.Name,
+			File:   file,
+			Rows:   table.Rows,
+			SHA256: hash(table.Data),
+		})
+		return writeFile(file, table.Data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	manifest.MigrationVersion = dump.MigrationVersion
+
+	for _, key := range dump.BlobKeys {
+		data, err := a.store.Get(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("blob %s: %w", key, err)
+		}
+
+		file := blobsDir + key
+		manifest.Blobs = append(manifest.Blobs, ManifestBlob{Key: key, File: file, Size: len(data), SHA256: hash(data)})
+		if err := writeFile(file, data); err != nil {
+			return nil, err
+		}
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFile(manifestFile, manifestJSON); err != nil {
+		return nil, err
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	if err := compressed.Close(); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// Restores a backup into an empty database at the migration version of the
+// backup. The archive is read twice: every file is checked against the
+// manifest before anything is written. Images go to the blob store first
+// and are deleted again when the tables cannot be restored.
+func (a *Archiver) Restore(ctx context.Context, archive io.ReadSeeker) (*Manifest, error) {
+	manifest, err := readManifest(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.repo.CheckRestorable(ctx, manifest.MigrationVersion); err != nil {
+		return nil, err
+	}
+
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	tables := map[string]*models.TableDump{}
+	blobs := map[string]ManifestBlob{}
+	for _, table := range manifest.Tables {
+		tables[table.File] = &models.TableDump{Name: table.Name, Rows: table.Rows}
+	}
+	for _, blob := range manifest.Blobs {
+		blobs[blob.File] = blob
+	}
+
+	var written []string
+	err = eachFile(archive, func(name string, data []byte) error {
+		if err := checkFile(manifest, name, data); err != nil {
+			return err
+		}
+
+		if table, ok := tables[name]; ok {
+			table.Data = data
+			return nil
+		}
+		if blob, ok := blobs[name]; ok {
+			if err := a.store.Put(ctx, blob.Key, data, http.DetectContentType(data)); err != nil {
+				return fmt.Errorf("blob %s: %w", blob.Key, err)
+			}
+			written = append(written, blob.Key)
+		}
+		return nil
+	})
+
+	if err == nil {
+		dumps := make([]*models.TableDump, 0, len(manifest.Tables))
+		for _, table := range manifest.Tables {
+			dumps = append(dumps, tables[table.File])
+		}
+		err = a.repo.Restore(ctx, manifest.MigrationVersion, dumps)
+	}
+
+	if err != nil {
+		for _, key := range written {
+			if deleteErr := a.store.Delete(ctx, key); deleteErr != nil {
+				log.Printf("Failed to delete restored blob %s: %v", key, deleteErr)
+			}
+		}
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// First pass: the manifest is at the end, then every file is checked against it
+func readManifest(archive io.Reader) (*Manifest, error) {
+	hashes := map[string]string{}
+	var manifestJSON []byte
+	err := eachFile(archive, func(name string, data []byte) error {
+		if name == manifestFile {
+			manifestJSON = data
+		} else {
+			hashes[name] = hash(data)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if manifestJSON == nil {
+		return nil, fmt.Errorf("%w: no %s", ErrInvalidArchive, manifestFile)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+	if manifest.Format != FormatName || manifest.Version != FormatVersion {
+		return nil, fmt.Errorf("%w: %s version %d", ErrInvalidArchive, manifest.Format, manifest.Version)
+	}
+
+	expected := map[string]string{}
+	for _, table := range manifest.Tables {
+		expected[table.File] = table.SHA256
+	}
+	for _, blob := range manifest.Blobs {
+		if blob.File != blobsDir+blob.Key {
+			return nil, fmt.Errorf("%w: blob %s stored as %s", ErrInvalidArchive, blob.Key, blob.File)
+		}
+		expected[blob.File] = blob.SHA256
+	}
+
+	for name, sum := range expected {
+		if hashes[name] != sum {
+			return nil, fmt.Errorf("%w: %s", ErrHashMismatch, name)
+		}
+	}
+	for name := range hashes {
+		if _, ok := expected[name]; !ok {
+			return nil, fmt.Errorf("%w: %s is not in the manifest", ErrInvalidArchive, name)
+		}
+	}
+
+	return &manifest, nil
+}
+
+// Second pass: the archive may have changed since the first one
+func checkFile(manifest *Manifest, name string, data []byte) error {
+	if name == manifestFile {
+		return nil
+	}
+	for _, table := range manifest.Tables {
+		if table.File == name && table.SHA256 != hash(data) {
+			return fmt.Errorf("%w: %s", ErrHashMismatch, name)
+		}
+	}
+	for _, blob := range manifest.Blobs {
+		if blob.File == name && blob.SHA256 != hash(data) {
+			return fmt.Errorf("%w: %s", ErrHashMismatch, name)
+		}
+	}
+	return nil
+}
+
+func eachFile(archive io.Reader, file func(name string, data []byte) error) error {
+	decompressed, err := gzip.NewReader(archive)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+	defer decompressed.Close()
+
+	reader := tar.NewReader(decompressed)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+		if err := file(header.Name, data); err != nil {
+			return err
+		}
+	}
+}
+
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
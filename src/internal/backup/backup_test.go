@@ -0,0 +1,224 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/storage"
+)
+
+func newTestStore(t *testing.T) storage.BlobStore {
+	t.Helper()
+
+	store, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	return store
+}
+
+// A shelf with one game and its cover
+func writeTestBackup(t *testing.T) []byte {
+	t.Helper()
+
+	store := newTestStore(t)
+	if err := store.Put(context.Background(), "boardgames/1/cover/original", []byte("cover bytes"), "image/png"); err != nil {
+		t.Fatalf("failed to put blob: %v", err)
+	}
+
+	repo := &mockBackupRepo{
+		tables: []*models.TableDump{
+			{Name: "board_games", Rows: 1, Data: []byte(`{"id":1,"name":"Catan"}` + "\n")},
+			{Name: "board_game_images", Rows: 1, Data: []byte(`{"id":1,"board_game_id":1}` + "\n")},
+		},
+		dump: &models.DatabaseDump{MigrationVersion: 13, BlobKeys: []string{"boardgames/1/cover/original"}},
+	}
+
+	var archive bytes.Buffer
+	if _, err := NewArchiver(repo, store).Write(context.Background(), &archive); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	return archive.Bytes()
+}
+
+func TestWriteRestore_RoundTrip(t *testing.T) {
+	// Arrange
+	archive := writeTestBackup(t)
+	store := newTestStore(t)
+	repo := &mockBackupRepo{}
+
+	// Act
+	manifest, err := NewArchiver(repo, store).Restore(context.Background(), bytes.NewReader(archive))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Restore() failed: %v", err)
+	}
+	if manifest.MigrationVersion != 13 || len(manifest.Tables) != 2 || len(manifest.Blobs) != 1 {
+		t.Errorf("unexpected manifest %+v", manifest)
+	}
+
+	if repo.restoredVersion != 13 || len(repo.restored) != 2 {
+		t.Fatalf("expected two tables restored at version 13, got %d at %d", len(repo.restored), repo.restoredVersion)
+	}
+	if repo.restored[0].Name != "board_games" || string(repo.restored[0].Data) != `{"id":1,"name":"Catan"}`+"\n" {
+		t.Errorf("expected board_games first with its rows, got %s %q", repo.restored[0].Name, repo.restored[0].Data)
+	}
+
+	data, err := store.Get(context.Background(), "boardgames/1/cover/original")
+	if err != nil || string(data) != "cover bytes" {
+		t.Errorf("expected the cover to be restored, got %q %v", data, err)
+	}
+}
+
+func TestRestore_HashMismatch(t *testing.T) {
+	// Arrange
+	archive := rewriteArchive(t, writeTestBackup(t), "blobs/boardgames/1/cover/original", []byte("tampered"))
+	store := newTestStore(t)
+	repo := &mockBackupRepo{}
+
+	// Act
+	_, err := NewArchiver(repo, store).Restore(context.Background(), bytes.NewReader(archive))
+
+	// Assert
+	if !errors.Is(err, ErrHashMismatch) {
+		t.Fatalf("expected ErrHashMismatch, got %v", err)
+	}
+	if repo.checked || repo.restored != nil {
+		t.Error("expected nothing to be restored")
+	}
+}
+
+func TestRestore_NotRestorable(t *testing.T) {
+	// Arrange
+	archive := writeTestBackup(t)
+	store := newTestStore(t)
+	repo := &mockBackupRepo{checkErr: repository.ErrDatabaseNotEmpty}
+
+	// Act
+	_, err := NewArchiver(repo, store).Restore(context.Background(), bytes.NewReader(archive))
+
+	// Assert
+	if !errors.Is(err, repository.ErrDatabaseNotEmpty) {
+		t.Fatalf("expected ErrDatabaseNotEmpty, got %v", err)
+	}
+	if _, err := store.Get(context.Background(), "boardgames/1/cover/original"); !errors.Is(err, storage.ErrBlobNotFound) {
+		t.Errorf("expected no blob to be written, got %v", err)
+	}
+}
+
+func TestRestore_TablesFailDeletesBlobs(t *testing.T) {
+	// Arrange
+	archive := writeTestBackup(t)
+	store := newTestStore(t)
+	repo := &mockBackupRepo{restoreErr: repository.ErrQueryFailed}
+
+	// Act
+	_, err := NewArchiver(repo, store).Restore(context.Background(), bytes.NewReader(archive))
+
+	// Assert
+	if !errors.Is(err, repository.ErrQueryFailed) {
+		t.Fatalf("expected ErrQueryFailed, got %v", err)
+	}
+	if _, err := store.Get(context.Background(), "boardgames/1/cover/original"); !errors.Is(err, storage.ErrBlobNotFound) {
+		t.Errorf("expected the restored blob to be deleted, got %v", err)
+	}
+}
+
+func TestWrite_NothingWrittenOnDumpError(t *testing.T) {
+	// Arrange
+	repo := &mockBackupRepo{dumpErr: repository.ErrLegacyImages}
+	var archive bytes.Buffer
+
+	// Act
+	_, err := NewArchiver(repo, newTestStore(t)).Write(context.Background(), &archive)
+
+	// Assert
+	if !errors.Is(err, repository.ErrLegacyImages) {
+		t.Fatalf("expected ErrLegacyImages, got %v", err)
+	}
+	if archive.Len() != 0 {
+		t.Errorf("expected nothing to be written, got %d bytes", archive.Len())
+	}
+}
+
+func TestRestore_InvalidArchive(t *testing.T) {
+	// Act
+	_, err := NewArchiver(&mockBackupRepo{}, newTestStore(t)).Restore(context.Background(), bytes.NewReader([]byte("not a tar.gz")))
+
+	// Assert
+	if !errors.Is(err, ErrInvalidArchive) {
+		t.Fatalf("expected ErrInvalidArchive, got %v", err)
+	}
+}
+
+// Copies the archive with the content of one file replaced
+func rewriteArchive(t *testing.T, archive []byte, name string, data []byte) []byte {
+	t.Helper()
+
+	var rewritten bytes.Buffer
+	compressed := gzip.NewWriter(&rewritten)
+	writer := tar.NewWriter(compressed)
+
+	err := eachFile(bytes.NewReader(archive), func(file string, content []byte) error {
+		if file == name {
+			content = data
+		}
+		if err := writer.WriteHeader(&tar.Header{Name: file, Mode: 0o644, Size: int64(len(content))}); err != nil {
+			return err
+		}
+		_, err := writer.Write(content)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to read archive: %v", err)
+	}
+
+	writer.Close()
+	compressed.Close()
+	return rewritten.Bytes()
+}
+
+type mockBackupRepo struct {
+	tables     []*models.TableDump
+	dump       *models.DatabaseDump
+	dumpErr    error
+	checkErr   error
+	restoreErr error
+
+	checked         bool
+	restored        []*models.TableDump
+	restoredVersion int64
+}
+
+func (m *mockBackupRepo) Dump(ctx context.Context, table func(dump *models.TableDump) error) (*models.DatabaseDump, error) {
+	if m.dumpErr != nil {
+		return nil, m.dumpErr
+	}
+	for _, dump := range m.tables {
+		if err := table(dump); err != nil {
+			return nil, err
+		}
+	}
+	return m.dump, nil
+}
+
+func (m *mockBackupRepo) CheckRestorable(ctx context.Context, migrationVersion int64) error {
+	m.checked = true
+	return m.checkErr
+}
+
+func (m *mockBackupRepo) Restore(ctx context.Context, migrationVersion int64, tables []*models.TableDump) error {
+	if m.restoreErr != nil {
+		return m.restoreErr
+	}
+	m.restored = tables
+	m.restoredVersion = migrationVersion
+	return nil
+}
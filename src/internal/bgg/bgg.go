@@ -0,0 +1,235 @@
+// Package bgg reads BoardGameGeek XML API2 exports, "collection" and "thing"
+// files, from disk. Nothing is ever fetched from the network.
+package bgg
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+)
+
+var ErrInvalidFile = errors.New("Not a BoardGameGeek collection or thing export")
+
+// Both exports list <item>s under <items>, with different shapes:
+// collection items have objectid, <name>text</name> and a <stats> element,
+// thing items have id, <name value=""/>, value attributes and <link>s.
+type xmlItems struct {
+	XMLName xml.Name  `xml:"items"`
+	Items   []xmlItem `xml:"item"`
+}
+
+type xmlItem struct {
+	ID            int64     `xml:"id,attr"`
+	ObjectID      int64     `xml:"objectid,attr"`
+	Type          string    `xml:"type,attr"`
+	Subtype       string    `xml:"subtype,attr"`
+	Names         []xmlName `xml:"name"`
+	Description   *string   `xml:"description"`
+	YearPublished xmlValue  `xml:"yearpublished"`
+	MinPlayers    xmlValue  `xml:"minplayers"`
+	MaxPlayers    xmlValue  `xml:"maxplayers"`
+	PlayingTime   xmlValue  `xml:"playingtime"`
+	MinPlayTime   xmlValue  `xml:"minplaytime"`
+	MaxPlayTime   xmlValue  `xml:"maxplaytime"`
+	MinAge        xmlValue  `xml:"minage"`
+	Image         string    `xml:"image"`
+	Links         []xmlLink `xml:"link"`
+	Stats         *xmlStats `xml:"stats"`
+}
+
+type xmlName struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:"value,attr"`
+	Text  string `xml:",chardata"`
+}
+
+// Thing files use value="", collection files the element text
+type xmlValue struct {
+	Value string `xml:"value,attr"`
+	Text  string `xml:",chardata"`
+}
+
+type xmlLink struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type xmlStats struct {
+	MinPlayers  string `xml:"minplayers,attr"`
+	MaxPlayers  string `xml:"maxplayers,attr"`
+	PlayingTime string `xml:"playingtime,attr"`
+	MinPlayTime string `xml:"minplaytime,attr"`
+	MaxPlayTime string `xml:"maxplaytime,attr"`
+}
+
+// Types of items imported, accessories and other things are reported as conflicts
+var boardGameTypes = map[string]bool{
+	"boardgame":          true,
+	"boardgameexpansion": true,
+}
+
+// BGG writes this instead of leaving a credit out
+const uncredited = "(Uncredited)"
+
+// Parse reads every item of the export. Items that are not board games come
+// back as conflicts, with their reason.
+func Parse(r io.Reader) ([]*models.ImportedBoardGame, []models.ImportEntry, error) {
+	var export xmlItems
+	if err := xml.NewDecoder(r).Decode(&export); err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+
+	var games []*models.ImportedBoardGame
+	var skipped []models.ImportEntry
+	for i, item := range export.Items {
+		game, err := item.toBoardGame()
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: item %d: %v", ErrInvalidFile, i+1, err)
+		}
+
+		itemType := item.Type
+		if itemType == "" {
+			itemType = item.Subtype
+		}
+		if !boardGameTypes[itemType] {
+			skipped = append(skipped, models.ImportEntry{
+				BGGID:  *game.BGGID,
+				Name:   game.Name,
+				Reason: fmt.Sprintf("%s items are not board games", itemType),
+			})
+			continue
+		}
+
+		games = append(games, game)
+	}
+
+	return games, skipped, nil
+}
+
+func (item xmlItem) toBoardGame() (*models.ImportedBoardGame, error) {
+	bggID := item.ID
+	if bggID == 0 {
+		bggID = item.ObjectID
+	}
+	if bggID == 0 {
+		return nil, errors.New("missing id")
+	}
+
+	game := &models.ImportedBoardGame{}
+	game.BGGID = &bggID
+	game.Name = item.name()
+	if year := item.YearPublished.int(); year != 0 {
+		game.YearPublished = &year
+	}
+
+	if item.Stats != nil {
+		// Collection export, only what the stats carry
+		game.Partial = true
+		game.MinPlayers = atoi(item.Stats.MinPlayers)
+		game.MaxPlayers = atoi(item.Stats.MaxPlayers)
+		game.PlayTime = firstPositive(atoi(item.Stats.PlayingTime), atoi(item.Stats.MaxPlayTime), atoi(item.Stats.MinPlayTime))
+	} else {
+		game.Partial = item.Description == nil
+		game.MinPlayers = item.MinPlayers.int()
+		game.MaxPlayers = item.MaxPlayers.int()
+		game.PlayTime = firstPositive(item.PlayingTime.int(), item.MaxPlayTime.int(), item.MinPlayTime.int())
+		game.MinAge = item.MinAge.int()
+		if item.Description != nil {
+			// Descriptions come HTML escaped inside the XML, e.g. &amp;mdash;
+			game.Description = strings.TrimSpace(html.UnescapeString(*item.Description))
+		}
+	}
+
+	for _, link := range item.Links {
+		value := strings.TrimSpace(link.Value)
+		if value == "" || value == uncredited {
+			continue
+		}
+		switch link.Type {
+		case "boardgamecategory":
+			game.Categories = append(game.Categories, value)
+		case "boardgamemechanic":
+			game.Mechanics = append(game.Mechanics, value)
+		case "boardgamedesigner":
+			game.Designers = append(game.Designers, value)
+		case "boardgameartist":
+			game.Artists = append(game.Artists, value)
+		case "boardgamepublisher":
+			game.Publishers = append(game.Publishers, value)
+		}
+	}
+
+	image := strings.TrimSpace(item.Image)
+	if strings.HasPrefix(image, "data:") {
+		cover, err := decodeDataURI(image)
+		if err != nil {
+			return nil, fmt.Errorf("bundled image: %v", err)
+		}
+		game.Cover = cover
+	} else {
+		game.CoverURL = image
+	}
+
+	return game, nil
+}
+
+// The primary name of a thing, or the collection's name
+func (item xmlItem) name() string {
+	for _, name := range item.Names {
+		if name.Type == "primary" && name.Value != "" {
+			return strings.TrimSpace(name.Value)
+		}
+	}
+	for _, name := range item.Names {
+		if value := strings.TrimSpace(name.Value + name.Text); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func (v xmlValue) int() int {
+	if v.Value != "" {
+		return atoi(v.Value)
+	}
+	return atoi(v.Text)
+}
+
+// Missing and malformed numbers read as 0, the import reports what is missing
+func atoi(value string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+func firstPositive(values ...int) int {
+	for _, value := range values {
+		if value > 0 {
+			return value
+		}
+	}
+	return 0
+}
+
+// Only base64 data URIs, e.g. data:image/png;base64,iVBOR...
+func decodeDataURI(uri string) ([]byte, error) {
+	header, data, found := strings.Cut(strings.TrimPrefix(uri, "data:"), ",")
+	if !found || !strings.HasSuffix(header, ";base64") {
+		return nil, errors.New("only base64 data URIs are supported")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(data), ""))
+	if err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
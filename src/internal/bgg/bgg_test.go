@@ -0,0 +1,122 @@
+package bgg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func openTestFile(t *testing.T, name string) *os.File {
+	t.Helper()
+
+	file, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", name, err)
+	}
+	t.Cleanup(func() { file.Close() })
+	return file
+}
+
+func TestParse_Thing(t *testing.T) {
+	// Act
+	games, skipped, err := Parse(openTestFile(t, "thing.xml"))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if len(games) != 1 {
+		t.Fatalf("expected one game, got %d", len(games))
+	}
+
+	game := games[0]
+	if *game.BGGID != 31260 || game.Name != "Agricola" || game.Partial {
+		t.Errorf("unexpected game %+v", game)
+	}
+	if game.MinPlayers != 1 || game.MaxPlayers != 5 || game.PlayTime != 150 || game.MinAge != 12 || *game.YearPublished != 2007 {
+		t.Errorf("unexpected numbers %+v", game.BoardGame)
+	}
+	if game.Description != "Work the land — and feed your family.\nRated \"best\"." {
+		t.Errorf("expected the description to be unescaped, got %q", game.Description)
+	}
+	if !reflect.DeepEqual(game.Categories, []string{"Animals", "Farming"}) || !reflect.DeepEqual(game.Mechanics, []string{"Worker Placement"}) {
+		t.Errorf("unexpected tags %v %v", game.Categories, game.Mechanics)
+	}
+	if !reflect.DeepEqual(game.Designers, []string{"Uwe Rosenberg"}) || !reflect.DeepEqual(game.Publishers, []string{"Lookout Games"}) {
+		t.Errorf("unexpected credits %v %v", game.Designers, game.Publishers)
+	}
+	if game.Cover != nil || game.CoverURL != "https://cf.geekdo-images.com/agricola.jpg" {
+		t.Errorf("expected the cover to only be linked, got %q", game.CoverURL)
+	}
+
+	if len(skipped) != 1 || skipped[0].BGGID != 99999 {
+		t.Errorf("expected the accessory to be skipped, got %+v", skipped)
+	}
+}
+
+func TestParse_Collection(t *testing.T) {
+	// Act
+	games, skipped, err := Parse(openTestFile(t, "collection.xml"))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if len(games) != 2 || len(skipped) != 0 {
+		t.Fatalf("expected two games, got %d and %d skipped", len(games), len(skipped))
+	}
+
+	catan := games[0]
+	if *catan.BGGID != 13 || catan.Name != "CATAN" || !catan.Partial {
+		t.Errorf("unexpected game %+v", catan)
+	}
+	if catan.MinPlayers != 3 || catan.MaxPlayers != 4 || catan.PlayTime != 120 || *catan.YearPublished != 1995 {
+		t.Errorf("unexpected numbers %+v", catan.BoardGame)
+	}
+}
+
+func TestParse_BundledCover(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	xml := `<items><item type="boardgame" id="1"><name type="primary" value="Tiny"/>
+		<image>data:image/png;base64,` + base64.StdEncoding.EncodeToString(buf.Bytes()) + `</image></item></items>`
+
+	// Act
+	games, _, err := Parse(strings.NewReader(xml))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if !bytes.Equal(games[0].Cover, buf.Bytes()) || games[0].CoverURL != "" {
+		t.Errorf("expected the bundled PNG as cover, got %d bytes", len(games[0].Cover))
+	}
+}
+
+func TestParse_InvalidFile(t *testing.T) {
+	tests := []struct {
+		name string
+		xml  string
+	}{
+		{"not xml", "name,players\nCatan,4"},
+		{"other root", `<message>Your request for this collection has been accepted</message>`},
+		{"item without id", `<items><item type="boardgame"><name value="Nameless"/></item></items>`},
+		{"broken data uri", `<items><item type="boardgame" id="1"><image>data:image/png,raw</image></item></items>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Parse(strings.NewReader(tt.xml))
+			if !errors.Is(err, ErrInvalidFile) {
+				t.Errorf("expected ErrInvalidFile, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package bgg
+
+import (
+	"context"
+	"io"
+	"log"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/helpers"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/repository"
+)
+
+// Imports export files into the collection, covers go through the same
+// checks and resizing as uploads
+type Importer struct {
+	games          repository.ImportRepo
+	images         repository.BoardGameImageRepo
+	variantWidths  []int
+	maxImagePixels int
+}
+
+func NewImporter(games repository.ImportRepo, images repository.BoardGameImageRepo) *Importer {
+	return &Importer{
+		games:          games,
+		images:         images,
+		variantWidths:  helpers.DefaultVariantWidths,
+		maxImagePixels: helpers.DefaultMaxImagePixels,
+	}
+}
+
+func (i *Importer) SetImageVariantWidths(widths []int) {
+	i.variantWidths = widths
+}
+
+func (i *Importer) SetMaxImagePixels(pixels int) {
+	i.maxImagePixels = pixels
+}
+
+// Import reads the export and creates or updates its games on the user's
+// shelf. A dry run only reports what would change.
+func (i *Importer) Import(ctx context.Context, userID int64, r io.Reader, dryRun bool) (*models.ImportReport, error) {
+	games, skipped, err := Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := i.games.ImportBoardGames(ctx, userID, games, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	report.Conflicts = append(report.Conflicts, skipped...)
+
+	if dryRun {
+		return report, nil
+	}
+
+	covers := make(map[int64][]byte, len(games))
+	for _, game := range games {
+		covers[*game.BGGID] = game.Cover
+	}
+	// The games are saved by now, a cover that fails is reported and the import goes on
+	for _, entries := range [][]models.ImportEntry{report.Created, report.Updated} {
+		for n := range entries {
+			entry := &entries[n]
+			if entry.Cover != models.CoverAttached {
+				continue
+			}
+			if err := i.attachCover(ctx, userID, entry.BoardGameID, covers[entry.BGGID]); err != nil {
+				log.Printf("Import: cover of board game %d: %v", entry.BoardGameID, err)
+				entry.Cover = models.CoverUnreadable
+			}
+		}
+	}
+
+	return report, nil
+}
+
+func (i *Importer) attachCover(ctx context.Context, userID int64, boardGameID int64, data []byte) error {
+	imageData, mimeType, err := helpers.SanitizeImage(data, i.maxImagePixels)
+	if err != nil {
+		return err
+	}
+
+	thumbnailData, err := helpers.GenerateThumbnail(imageData, mimeType)
+	if err != nil {
+		return err
+	}
+
+	variants, err := helpers.GenerateVariants(imageData, mimeType, i.variantWidths)
+	if err != nil {
+		return err
+	}
+
+	image := &models.BoardGameImage{
+		BoardGameID:   boardGameID,
+		ImageData:     imageData,
+		ImageMimeType: mimeType,
+		ThumbnailData: thumbnailData,
+		ImageType:     "cover",
+		Variants:      variants,
+	}
+	// Never replaces a cover uploaded since the import checked
+	_, err = i.images.SaveImage(ctx, userID, image, repository.CoverModeReject)
+	return err
+}
@@ -0,0 +1,376 @@
+// Package collection writes the whole shelf as CSV or JSON and reads those
+// files back, so a collection can be moved or edited in a spreadsheet.
+package collection
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+var (
+	ErrUnknownFormat = errors.New("Invalid format: must be csv or json")
+	ErrInvalidFile   = errors.New("Not a collection export")
+)
+
+// CSV columns, in export order. Tags are "kind:name" and images "type:id", separated by listSeparator.
+var csvHeader = []string{
+	"id", "name", "min_players", "max_players", "play_time", "min_age", "description",
+	"year_published", "bgg_id", "tags", "images", "created_at", "updated_at",
+}
+
+const listSeparator = "|"
+
+func ContentType(format string) string {
+	if format == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/json; charset=utf-8"
+}
+
+func Write(w io.Writer, format string, games []*models.CollectionGame) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, games)
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(games)
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+func writeCSV(w io.Writer, games []*models.CollectionGame) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, game := range games {
+		tags := make([]string, len(game.Tags))
+		for i, tag := range game.Tags {
+			tags[i] = tag.Kind + ":" + tag.Name
+		}
+		images := make([]string, len(game.Images))
+		for i, image := range game.Images {
+			images[i] = image.Type + ":" + strconv.FormatInt(image.ID, 10)
+		}
+
+		record := []string{
+			strconv.FormatInt(game.ID, 10),
+			game.Name,
+			strconv.Itoa(game.MinPlayers),
+			formatOptional(game.MaxPlayers),
+			strconv.Itoa(game.PlayTime),
+			strconv.Itoa(game.MinAge),
+			game.Description,
+			"",
+			"",
+			strings.Join(tags, listSeparator),
+			strings.Join(images, listSeparator),
+			game.CreatedAt.Format(time.RFC3339),
+			game.UpdatedAt.Format(time.RFC3339),
+		}
+		if game.YearPublished != nil {
+			record[7] = strconv.Itoa(*game.YearPublished)
+		}
+		if game.BGGID != nil {
+			record[8] = strconv.FormatInt(*game.BGGID, 10)
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Zero means unknown, written as an empty cell
+func formatOptional(value int) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.Itoa(value)
+}
+
+// Reads a file written by Write. Rows that cannot be imported, because a cell
+// is not a number or the game would not pass HandleBoardGameCreate, come back
+// as row errors; a file that is not an export at all is ErrInvalidFile.
+func Parse(r io.Reader, format string) ([]*models.CollectionGame, []models.CollectionRowError, error) {
+	var games []*models.CollectionGame
+	var rowErrors []models.CollectionRowError
+	var err error
+
+	switch format {
+	case FormatCSV:
+		games, rowErrors, err = parseCSV(r)
+	case FormatJSON:
+		games, rowErrors, err = parseJSON(r)
+	default:
+		return nil, nil, ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	valid := []*models.CollectionGame{}
+	for _, game := range games {
+		if message := validate(game); message != "" {
+			rowErrors = append(rowErrors, models.CollectionRowError{Line: game.Line, Name: game.Name, Error: message})
+			continue
+		}
+		valid = append(valid, game)
+	}
+
+	if rowErrors == nil {
+		rowErrors = []models.CollectionRowError{}
+	}
+	sort.SliceStable(rowErrors, func(i, j int) bool { return rowErrors[i].Line < rowErrors[j].Line })
+	return valid, rowErrors, nil
+}
+
+// Same binding rules as creating a game by hand, plus the checks the database
+// would otherwise fail the whole import on
+func validate(game *models.CollectionGame) string {
+	if err := binding.Validator.ValidateStruct(game.BoardGame()); err != nil {
+		return err.Error()
+	}
+
+	switch {
+	case game.MinPlayers < 1:
+		return "min_players must be at least 1"
+	case game.MaxPlayers != 0 && game.MaxPlayers < game.MinPlayers:
+		return "max_players must not be below min_players"
+	case game.PlayTime < 1:
+		return "play_time must be at least 1"
+	}
+
+	for _, tag := range game.Tags {
+		if err := binding.Validator.ValidateStruct(&models.Tag{Name: tag.Name, Kind: tag.Kind}); err != nil {
+			return fmt.Sprintf("tag %q: %s", tag.Name, err)
+		}
+	}
+
+	return ""
+}
+
+func parseCSV(r io.Reader) ([]*models.CollectionGame, []models.CollectionRowError, error) {
+	reader := csv.NewReader(r)
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, nil, fmt.Errorf("%w: no name column", ErrInvalidFile)
+	}
+
+	games := []*models.CollectionGame{}
+	rowErrors := []models.CollectionRowError{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		// The record still comes back with a wrong field count, not with other errors
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		if err != nil {
+			rowErrors = append(rowErrors, models.CollectionRowError{Line: line, Error: "wrong number of columns"})
+			continue
+		}
+
+		game, err := parseCSVRecord(record, columns)
+		if err != nil {
+			rowErrors = append(rowErrors, models.CollectionRowError{Line: line, Name: game.Name, Error: err.Error()})
+			continue
+		}
+		game.Line = line
+		games = append(games, game)
+	}
+
+	return games, rowErrors, nil
+}
+
+// Always returns the game so errors can name it
+func parseCSVRecord(record []string, columns map[string]int) (*models.CollectionGame, error) {
+	cell := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	game := &models.CollectionGame{
+		Name:        cell("name"),
+		Description: cell("description"),
+		Tags:        []models.TagRef{},
+		Images:      []models.BoardGameImageRef{},
+	}
+
+	numbers := []struct {
+		column string
+		dest   *int
+	}{
+		{"min_players", &game.MinPlayers},
+		{"max_players", &game.MaxPlayers},
+		{"play_time", &game.PlayTime},
+		{"min_age", &game.MinAge},
+	}
+	for _, number := range numbers {
+		if value := cell(number.column); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return game, fmt.Errorf("%s: %q is not a number", number.column, value)
+			}
+			*number.dest = parsed
+		}
+	}
+
+	if value := cell("id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return game, fmt.Errorf("id: %q is not a number", value)
+		}
+		game.ID = id
+	}
+	if value := cell("year_published"); value != "" {
+		year, err := strconv.Atoi(value)
+		if err != nil {
+			return game, fmt.Errorf("year_published: %q is not a number", value)
+		}
+		game.YearPublished = &year
+	}
+	if value := cell("bgg_id"); value != "" {
+		bggID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return game, fmt.Errorf("bgg_id: %q is not a number", value)
+		}
+		game.BGGID = &bggID
+	}
+
+	for _, tag := range splitList(cell("tags")) {
+		kind, name, ok := strings.Cut(tag, ":")
+		if !ok {
+			return game, fmt.Errorf("tags: %q is not kind:name", tag)
+		}
+		game.Tags = append(game.Tags, models.TagRef{Name: strings.TrimSpace(name), Kind: strings.TrimSpace(kind)})
+	}
+	for _, image := range splitList(cell("images")) {
+		imageType, value, ok := strings.Cut(image, ":")
+		id, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if !ok || err != nil {
+			return game, fmt.Errorf("images: %q is not type:id", image)
+		}
+		game.Images = append(game.Images, models.BoardGameImageRef{
+			ID:   id,
+			URL:  models.ImageURL(id, ""),
+			Type: strings.TrimSpace(imageType),
+		})
+	}
+
+	times := []struct {
+		column string
+		dest   *time.Time
+	}{
+		{"created_at", &game.CreatedAt},
+		{"updated_at", &game.UpdatedAt},
+	}
+	for _, timestamp := range times {
+		if value := cell(timestamp.column); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return game, fmt.Errorf("%s: %q is not an RFC 3339 time", timestamp.column, value)
+			}
+			*timestamp.dest = parsed
+		}
+	}
+
+	return game, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, listSeparator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Games are decoded one by one so a bad value only costs its own row
+func parseJSON(r io.Reader) ([]*models.CollectionGame, []models.CollectionRowError, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, nil, fmt.Errorf("%w: expected an array of games", ErrInvalidFile)
+	}
+
+	games := []*models.CollectionGame{}
+	rowErrors := []models.CollectionRowError{}
+	for decoder.More() {
+		line := lineAt(data, decoder.InputOffset())
+
+		var game models.CollectionGame
+		err := decoder.Decode(&game)
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			rowErrors = append(rowErrors, models.CollectionRowError{
+				Line:  line,
+				Name:  game.Name,
+				Error: fmt.Sprintf("%s: expected %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value),
+			})
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+		}
+
+		game.Line = line
+		games = append(games, &game)
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+
+	return games, rowErrors, nil
+}
+
+// Line of the first value at or after offset, the decoder stops right after the previous one
+func lineAt(data []byte, offset int64) int {
+	for offset < int64(len(data)) && strings.ContainsRune(" \t\r\n,", rune(data[offset])) {
+		offset++
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
@@ -0,0 +1,166 @@
+package collection
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+)
+
+func testCollection() []*models.CollectionGame {
+	year := 2007
+	bggID := int64(31260)
+	createdAt := time.Date(2024, 3, 1, 18, 30, 0, 0, time.UTC)
+
+	return []*models.CollectionGame{
+		{
+			ID:            1,
+			Name:          "Agricola",
+			MinPlayers:    1,
+			MaxPlayers:    5,
+			PlayTime:      150,
+			MinAge:        12,
+			Description:   "Work the land,\nfeed your \"family\".",
+			YearPublished: &year,
+			BGGID:         &bggID,
+			Tags:          []models.TagRef{{Name: "Farming", Kind: "category"}, {Name: "Worker Placement", Kind: "mechanic"}},
+			Images:        []models.BoardGameImageRef{{ID: 4, URL: models.ImageURL(4, ""), Type: "cover"}},
+			CreatedAt:     createdAt,
+			UpdatedAt:     createdAt,
+		},
+		{
+			ID:          2,
+			Name:        "Skull",
+			MinPlayers:  3,
+			PlayTime:    30,
+			MinAge:      10,
+			Description: "Bluff",
+			Tags:        []models.TagRef{},
+			Images:      []models.BoardGameImageRef{},
+			CreatedAt:   createdAt,
+			UpdatedAt:   createdAt,
+		},
+	}
+}
+
+func TestWriteParse_RoundTrip(t *testing.T) {
+	for _, format := range []string{FormatCSV, FormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			// Arrange
+			var file bytes.Buffer
+			if err := Write(&file, format, testCollection()); err != nil {
+				t.Fatalf("Write() failed: %v", err)
+			}
+
+			// Act
+			games, rowErrors, err := Parse(&file, format)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Parse() failed: %v", err)
+			}
+			if len(rowErrors) != 0 {
+				t.Fatalf("expected no row errors, got %+v", rowErrors)
+			}
+
+			want := testCollection()
+			if len(games) != len(want) {
+				t.Fatalf("expected %d games, got %d", len(want), len(games))
+			}
+			for i := range want {
+				games[i].Line = 0
+				if !reflect.DeepEqual(games[i], want[i]) {
+					t.Errorf("game %d: expected %+v, got %+v", i, want[i], games[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParse_CSVRowErrors(t *testing.T) {
+	// Arrange
+	file := "name,min_players,play_time,min_age,description,tags\n" +
+		"Catan,3,90,10,\"Trade\nand build\",category:Strategy\n" +
+		"Skull,three,30,10,Bluff,\n" +
+		"Hanabi,2,25,8,Cooperate,flavour:Cards\n" +
+		"Too,few\n" +
+		",2,30,8,No name,\n"
+
+	// Act
+	games, rowErrors, err := Parse(strings.NewReader(file), FormatCSV)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if len(games) != 1 || games[0].Name != "Catan" || games[0].Line != 2 {
+		t.Fatalf("expected only Catan from line 2, got %+v", games)
+	}
+
+	lines := []int{}
+	for _, rowErr := range rowErrors {
+		lines = append(lines, rowErr.Line)
+	}
+	if !reflect.DeepEqual(lines, []int{4, 5, 6, 7}) {
+		t.Errorf("expected errors on lines 4 to 7, got %+v", rowErrors)
+	}
+	if !strings.Contains(rowErrors[0].Error, "min_players") || rowErrors[0].Name != "Skull" {
+		t.Errorf("expected Skull's min_players to be reported, got %+v", rowErrors[0])
+	}
+}
+
+func TestParse_JSONRowErrors(t *testing.T) {
+	// Arrange
+	file := `[
+  {"name": "Catan", "min_players": 3, "play_time": 90, "min_age": 10, "description": "Trade"},
+  {
+    "name": "Skull",
+    "min_players": "three", "play_time": 30, "min_age": 10, "description": "Bluff"
+  },
+  {"name": "Hanabi", "min_players": 4, "max_players": 2, "play_time": 25, "min_age": 8, "description": "Cooperate"}
+]`
+
+	// Act
+	games, rowErrors, err := Parse(strings.NewReader(file), FormatJSON)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if len(games) != 1 || games[0].Line != 2 {
+		t.Fatalf("expected only Catan from line 2, got %+v", games)
+	}
+	if len(rowErrors) != 2 || rowErrors[0].Line != 3 || rowErrors[1].Line != 7 {
+		t.Errorf("expected errors on lines 3 and 7, got %+v", rowErrors)
+	}
+}
+
+func TestParse_InvalidFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		file   string
+		want   error
+	}{
+		{"csv without name column", FormatCSV, "title,players\nCatan,4\n", ErrInvalidFile},
+		{"json object", FormatJSON, `{"name": "Catan"}`, ErrInvalidFile},
+		{"truncated json", FormatJSON, `[{"name": "Catan"`, ErrInvalidFile},
+		{"unknown format", "xml", "<items/>", ErrUnknownFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, _, err := Parse(strings.NewReader(tt.file), tt.format)
+
+			// Assert
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package helpers
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"image/jpeg"
 	"image/png"
 
@@ -22,13 +24,10 @@ func GenerateThumbnail(imageData []byte, mimeType string) ([]byte, error) {
 	// Encode back to bytes
 	var buf bytes.Buffer
 
-	switch mimeType {
-	case "image/jpeg", "image/jpg":
-		err = jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: 85})
+	switch ThumbnailMimeType(mimeType) {
 	case "image/png":
 		err = png.Encode(&buf, thumbnail)
 	default:
-		// Default to JPEG for other formats
 		err = jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: 85})
 	}
 
@@ -38,3 +37,18 @@ func GenerateThumbnail(imageData []byte, mimeType string) ([]byte, error) {
 
 	return buf.Bytes(), nil
 }
+
+// ThumbnailMimeType is the format GenerateThumbnail encodes to.
+// PNG stays PNG (keeps transparency), everything else becomes JPEG.
+func ThumbnailMimeType(mimeType string) string {
+	if mimeType == "image/png" {
+		return "image/png"
+	}
+	return "image/jpeg"
+}
+
+// Hex SHA-256 of the image bytes, used as ETag and URL version
+func ContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,247 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	_ "image/gif" // Registers GIF for DecodeConfig
+	"image/jpeg"
+
+	"github.com/disintegration/imaging"
+	"github.com/gabriel-vasile/mimetype"
+)
+
+var (
+	ErrUnsupportedImage = errors.New("Unsupported image type")
+	ErrImageTooLarge    = errors.New("Image dimensions exceed the pixel limit")
+)
+
+// Uploads above this many pixels are rejected when IMAGE_MAX_PIXELS is not set.
+// Decoding one takes about 4 bytes per pixel, 40 megapixels is ~160MB.
+const DefaultMaxImagePixels = 40_000_000
+
+// Types we can decode and resize
+var supportedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// SanitizeImage checks an upload before anything decodes it and returns the
+// bytes to store along with their real MIME type:
+//   - the type comes from the magic bytes, never from the client
+//   - the dimensions are read from the header and checked against maxPixels
+//   - EXIF (GPS, camera...), XMP and text metadata are dropped
+//   - JPEGs with an EXIF orientation are rotated, since the tag is dropped too
+func SanitizeImage(data []byte, maxPixels int) ([]byte, string, error) {
+	mimeType := mimetype.Detect(data).String()
+	if !supportedImageTypes[mimeType] {
+		return nil, "", ErrUnsupportedImage
+	}
+
+	// Only reads the header, a decompression bomb is caught before it expands
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrUnsupportedImage
+	}
+	if int64(config.Width)*int64(config.Height) > int64(maxPixels) {
+		return nil, "", ErrImageTooLarge
+	}
+
+	var clean []byte
+	switch mimeType {
+	case "image/jpeg":
+		clean, err = sanitizeJPEG(data)
+	case "image/png":
+		clean, err = stripPNGMetadata(data)
+	case "image/webp":
+		clean, err = stripWebPMetadata(data)
+	default:
+		clean = data
+	}
+	if err != nil {
+		return nil, "", ErrUnsupportedImage
+	}
+
+	return clean, mimeType, nil
+}
+
+var errMalformedImage = errors.New("malformed image")
+
+func sanitizeJPEG(data []byte) ([]byte, error) {
+	stripped, orientation, err := stripJPEGMetadata(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if orientation <= 1 || orientation > 8 {
+		// Lossless, only the metadata segments are gone
+		return stripped, nil
+	}
+
+	// Rotating means re-encoding, the new file has no metadata at all
+	img, err := imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 92}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Drops APP1 (EXIF, XMP), APP13 (IPTC) and comments, keeps everything else
+// including the ICC profile. Also returns the EXIF orientation, 0 when missing.
+func stripJPEGMetadata(data []byte) ([]byte, int, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, 0, errMalformedImage
+	}
+
+	out := []byte{0xFF, 0xD8}
+	orientation := 0
+	pos := 2
+
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return nil, 0, errMalformedImage
+		}
+		marker := data[pos+1]
+		if marker == 0xFF { // Fill byte
+			pos++
+			continue
+		}
+
+		// Start of scan, the rest is image data
+		if marker == 0xDA {
+			return append(out, data[pos:]...), orientation, nil
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, 0, errMalformedImage
+		}
+		segment := data[pos:end]
+
+		switch marker {
+		case 0xE1:
+			if o := exifOrientation(segment[4:]); o != 0 {
+				orientation = o
+			}
+		case 0xED, 0xFE:
+		default:
+			out = append(out, segment...)
+		}
+		pos = end
+	}
+
+	return nil, 0, errMalformedImage
+}
+
+// Reads tag 0x0112 from IFD0 of an APP1 payload, 0 when missing
+func exifOrientation(payload []byte) int {
+	if len(payload) < 14 || string(payload[:6]) != "Exif\x00\x00" {
+		return 0
+	}
+	tiff := payload[6:]
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 0
+}
+
+// PNG chunks that only carry metadata
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"iTXt": true,
+	"zTXt": true,
+	"tIME": true,
+}
+
+func stripPNGMetadata(data []byte) ([]byte, error) {
+	const signatureLength = 8
+	if len(data) < signatureLength {
+		return nil, errMalformedImage
+	}
+
+	out := append([]byte{}, data[:signatureLength]...)
+	pos := signatureLength
+	for pos < len(data) {
+		if pos+12 > len(data) {
+			return nil, errMalformedImage
+		}
+		// length, type, data, crc
+		end := pos + 12 + int(binary.BigEndian.Uint32(data[pos:]))
+		if end > len(data) || end < pos {
+			return nil, errMalformedImage
+		}
+		if !pngMetadataChunks[string(data[pos+4:pos+8])] {
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+
+	return out, nil
+}
+
+func stripWebPMetadata(data []byte) ([]byte, error) {
+	const headerLength = 12 // "RIFF" size "WEBP"
+	if len(data) < headerLength {
+		return nil, errMalformedImage
+	}
+
+	out := append([]byte{}, data[:headerLength]...)
+	pos := headerLength
+	for pos < len(data) {
+		if pos+8 > len(data) {
+			return nil, errMalformedImage
+		}
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size + size%2 // Chunks are padded to an even size
+		if end > len(data) || end < pos {
+			return nil, errMalformedImage
+		}
+
+		switch string(data[pos : pos+4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte{}, data[pos:end]...)
+			if len(chunk) > 8 {
+				chunk[8] &^= 0x08 | 0x04 // Clear the EXIF and XMP flags
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
@@ -0,0 +1,109 @@
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/disintegration/imaging"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	_ "golang.org/x/image/webp" // Lets imaging decode WebP uploads
+)
+
+// Widths generated at upload when IMAGE_VARIANT_WIDTHS is not set
+var DefaultVariantWidths = []int{150, 300, 800, 1600}
+
+// ParseVariantWidths reads a comma separated list like "150,300,800"
+func ParseVariantWidths(value string) ([]int, error) {
+	var widths []int
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		width, err := strconv.Atoi(part)
+		if err != nil || width <= 0 {
+			return nil, fmt.Errorf("invalid image variant width %q", part)
+		}
+		widths = append(widths, width)
+	}
+	sort.Ints(widths)
+	return widths, nil
+}
+
+// GenerateVariants resizes the image to every width smaller than the original.
+// Each width gets a JPEG (or PNG for PNG sources) copy every browser can show,
+// plus a WebP copy when it comes out smaller. The WebP encoder is lossless, so
+// that mostly pays off for artwork and screenshots rather than photos.
+func GenerateVariants(imageData []byte, mimeType string, widths []int) ([]models.ImageVariant, error) {
+	img, err := imaging.Decode(bytes.NewReader(imageData))
+	if err != nil {
+		return nil, err
+	}
+
+	var variants []models.ImageVariant
+	for _, width := range widths {
+		// Never upscale, the original is served for anything bigger
+		if width >= img.Bounds().Dx() {
+			continue
+		}
+
+		resized := imaging.Resize(img, width, 0, imaging.Lanczos)
+
+		fallback, err := encodeFallback(resized, mimeType)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, fallback)
+
+		webp, err := encodeWebP(resized)
+		if err != nil {
+			return nil, err
+		}
+		if len(webp.Data) < len(fallback.Data) {
+			variants = append(variants, webp)
+		}
+	}
+
+	return variants, nil
+}
+
+func encodeFallback(img image.Image, mimeType string) (models.ImageVariant, error) {
+	var buf bytes.Buffer
+	var err error
+
+	variantType := ThumbnailMimeType(mimeType)
+	if variantType == "image/png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	}
+	if err != nil {
+		return models.ImageVariant{}, err
+	}
+
+	return newVariant(img, variantType, buf.Bytes()), nil
+}
+
+func encodeWebP(img image.Image) (models.ImageVariant, error) {
+	var buf bytes.Buffer
+	if err := nativewebp.Encode(&buf, img, nil); err != nil {
+		return models.ImageVariant{}, err
+	}
+	return newVariant(img, "image/webp", buf.Bytes()), nil
+}
+
+func newVariant(img image.Image, mimeType string, data []byte) models.ImageVariant {
+	return models.ImageVariant{
+		Width:    img.Bounds().Dx(),
+		Height:   img.Bounds().Dy(),
+		MimeType: mimeType,
+		Data:     data,
+	}
+}
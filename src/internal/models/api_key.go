@@ -0,0 +1,39 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// What an API key may do. Signing in with a session allows everything.
+type APIKeyScope string
+
+const (
+	ScopeGamesRead   APIKeyScope = "games:read"   // Lists, searches and reads games and their images
+	ScopeGamesWrite  APIKeyScope = "games:write"  // Adds, changes and deletes games
+	ScopeImagesWrite APIKeyScope = "images:write" // Uploads, reorders and deletes game images
+)
+
+// A key for scripts and devices, acting as its user within its scopes.
+// Only the hash of the key is stored, Key is only set when it is created.
+type APIKey struct {
+	ID         int64         `json:"id"`
+	Name       string        `json:"name"`
+	Key        string        `json:"key,omitempty"`
+	Hint       string        `json:"hint"` // First characters of the key
+	Scopes     []APIKeyScope `json:"scopes"`
+	CreatedAt  time.Time     `json:"created_at"`
+	ExpiresAt  *time.Time    `json:"expires_at"` // Never expires when nil
+	LastUsedAt *time.Time    `json:"last_used_at"`
+	RevokedAt  *time.Time    `json:"revoked_at,omitempty"`
+}
+
+func (k *APIKey) HasScope(scope APIKeyScope) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+type APIKeyInput struct {
+	Name      string        `json:"name" binding:"required,max=100"`
+	Scopes    []APIKeyScope `json:"scopes" binding:"required,min=1,dive,oneof=games:read games:write images:write"`
+	ExpiresAt *time.Time    `json:"expires_at"`
+}
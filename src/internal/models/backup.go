@@ -0,0 +1,14 @@
+package models
+
+// Rows of one table as JSON lines, one object per row keyed by column
+type TableDump struct {
+	Name string
+	Rows int
+	Data []byte
+}
+
+// What a backup needs besides the tables
+type DatabaseDump struct {
+	MigrationVersion int64
+	BlobKeys         []string // Blob store keys of images, thumbnails, variants and avatars
+}
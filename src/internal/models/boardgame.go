@@ -1,29 +1,167 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // The * means it's a pointer - can be nil (like NULL in SQL).
 type BoardGame struct {
-	ID            int64     `json:"id"`
-	Name          string    `json:"name" binding:"required"`
-	MinPlayers    int       `json:"min_players" binding:"required"`
-	MaxPlayers    int       `json:"max_players,omitempty"` // NULL in DB
-	PlayTime      int       `json:"play_time" binding:"required"`
-	MinAge        int       `json:"min_age" binding:"required"`
-	Description   string    `json:"description" binding:"required"`
-	ImageIDs      []int64   `json:"image_ids,omitempty"`
-	CoverImageUrL string    `json:"coverImageUrl,omitempty"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            int64               `json:"id"`
+	Name          string              `json:"name" binding:"required"`
+	MinPlayers    int                 `json:"min_players" binding:"required"`
+	MaxPlayers    int                 `json:"max_players,omitempty"` // NULL in DB
+	PlayTime      int                 `json:"play_time" binding:"required"`
+	MinAge        int                 `json:"min_age" binding:"required"`
+	Description   string              `json:"description" binding:"required"`
+	YearPublished *int                `json:"year_published,omitempty" binding:"omitempty,max=9999"` // Negative for ancient games
+	BGGID         *int64              `json:"bgg_id,omitempty"`                                      // Only set by imports
+	HouseholdID   int64               `json:"household_id,omitempty"`                                // On create, defaults to the first household the user can edit
+	ImageIDs      []int64             `json:"image_ids,omitempty"`
+	CoverImageUrL string              `json:"coverImageUrl,omitempty"` // Only set when the game has a cover
+	Images        []BoardGameImageRef `json:"images,omitempty"`
+	Tags          []TagRef            `json:"tags,omitempty"`
+	Designers     []PersonRef         `json:"designers,omitempty"`
+	Artists       []PersonRef         `json:"artists,omitempty"`
+	Publishers    []PublisherRef      `json:"publishers,omitempty"`
+	BaseGame      *ExpansionRef       `json:"base_game,omitempty"` // Only set on expansions
+	Expansions    []ExpansionRef      `json:"expansions,omitempty"`
+	Combined      *CombinedRange      `json:"combined,omitempty"` // Only set on games with expansions
+	LentTo        *string             `json:"lent_to,omitempty"`  // Borrower while the game is lent out
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+}
+
+// Image metadata embedded in board game responses
+type BoardGameImageRef struct {
+	ID   int64  `json:"id"`
+	URL  string `json:"url"`
+	Type string `json:"type"`
+}
+
+// A board game matched by a search, higher rank means more relevant
+type BoardGameSearchResult struct {
+	BoardGame
+	Rank float64 `json:"rank"`
 }
 
 type BoardGameImage struct {
-	ID            int64
-	BoardGameID   int64
-	ImageData     []byte
-	ImageMimeType string
-	ThumbnailData []byte
-	ImageType     string
-	DisplayOrder  int
-	UploadedAt    time.Time
+	ID            int64     `json:"id"`
+	BoardGameID   int64     `json:"board_game_id"`
+	ImageData     []byte    `json:"-"`
+	ImageMimeType string    `json:"mime_type"`
+	ThumbnailData []byte    `json:"-"`
+	ImageType     string    `json:"type"`
+	DisplayOrder  int       `json:"display_order"`
+	URL           string    `json:"url,omitempty"`
+	ThumbnailURL  string    `json:"thumbnail_url,omitempty"`
+	ContentHash   string    `json:"etag,omitempty"` // SHA-256 of the original, empty for images not hashed yet
+	UploadedAt    time.Time `json:"uploaded_at"`
+
+	// Resized copies, served through URL?w=<width>
+	Variants []ImageVariant `json:"variants,omitempty"`
+	SrcSet   string         `json:"srcset,omitempty"` // Ready for <img srcset>
+}
+
+// A resized copy of an image in one format
+type ImageVariant struct {
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	MimeType string `json:"mime_type"`
+	Data     []byte `json:"-"`
+
+	// Copied from the original image, for caching headers
+	ContentHash string    `json:"-"`
+	UploadedAt  time.Time `json:"-"`
+}
+
+// Options for listing board games, zero values mean "no filter"
+type BoardGameFilter struct {
+	Players     int      // Supports this many players
+	MaxPlayTime int      // Plays in this many minutes or less
+	Age         int      // Suitable for this age (min_age <= Age)
+	NamePrefix  string   // Name starts with this, case insensitive
+	Tags        []string // Tag names, case insensitive
+	TagMatch    string   // "all" (default) keeps games with every tag, "any" with at least one
+	Designer    string   // A designer's name contains this, case insensitive
+	Publisher   string   // A publisher's name contains this, case insensitive
+	OnShelf     *bool    // true keeps the games at home, false the ones lent out
+	Sort        string   // id, name, play_time, min_players, min_age or created_at
+	Order       string   // asc or desc
+	Limit       int
+	Cursor      string // Next token from a previous page
+}
+
+// What the group can play tonight, zero values mean "no constraint" except for Players
+type RecommendationCriteria struct {
+	Players    int      // How many will play, required
+	Minutes    int      // Time available
+	Age        int      // Age of the youngest player
+	Tags       []string // Preferred tags, a game also matches when its description mentions one
+	ExcludeIDs []int64  // Games nobody wants tonight
+	Limit      int
+}
+
+// A game that fits the criteria, higher score means a better fit
+type Recommendation struct {
+	BoardGame
+	Score       float64  `json:"score"`
+	Reason      string   `json:"reason"` // e.g. "fits 5 players, 45 min, ages 8+"
+	MatchedTags []string `json:"matched_tags,omitempty"`
+}
+
+// One page of board games
+type BoardGamePage struct {
+	Games []*BoardGame
+	Total int    // Games matching the filter across all pages
+	Next  string // Cursor for the next page, empty on the last one
+}
+
+// Short form of the content hash used in ?v=, a new image means a new URL
+// so the old one can be cached forever
+func ImageVersion(contentHash string) string {
+	if len(contentHash) > 16 {
+		return contentHash[:16]
+	}
+	return contentHash
+}
+
+// URLs the API serves images from. version comes from ImageVersion, empty for unversioned URLs.
+func CoverImageURL(boardGameID int64, version string) string {
+	return withVersion(fmt.Sprintf("/api/boardgame/%d/images/cover", boardGameID), "?", version)
+}
+
+func ImageURL(imageID int64, version string) string {
+	return withVersion(fmt.Sprintf("/api/boardgame/images/%d", imageID), "?", version)
+}
+
+func ImageThumbnailURL(imageID int64, version string) string {
+	return withVersion(fmt.Sprintf("/api/boardgame/images/%d/thumbnail", imageID), "?", version)
+}
+
+func ImageVariantURL(imageID int64, width int, version string) string {
+	return withVersion(fmt.Sprintf("/api/boardgame/images/%d?w=%d", imageID, width), "&", version)
+}
+
+func withVersion(url string, separator string, version string) string {
+	if version == "" {
+		return url
+	}
+	return url + separator + "v=" + version
+}
+
+// Width descriptors for <img srcset>, one entry per width.
+// The format is negotiated per request so every width only needs one URL.
+func ImageSrcSet(imageID int64, version string, variants []ImageVariant) string {
+	var entries []string
+	seen := map[int]bool{}
+	for _, variant := range variants {
+		if seen[variant.Width] {
+			continue
+		}
+		seen[variant.Width] = true
+		entries = append(entries, fmt.Sprintf("%s %dw", ImageVariantURL(imageID, variant.Width, version), variant.Width))
+	}
+	return strings.Join(entries, ", ")
 }
@@ -0,0 +1,107 @@
+package models
+
+import "time"
+
+// What a collection import does with a game named like one already on the shelf
+const (
+	DuplicateSkip   = "skip"
+	DuplicateUpdate = "update"
+	DuplicateFail   = "fail" // Nothing is imported
+)
+
+// One board game of a collection export: its board_games columns, tags and image references.
+// Image files are not part of the export, the references point at the images of the exporting
+// shelf and an import copies the ones still there.
+type CollectionGame struct {
+	Line          int                 `json:"-"` // Where the game starts in the imported file
+	ID            int64               `json:"id"`
+	Name          string              `json:"name"`
+	MinPlayers    int                 `json:"min_players"`
+	MaxPlayers    int                 `json:"max_players,omitempty"`
+	PlayTime      int                 `json:"play_time"`
+	MinAge        int                 `json:"min_age"`
+	Description   string              `json:"description"`
+	YearPublished *int                `json:"year_published,omitempty"`
+	BGGID         *int64              `json:"bgg_id,omitempty"`
+	Tags          []TagRef            `json:"tags"`
+	Images        []BoardGameImageRef `json:"images"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+}
+
+func NewCollectionGame(game *BoardGame) *CollectionGame {
+	tags := game.Tags
+	if tags == nil {
+		tags = []TagRef{}
+	}
+	images := game.Images
+	if images == nil {
+		images = []BoardGameImageRef{}
+	}
+
+	return &CollectionGame{
+		ID:            game.ID,
+		Name:          game.Name,
+		MinPlayers:    game.MinPlayers,
+		MaxPlayers:    game.MaxPlayers,
+		PlayTime:      game.PlayTime,
+		MinAge:        game.MinAge,
+		Description:   game.Description,
+		YearPublished: game.YearPublished,
+		BGGID:         game.BGGID,
+		Tags:          tags,
+		Images:        images,
+		CreatedAt:     game.CreatedAt,
+		UpdatedAt:     game.UpdatedAt,
+	}
+}
+
+// The game as HandleBoardGameCreate would bind it, so both validate the same way
+func (g *CollectionGame) BoardGame() *BoardGame {
+	return &BoardGame{
+		Name:          g.Name,
+		MinPlayers:    g.MinPlayers,
+		MaxPlayers:    g.MaxPlayers,
+		PlayTime:      g.PlayTime,
+		MinAge:        g.MinAge,
+		Description:   g.Description,
+		YearPublished: g.YearPublished,
+		BGGID:         g.BGGID,
+	}
+}
+
+// What a collection import did
+type CollectionImportReport struct {
+	Created []CollectionImportEntry `json:"created"`
+	Updated []CollectionImportEntry `json:"updated"`
+	Skipped []CollectionImportEntry `json:"skipped"`
+	Errors  []CollectionRowError    `json:"errors"` // Rows left out
+}
+
+type CollectionImportEntry struct {
+	Line        int                     `json:"line"`
+	BoardGameID int64                   `json:"board_game_id"`
+	Name        string                  `json:"name"`
+	Images      []CollectionImageResult `json:"images,omitempty"` // One per image reference of the row
+}
+
+// What an import did with an image reference
+const (
+	ImageRestored    = "restored"     // Copied onto the game, files included
+	ImageKept        = "kept"         // The game already has the same image
+	ImageMissing     = "missing"      // Not on a shelf of the user anymore, or its files are gone
+	ImageCoverExists = "cover_exists" // A cover the game already has another cover for
+)
+
+type CollectionImageResult struct {
+	ID     int64  `json:"id"` // Id in the imported file
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+// Why a row of an imported file was left out, Line is where the row starts
+type CollectionRowError struct {
+	Line  int    `json:"line"`
+	Name  string `json:"name,omitempty"`
+	Error string `json:"error"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// Roles a person can have on a game
+const (
+	RoleDesigner = "designer"
+	RoleArtist   = "artist"
+)
+
+// A designer or artist
+type Person struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name" binding:"required,max=200"`
+	GameCount int       `json:"game_count"` // Games credited to the person, in the listed role when there is one
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Publisher struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name" binding:"required,max=200"`
+	GameCount int       `json:"game_count"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Person embedded in board game responses
+type PersonRef struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// Publisher embedded in board game responses
+type PublisherRef struct {
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	YearPublished *int   `json:"year_published,omitempty"`
+}
+
+// Who made a game, replaces all of its credits at once. Each entry is the
+// id of an existing person or publisher or a name, unknown names are added.
+type BoardGameCredits struct {
+	Designers  []CreditInput          `json:"designers" binding:"dive"`
+	Artists    []CreditInput          `json:"artists" binding:"dive"`
+	Publishers []PublisherCreditInput `json:"publishers" binding:"dive"`
+}
+
+type CreditInput struct {
+	ID   *int64 `json:"id,omitempty"`
+	Name string `json:"name" binding:"required_without=ID,max=200"`
+}
+
+type PublisherCreditInput struct {
+	CreditInput
+	YearPublished *int `json:"year_published,omitempty" binding:"omitempty,min=1000,max=9999"`
+}
@@ -0,0 +1,60 @@
+package models
+
+// How a game relates to the game it expands
+const (
+	RelationExpansion           = "expansion"
+	RelationStandaloneExpansion = "standalone_expansion" // Also playable on its own
+	RelationPromo               = "promo"
+)
+
+// Makes a game an expansion of another. MaxPlayers and PlayTime are only set
+// when the expansion changes them once added to the base game.
+type ExpansionLink struct {
+	BaseGameID int64  `json:"base_game_id" binding:"required"`
+	Relation   string `json:"relation" binding:"required,oneof=expansion standalone_expansion promo"`
+	MaxPlayers *int   `json:"max_players,omitempty" binding:"omitempty,gt=0"`
+	PlayTime   *int   `json:"play_time,omitempty" binding:"omitempty,gt=0"`
+}
+
+// Expansion or base game embedded in board game responses, with the
+// relation and changes the expansion declares
+type ExpansionRef struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Relation   string `json:"relation"`
+	MaxPlayers *int   `json:"max_players,omitempty"`
+	PlayTime   *int   `json:"play_time,omitempty"`
+}
+
+// Player count and play time of a base game with all of its expansions
+type CombinedRange struct {
+	MinPlayers  int `json:"min_players"`
+	MaxPlayers  int `json:"max_players,omitempty"` // 0 when the base game has no maximum
+	MinPlayTime int `json:"min_play_time"`
+	MaxPlayTime int `json:"max_play_time"`
+}
+
+// Widens the base game's ranges with what its expansions declare,
+// nil for games without expansions
+func CombineExpansions(game *BoardGame) *CombinedRange {
+	if len(game.Expansions) == 0 {
+		return nil
+	}
+
+	combined := &CombinedRange{
+		MinPlayers:  game.MinPlayers,
+		MaxPlayers:  game.MaxPlayers,
+		MinPlayTime: game.PlayTime,
+		MaxPlayTime: game.PlayTime,
+	}
+	for _, expansion := range game.Expansions {
+		if expansion.MaxPlayers != nil && combined.MaxPlayers > 0 && *expansion.MaxPlayers > combined.MaxPlayers {
+			combined.MaxPlayers = *expansion.MaxPlayers
+		}
+		if expansion.PlayTime != nil {
+			combined.MinPlayTime = min(combined.MinPlayTime, *expansion.PlayTime)
+			combined.MaxPlayTime = max(combined.MaxPlayTime, *expansion.PlayTime)
+		}
+	}
+	return combined
+}
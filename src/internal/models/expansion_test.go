@@ -0,0 +1,48 @@
+package models
+
+import "testing"
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestCombineExpansions(t *testing.T) {
+	tests := []struct {
+		name     string
+		game     BoardGame
+		expected *CombinedRange
+	}{
+		{
+			name:     "no expansions",
+			game:     BoardGame{MinPlayers: 1, MaxPlayers: 4, PlayTime: 90},
+			expected: nil,
+		},
+		{
+			name: "widens players and time",
+			game: BoardGame{MinPlayers: 1, MaxPlayers: 4, PlayTime: 90, Expansions: []ExpansionRef{
+				{ID: 2, MaxPlayers: intPtr(6), PlayTime: intPtr(150)},
+				{ID: 3, MaxPlayers: intPtr(5), PlayTime: intPtr(60)},
+			}},
+			expected: &CombinedRange{MinPlayers: 1, MaxPlayers: 6, MinPlayTime: 60, MaxPlayTime: 150},
+		},
+		{
+			name:     "expansion without changes",
+			game:     BoardGame{MinPlayers: 2, MaxPlayers: 4, PlayTime: 45, Expansions: []ExpansionRef{{ID: 2}}},
+			expected: &CombinedRange{MinPlayers: 2, MaxPlayers: 4, MinPlayTime: 45, MaxPlayTime: 45},
+		},
+		{
+			name:     "no maximum stays open",
+			game:     BoardGame{MinPlayers: 3, PlayTime: 30, Expansions: []ExpansionRef{{ID: 2, MaxPlayers: intPtr(12)}}},
+			expected: &CombinedRange{MinPlayers: 3, MinPlayTime: 30, MaxPlayTime: 30},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CombineExpansions(&tt.game)
+			if (got == nil) != (tt.expected == nil) || (got != nil && *got != *tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// What a member may do in a household
+type HouseholdRole string
+
+const (
+	RoleOwner  HouseholdRole = "owner"  // Manages members and invitations, edits games
+	RoleEditor HouseholdRole = "editor" // Adds, changes and deletes games and their images
+	RoleViewer HouseholdRole = "viewer" // Only sees the games
+)
+
+// Owners and editors can change the household's games
+func (r HouseholdRole) CanEdit() bool {
+	return r == RoleOwner || r == RoleEditor
+}
+
+// People sharing a collection of games
+type Household struct {
+	ID        int64             `json:"id"`
+	Name      string            `json:"name"`
+	Role      HouseholdRole     `json:"role"` // Of the signed in user
+	Members   []HouseholdMember `json:"members,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+type HouseholdMember struct {
+	UserID      int64         `json:"user_id"`
+	Email       string        `json:"email"`
+	DisplayName string        `json:"display_name"`
+	Role        HouseholdRole `json:"role"`
+	JoinedAt    time.Time     `json:"joined_at"`
+}
+
+type HouseholdInput struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type HouseholdRoleInput struct {
+	Role HouseholdRole `json:"role" binding:"required,oneof=owner editor viewer"`
+}
+
+// Joins whoever accepts it to the household, once. The token is only known
+// when the invitation is created.
+type Invitation struct {
+	ID          int64         `json:"id"`
+	HouseholdID int64         `json:"household_id"`
+	Role        HouseholdRole `json:"role"`
+	Token       string        `json:"token,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	ExpiresAt   time.Time     `json:"expires_at"`
+}
+
+type InvitationAcceptance struct {
+	Token string `json:"token" binding:"required"`
+}
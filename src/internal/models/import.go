@@ -0,0 +1,38 @@
+package models
+
+// What happened to the cover of an imported game
+const (
+	CoverAttached   = "attached"
+	CoverKept       = "kept"        // The game already had one
+	CoverNotBundled = "not_bundled" // The file only links to it, nothing is downloaded
+	CoverUnreadable = "unreadable"
+)
+
+// A board game read from an import file, matched to the collection by BGGID
+type ImportedBoardGame struct {
+	BoardGame
+	Partial    bool // Collection exports have no description, age, categories or mechanics
+	Categories []string
+	Mechanics  []string
+	Designers  []string
+	Artists    []string
+	Publishers []string
+	Cover      []byte // Cover bundled in the file, nil when there is none
+	CoverURL   string // Cover the file only links to
+}
+
+// What an import did, or would do for a dry run
+type ImportReport struct {
+	DryRun    bool          `json:"dry_run"`
+	Created   []ImportEntry `json:"created"`
+	Updated   []ImportEntry `json:"updated"`
+	Conflicts []ImportEntry `json:"conflicts"` // Skipped, see their reason
+}
+
+type ImportEntry struct {
+	BGGID       int64  `json:"bgg_id"`
+	Name        string `json:"name"`
+	BoardGameID int64  `json:"board_game_id,omitempty"` // Not set for games a dry run would create
+	Cover       string `json:"cover,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+}
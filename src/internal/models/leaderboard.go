@@ -0,0 +1,13 @@
+package models
+
+// A line of a leaderboard, best rating first
+type LeaderboardEntry struct {
+	Position    int     `json:"position"`
+	PlayerID    int64   `json:"player_id"`
+	Name        string  `json:"name"`
+	AvatarURL   string  `json:"avatar_url,omitempty"`
+	Rating      int     `json:"rating"`
+	GamesPlayed int     `json:"games_played"`
+	Wins        int     `json:"wins"`
+	WinRate     float64 `json:"win_rate"` // 0 to 1
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// A game lent to someone, until it comes back
+type Loan struct {
+	ID             int64      `json:"id"`
+	BoardGameID    int64      `json:"board_game_id"`
+	BoardGameName  string     `json:"board_game_name"`
+	BorrowerName   string     `json:"borrower_name"`
+	BorrowerUserID *int64     `json:"borrower_user_id,omitempty"` // Only for borrowers with an account
+	LentAt         time.Time  `json:"lent_at"`
+	DueAt          *time.Time `json:"due_at,omitempty"`
+	ReturnedAt     *time.Time `json:"returned_at,omitempty"` // nil while the game is away
+	ConditionNote  string     `json:"condition_note,omitempty"`
+	Overdue        bool       `json:"overdue"` // Still away after its due date
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Send a borrower name, the id of a user, or both to name the user differently
+type LoanInput struct {
+	BorrowerName   string     `json:"borrower_name" binding:"required_without=BorrowerUserID,max=100"`
+	BorrowerUserID *int64     `json:"borrower_user_id"`
+	LentAt         *time.Time `json:"lent_at"` // Now when nil
+	DueAt          *time.Time `json:"due_at"`
+}
+
+type LoanReturn struct {
+	ReturnedAt    *time.Time `json:"returned_at"` // Now when nil
+	ConditionNote string     `json:"condition_note" binding:"max=1000"`
+}
+
+// Which loans to list
+const (
+	LoanStatusActive   = "active" // Default
+	LoanStatusOverdue  = "overdue"
+	LoanStatusReturned = "returned"
+	LoanStatusAll      = "all"
+)
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// A logged play of a board game
+type PlaySession struct {
+	ID              int64               `json:"id"`
+	BoardGameID     *int64              `json:"board_game_id"`   // nil once the game is deleted
+	BoardGameName   string              `json:"board_game_name"` // Kept for the history of deleted games
+	PlayedAt        time.Time           `json:"played_at" binding:"required"`
+	DurationMinutes int                 `json:"duration_minutes,omitempty" binding:"omitempty,gt=0"`
+	Location        string              `json:"location,omitempty" binding:"max=255"`
+	Notes           string              `json:"notes,omitempty"`
+	Players         []PlaySessionPlayer `json:"players" binding:"required,min=1,dive"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+}
+
+// Someone who took part in a play session. Send either the id of a player or
+// a name, an unknown name adds the player to the roster.
+type PlaySessionPlayer struct {
+	PlayerID    *int64 `json:"player_id,omitempty"` // nil once the player is deleted
+	Name        string `json:"name" binding:"required_without=PlayerID,max=100"`
+	Score       *int   `json:"score,omitempty"`                         // nil when the game has no score
+	Rank        *int   `json:"rank,omitempty" binding:"omitempty,gt=0"` // Finishing position, equal ranks are ties
+	Team        *int   `json:"team,omitempty" binding:"omitempty,gt=0"` // Teammates share the number
+	Winner      bool   `json:"winner"`
+	FirstPlayer bool   `json:"first_player"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Someone from the household, linked to the play sessions they took part in
+type Player struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name" binding:"required,max=100"`
+	HouseholdID int64     `json:"household_id,omitempty"` // On create, defaults to the first household the user can edit
+	AvatarURL   string    `json:"avatar_url,omitempty"`   // Only set when the player has an avatar
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Avatar bytes with what the caching headers need
+type PlayerAvatar struct {
+	Data        []byte
+	MimeType    string
+	ContentHash string
+	UpdatedAt   time.Time
+}
+
+// How a player does overall and game by game
+type PlayerStats struct {
+	PlayerID         int64              `json:"player_id"`
+	Name             string             `json:"name"`
+	GamesPlayed      int                `json:"games_played"`
+	Wins             int                `json:"wins"`
+	WinRate          float64            `json:"win_rate"`       // 0 to 1
+	FavouriteGame    *PlayerGameStats   `json:"favourite_game"` // Most played, nil before the first play
+	LongestWinStreak int                `json:"longest_win_streak"`
+	PerGame          []*PlayerGameStats `json:"per_game"`
+}
+
+type PlayerGameStats struct {
+	BoardGameID   *int64    `json:"board_game_id"` // nil for deleted games
+	BoardGameName string    `json:"board_game_name"`
+	Plays         int       `json:"plays"`
+	Wins          int       `json:"wins"`
+	WinRate       float64   `json:"win_rate"`
+	LastPlayedAt  time.Time `json:"last_played_at"`
+}
+
+func PlayerAvatarURL(playerID int64, version string) string {
+	return withVersion(fmt.Sprintf("/api/players/%d/avatar", playerID), "?", version)
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Kinds of tags, the taxonomy is flat inside each kind
+const (
+	TagKindCategory = "category"
+	TagKindMechanic = "mechanic"
+	TagKindTheme    = "theme"
+)
+
+// A label shared by many games, e.g. "co-op" (mechanic) or "party" (category)
+type Tag struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name" binding:"required,max=100"`
+	Kind      string    `json:"kind" binding:"required,oneof=category mechanic theme"`
+	GameCount int       `json:"game_count"` // Games with this tag
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Tag embedded in board game responses
+type TagRef struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Someone signing in. Each user starts with a household of their own, the
+// first user to register is the admin.
+type User struct {
+	ID           int64     `json:"id"`
+	Email        string    `json:"email"`
+	DisplayName  string    `json:"display_name"`
+	PasswordHash string    `json:"-"`
+	IsAdmin      bool      `json:"is_admin"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type Registration struct {
+	Email       string `json:"email" binding:"required,email,max=255"`
+	Password    string `json:"password" binding:"required,min=8,max=256"`
+	DisplayName string `json:"display_name" binding:"required,max=100"`
+}
+
+type Credentials struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// A signed in browser or client. Only the hash of the token is stored.
+type Session struct {
+	ID        int64
+	UserID    int64
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Who an OpenID Connect provider says signed in. Issuer and subject identify
+// them for good, the email can change.
+type Identity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// A sign in with the provider under way, until it sends the browser back.
+// Only the hash of the state is stored.
+type OIDCLogin struct {
+	StateHash    string
+	CodeVerifier string
+	Nonce        string
+	ExpiresAt    time.Time
+}
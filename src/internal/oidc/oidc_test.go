@@ -0,0 +1,209 @@
+package oidc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/oidc/oidctest"
+)
+
+func newTestProvider(t *testing.T, idp *oidctest.Server) *Provider {
+	t.Helper()
+	provider, err := NewProvider(Config{
+		Issuer:       idp.URL,
+		ClientID:     oidctest.ClientID,
+		ClientSecret: oidctest.ClientSecret,
+		RedirectURL:  oidctest.RedirectURL,
+		Scopes:       []string{"email", "profile"},
+	})
+	if err != nil {
+		t.Fatalf("NewProvider() failed: %v", err)
+	}
+	return provider
+}
+
+// Sends the browser to the provider and follows it back to the callback,
+// returns the code
+func signIn(t *testing.T, provider *Provider, idp *oidctest.Server, state, nonce, challenge string) string {
+	t.Helper()
+	authURL, err := provider.AuthCodeURL(context.Background(), state, nonce, challenge)
+	if err != nil {
+		t.Fatalf("AuthCodeURL() failed: %v", err)
+	}
+
+	callback := idp.Authorize(t, authURL)
+	if callback.Query().Get("state") != state {
+		t.Fatalf("expected the state to come back, got %q", callback.Query().Get("state"))
+	}
+	return callback.Query().Get("code")
+}
+
+func TestProvider_SignIn(t *testing.T) {
+	// Arrange
+	idp := oidctest.NewServer(t)
+	provider := newTestProvider(t, idp)
+	verifier, challenge, err := NewPKCE()
+	if err != nil {
+		t.Fatalf("NewPKCE() failed: %v", err)
+	}
+	code := signIn(t, provider, idp, "state-1", "nonce-1", challenge)
+
+	// Act
+	identity, err := provider.Exchange(context.Background(), code, verifier, "nonce-1")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Exchange() failed: %v", err)
+	}
+	if identity.Issuer != idp.URL || identity.Subject != "user-1" {
+		t.Errorf("expected user-1 of %s, got %s of %s", idp.URL, identity.Subject, identity.Issuer)
+	}
+	if identity.Email != "ada@example.com" || !identity.EmailVerified || identity.Name != "Ada Lovelace" {
+		t.Errorf("unexpected identity %+v", identity)
+	}
+}
+
+func TestProvider_Exchange_Rejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		verifier string // The right one when empty
+		nonce    string // nonce-1 when empty
+		claims   func(claims map[string]any)
+		err      error
+	}{
+		{name: "wrong verifier", verifier: "not-the-verifier", err: ErrExchange},
+		{name: "wrong nonce", nonce: "nonce-2", err: ErrInvalidToken},
+		{name: "other audience", claims: func(c map[string]any) { c["aud"] = "someone-else" }, err: ErrInvalidToken},
+		{name: "other issuer", claims: func(c map[string]any) { c["iss"] = "https://evil.example.com" }, err: ErrInvalidToken},
+		{name: "expired", claims: func(c map[string]any) { c["exp"] = time.Now().Add(-time.Hour).Unix() }, err: ErrInvalidToken},
+		{
+			name: "several audiences, authorized party is another",
+			claims: func(c map[string]any) {
+				c["aud"] = []string{oidctest.ClientID, "someone-else"}
+				c["azp"] = "someone-else"
+			},
+			err: ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			idp := oidctest.NewServer(t)
+			if tt.claims != nil {
+				defaults := idp.Claims
+				idp.Claims = func(nonce string) map[string]any {
+					claims := defaults(nonce)
+					tt.claims(claims)
+					return claims
+				}
+			}
+			provider := newTestProvider(t, idp)
+			verifier, challenge, _ := NewPKCE()
+			code := signIn(t, provider, idp, "state-1", "nonce-1", challenge)
+
+			if tt.verifier != "" {
+				verifier = tt.verifier
+			}
+			nonce := "nonce-1"
+			if tt.nonce != "" {
+				nonce = tt.nonce
+			}
+
+			// Act
+			_, err := provider.Exchange(context.Background(), code, verifier, nonce)
+
+			// Assert
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestProvider_Verify_Signature(t *testing.T) {
+	idp := oidctest.NewServer(t)
+	provider := newTestProvider(t, idp)
+	doc, err := provider.discover(context.Background())
+	if err != nil {
+		t.Fatalf("discover() failed: %v", err)
+	}
+
+	valid := idp.Sign(map[string]string{"alg": "RS256", "kid": "key-1"}, idp.Claims("n"))
+	parts := strings.Split(valid, ".")
+	tampered, _ := json.Marshal(map[string]any{"iss": idp.URL, "sub": "admin", "aud": oidctest.ClientID,
+		"exp": time.Now().Add(time.Hour).Unix(), "nonce": "n"})
+
+	tests := []struct {
+		name  string
+		token string
+		ok    bool
+	}{
+		{"signed by the provider", valid, true},
+		{"claims changed", parts[0] + "." + base64.RawURLEncoding.EncodeToString(tampered) + "." + parts[2], false},
+		{"unsigned", base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + ".", false},
+		{"unknown key", idp.Sign(map[string]string{"alg": "RS256", "kid": "key-2"}, idp.Claims("n")), false},
+		{"not a JWT", "abc", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := provider.verify(context.Background(), doc, tt.token, "n")
+
+			// Assert
+			if tt.ok && err != nil {
+				t.Errorf("expected the token to verify, got %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("expected ErrInvalidToken, got %v", err)
+			}
+		})
+	}
+}
+
+func TestProvider_Discovery_IssuerMismatch(t *testing.T) {
+	// Arrange
+	idp := oidctest.NewServer(t)
+	provider, _ := NewProvider(Config{Issuer: idp.URL + "/realms/other", ClientID: oidctest.ClientID, RedirectURL: oidctest.RedirectURL})
+
+	// Act
+	_, err := provider.AuthCodeURL(context.Background(), "s", "n", "c")
+
+	// Assert
+	if !errors.Is(err, ErrDiscovery) {
+		t.Errorf("expected ErrDiscovery, got %v", err)
+	}
+}
+
+func TestNewProvider_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{"no issuer", Config{ClientID: oidctest.ClientID, RedirectURL: oidctest.RedirectURL}},
+		{"no client", Config{Issuer: "https://idp.example.com", RedirectURL: oidctest.RedirectURL}},
+		{"no redirect", Config{Issuer: "https://idp.example.com", ClientID: oidctest.ClientID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewProvider(tt.config); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+// RFC 7636 appendix B
+func TestPKCEChallenge(t *testing.T) {
+	challenge := pkceChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if challenge != "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM" {
+		t.Errorf("unexpected challenge %s", challenge)
+	}
+}
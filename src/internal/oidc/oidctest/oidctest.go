@@ -0,0 +1,180 @@
+// Package oidctest runs an in-process OpenID Connect provider for tests, so
+// signing in needs no real Google or Keycloak.
+package oidctest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The client the provider knows
+const (
+	ClientID     = "my-game-shelf"
+	ClientSecret = "s3cret"
+	RedirectURL  = "http://localhost:8080/api/auth/oidc/callback"
+)
+
+// Server signs anyone in as its user without asking, checks PKCE and the
+// client credentials, and signs ID tokens with its RSA key
+type Server struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]pendingCode
+
+	// What the next ID token says, tests change it
+	Claims func(nonce string) map[string]any
+}
+
+type pendingCode struct {
+	challenge string
+	nonce     string
+}
+
+// NewServer starts the provider, it stops with the test
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	s := &Server{key: key, codes: map[string]pendingCode{}}
+	s.Server = httptest.NewServer(s)
+	t.Cleanup(s.Close)
+
+	s.Claims = func(nonce string) map[string]any {
+		return map[string]any{
+			"iss":            s.URL,
+			"sub":            "user-1",
+			"aud":            ClientID,
+			"exp":            time.Now().Add(time.Hour).Unix(),
+			"nonce":          nonce,
+			"email":          "ada@example.com",
+			"email_verified": true,
+			"name":           "Ada Lovelace",
+		}
+	}
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/.well-known/openid-configuration":
+		writeJSON(w, map[string]string{
+			"issuer":                 s.URL,
+			"authorization_endpoint": s.URL + "/authorize",
+			"token_endpoint":         s.URL + "/token",
+			"jwks_uri":               s.URL + "/jwks",
+		})
+
+	case "/jwks":
+		e := big.NewInt(int64(s.key.E)).Bytes()
+		writeJSON(w, map[string]any{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "key-1", "use": "sig", "alg": "RS256",
+			"n": base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(e),
+		}}})
+
+	case "/authorize":
+		query := r.URL.Query()
+		if query.Get("client_id") != ClientID || query.Get("redirect_uri") != RedirectURL ||
+			query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" ||
+			!strings.Contains(query.Get("scope"), "openid") {
+			http.Error(w, "invalid_request", http.StatusBadRequest)
+			return
+		}
+
+		s.mu.Lock()
+		code := "code-" + query.Get("state")
+		s.codes[code] = pendingCode{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+		s.mu.Unlock()
+
+		callback := RedirectURL + "?" + url.Values{"code": {code}, "state": {query.Get("state")}}.Encode()
+		http.Redirect(w, r, callback, http.StatusFound)
+
+	case "/token":
+		clientID, secret, ok := r.BasicAuth()
+		if !ok || clientID != ClientID || secret != ClientSecret {
+			http.Error(w, `{"error": "invalid_client"}`, http.StatusUnauthorized)
+			return
+		}
+		if r.PostFormValue("grant_type") != "authorization_code" || r.PostFormValue("redirect_uri") != RedirectURL {
+			http.Error(w, `{"error": "invalid_request"}`, http.StatusBadRequest)
+			return
+		}
+
+		// Codes work once, and only with the verifier of their challenge
+		s.mu.Lock()
+		pending, ok := s.codes[r.PostFormValue("code")]
+		delete(s.codes, r.PostFormValue("code"))
+		s.mu.Unlock()
+		sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != pending.challenge {
+			http.Error(w, `{"error": "invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+
+		writeJSON(w, map[string]string{
+			"access_token": "unused",
+			"token_type":   "Bearer",
+			"id_token":     s.Sign(map[string]string{"alg": "RS256", "kid": "key-1"}, s.Claims(pending.nonce)),
+		})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Sign returns a JWT with the header and claims, signed RS256 with the key
+// of the provider whatever the header says
+func (s *Server) Sign(header any, claims any) string {
+	headerJSON, _ := json.Marshal(header)
+	claimsJSON, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+
+	digest := sha256.Sum256([]byte(signed))
+	signature, _ := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// Authorize opens the sign in page like a browser and returns where the
+// provider sends it back to
+func (s *Server) Authorize(t testing.TB, authURL string) *url.URL {
+	t.Helper()
+	browser := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := browser.Get(authURL)
+	if err != nil {
+		t.Fatalf("failed to open the sign in page: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("expected the provider to redirect back, got %d", resp.StatusCode)
+	}
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("invalid redirect: %v", err)
+	}
+	return callback
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
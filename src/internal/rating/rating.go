@@ -0,0 +1,148 @@
+// Package rating is a multiplayer Elo: every game is scored as a set of
+// head-to-head matches between the teams that took part.
+package rating
+
+import "math"
+
+const (
+	InitialRating = 1500.0
+	KFactor       = 32.0
+)
+
+// One player in a finished game
+type Participant struct {
+	PlayerID int64
+	Rank     int  // Finishing position, 1 is first and equal ranks are ties. 0 when not recorded
+	Team     int  // Players with the same team play together, 0 plays alone
+	Winner   bool // Only used when nobody in the game has a rank
+}
+
+// Skill of a player in one scope, a single game or overall
+type Rating struct {
+	Value float64
+	Games int
+	Wins  int
+}
+
+// Ratings by player id
+type Table map[int64]*Rating
+
+func (t Table) get(playerID int64) *Rating {
+	r, ok := t[playerID]
+	if !ok {
+		r = &Rating{Value: InitialRating}
+		t[playerID] = r
+	}
+	return r
+}
+
+type team struct {
+	members []int64
+	rank    int
+	rating  float64
+}
+
+// Updates the ratings of everyone in the game. Games with fewer than two
+// teams change nothing, there was nobody to win against. The teams that
+// finished first win, unless every team tied and nobody was beaten.
+func (t Table) Apply(participants []Participant) {
+	teams := groupTeams(normalizeRanks(participants))
+	if len(teams) < 2 {
+		return
+	}
+
+	for _, tm := range teams {
+		total := 0.0
+		for _, id := range tm.members {
+			total += t.get(id).Value
+		}
+		tm.rating = total / float64(len(tm.members))
+	}
+
+	// Each team plays every other one, the K factor is shared between those matches
+	k := KFactor / float64(len(teams)-1)
+	deltas := make([]float64, len(teams))
+	best, worst := teams[0].rank, teams[0].rank
+	for i, a := range teams {
+		best, worst = min(best, a.rank), max(worst, a.rank)
+		for j, b := range teams {
+			if i == j {
+				continue
+			}
+			deltas[i] += k * (score(a.rank, b.rank) - expected(a.rating, b.rating))
+		}
+	}
+
+	for i, tm := range teams {
+		for _, id := range tm.members {
+			r := t.get(id)
+			r.Value += deltas[i]
+			r.Games++
+			if tm.rank == best && best < worst {
+				r.Wins++
+			}
+		}
+	}
+}
+
+// Chance of a beating b
+func expected(a float64, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+func score(rankA int, rankB int) float64 {
+	switch {
+	case rankA < rankB:
+		return 1
+	case rankA == rankB:
+		return 0.5
+	default:
+		return 0
+	}
+}
+
+// Fills in missing ranks: without any rank the winners are first and everyone
+// else ties second, otherwise unranked players finish behind the ranked ones
+func normalizeRanks(participants []Participant) []Participant {
+	last := 0
+	for _, p := range participants {
+		last = max(last, p.Rank)
+	}
+
+	normalized := make([]Participant, len(participants))
+	for i, p := range participants {
+		switch {
+		case p.Rank > 0:
+		case last > 0:
+			p.Rank = last + 1
+		case p.Winner:
+			p.Rank = 1
+		default:
+			p.Rank = 2
+		}
+		normalized[i] = p
+	}
+	return normalized
+}
+
+// A team finishes where its best member did, players without a team are teams of one
+func groupTeams(participants []Participant) []*team {
+	var teams []*team
+	byNumber := map[int]*team{}
+	for _, p := range participants {
+		if p.Team == 0 {
+			teams = append(teams, &team{members: []int64{p.PlayerID}, rank: p.Rank})
+			continue
+		}
+
+		tm, ok := byNumber[p.Team]
+		if !ok {
+			tm = &team{rank: p.Rank}
+			byNumber[p.Team] = tm
+			teams = append(teams, tm)
+		}
+		tm.members = append(tm.members, p.PlayerID)
+		tm.rank = min(tm.rank, p.Rank)
+	}
+	return teams
+}
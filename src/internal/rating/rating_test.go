@@ -0,0 +1,139 @@
+package rating
+
+import (
+	"math"
+	"testing"
+)
+
+func TestApply_TwoPlayers(t *testing.T) {
+	// Arrange
+	table := Table{}
+
+	// Act
+	table.Apply([]Participant{{PlayerID: 1, Rank: 1}, {PlayerID: 2, Rank: 2}})
+
+	// Assert
+	if table[1].Value != InitialRating+KFactor/2 || table[2].Value != InitialRating-KFactor/2 {
+		t.Errorf("expected +-16 between equal players, got %v and %v", table[1].Value, table[2].Value)
+	}
+	if table[1].Games != 1 || table[1].Wins != 1 || table[2].Wins != 0 {
+		t.Errorf("expected one game and one win for the winner, got %+v and %+v", table[1], table[2])
+	}
+}
+
+func TestApply_TieBetweenEqualPlayersChangesNothing(t *testing.T) {
+	table := Table{}
+
+	table.Apply([]Participant{{PlayerID: 1, Rank: 1}, {PlayerID: 2, Rank: 1}})
+
+	if table[1].Value != InitialRating || table[2].Value != InitialRating {
+		t.Errorf("expected ratings to stay at %v, got %v and %v", InitialRating, table[1].Value, table[2].Value)
+	}
+	if table[1].Wins != 0 || table[2].Wins != 0 {
+		t.Error("expected a tie between everyone not to count as a win")
+	}
+}
+
+func TestApply_Wins(t *testing.T) {
+	tests := []struct {
+		name         string
+		participants []Participant
+		wins         map[int64]int
+	}{
+		{"shared first place", []Participant{{PlayerID: 1, Rank: 1}, {PlayerID: 2, Rank: 1}, {PlayerID: 3, Rank: 2}},
+			map[int64]int{1: 1, 2: 1, 3: 0}},
+		{"ranked from second", []Participant{{PlayerID: 1, Rank: 2}, {PlayerID: 2, Rank: 3}},
+			map[int64]int{1: 1, 2: 0}},
+		{"no ranks and no winner", []Participant{{PlayerID: 1}, {PlayerID: 2}, {PlayerID: 3}},
+			map[int64]int{1: 0, 2: 0, 3: 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := Table{}
+
+			table.Apply(tt.participants)
+
+			for id, wins := range tt.wins {
+				if table[id].Wins != wins {
+					t.Errorf("expected player %d to have %d wins, got %d", id, wins, table[id].Wins)
+				}
+			}
+		})
+	}
+}
+
+func TestApply_UpsetMovesMoreThanExpectedWin(t *testing.T) {
+	favourite := Table{1: {Value: 1700}, 2: {Value: 1300}}
+	favourite.Apply([]Participant{{PlayerID: 1, Rank: 1}, {PlayerID: 2, Rank: 2}})
+
+	upset := Table{1: {Value: 1700}, 2: {Value: 1300}}
+	upset.Apply([]Participant{{PlayerID: 1, Rank: 2}, {PlayerID: 2, Rank: 1}})
+
+	if gain, loss := favourite[1].Value-1700, 1700-upset[1].Value; gain >= loss {
+		t.Errorf("expected the favourite to gain less (%v) than it loses in an upset (%v)", gain, loss)
+	}
+}
+
+func TestApply_Teams(t *testing.T) {
+	// Arrange
+	table := Table{}
+
+	// Act
+	table.Apply([]Participant{
+		{PlayerID: 1, Team: 1, Rank: 1},
+		{PlayerID: 2, Team: 1, Rank: 1},
+		{PlayerID: 3, Team: 2, Rank: 2},
+		{PlayerID: 4, Team: 2, Rank: 2},
+	})
+
+	// Assert
+	if table[1].Value != table[2].Value || table[1].Value <= InitialRating {
+		t.Errorf("expected both winners to gain the same, got %v and %v", table[1].Value, table[2].Value)
+	}
+	if table[3].Value != table[4].Value || table[3].Value >= InitialRating {
+		t.Errorf("expected both losers to lose the same, got %v and %v", table[3].Value, table[4].Value)
+	}
+}
+
+func TestApply_MultiplayerIsZeroSum(t *testing.T) {
+	table := Table{1: {Value: 1600}, 2: {Value: 1500}, 3: {Value: 1450}, 4: {Value: 1400}}
+
+	table.Apply([]Participant{
+		{PlayerID: 1, Rank: 3},
+		{PlayerID: 2, Rank: 1},
+		{PlayerID: 3, Rank: 2},
+		{PlayerID: 4, Rank: 2},
+	})
+
+	total := 0.0
+	for _, r := range table {
+		total += r.Value
+	}
+	if math.Abs(total-5950) > 1e-9 {
+		t.Errorf("expected the total rating to stay at 5950, got %v", total)
+	}
+	if table[2].Value <= 1500 || table[1].Value >= 1600 {
+		t.Errorf("expected the winner to gain and the last player to lose, got %+v", table)
+	}
+}
+
+func TestApply_WinnerFlagWithoutRanks(t *testing.T) {
+	table := Table{}
+
+	table.Apply([]Participant{{PlayerID: 1}, {PlayerID: 2, Winner: true}, {PlayerID: 3}})
+
+	if table[2].Value <= InitialRating || table[1].Value != table[3].Value {
+		t.Errorf("expected the winner to gain and the others to tie, got %+v", table)
+	}
+}
+
+func TestApply_SingleTeamChangesNothing(t *testing.T) {
+	table := Table{}
+
+	table.Apply([]Participant{{PlayerID: 1, Team: 1, Rank: 1}, {PlayerID: 2, Team: 1, Rank: 1}})
+
+	if len(table) != 0 {
+		t.Errorf("expected a cooperative game not to be rated, got %+v", table)
+	}
+}
@@ -0,0 +1,104 @@
+// Package recommend ranks the games that fit an evening. Filtering on what is
+// possible at all (player count, time, age) is left to the query, this only
+// decides which of those fit best.
+package recommend
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+)
+
+// How much each part weighs in the score
+const (
+	playersWeight = 2.0
+	timeWeight    = 1.0
+	tagsWeight    = 1.5
+)
+
+// Scores every candidate, best first, and keeps the first limit ones
+func Rank(candidates []*models.Recommendation, criteria models.RecommendationCriteria, limit int) []*models.Recommendation {
+	for _, candidate := range candidates {
+		candidate.Score = math.Round(score(candidate, criteria)*100) / 100
+		candidate.Reason = reason(candidate, criteria)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return strings.ToLower(candidates[i].Name) < strings.ToLower(candidates[j].Name)
+	})
+
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates
+}
+
+func score(game *models.Recommendation, criteria models.RecommendationCriteria) float64 {
+	// A game at the edge of its range still plays, just not at its best
+	total := playersWeight * (0.5 + 0.5*playerComfort(&game.BoardGame, criteria.Players))
+
+	// Filling the evening beats a short filler
+	if criteria.Minutes > 0 && game.PlayTime > 0 {
+		total += timeWeight * math.Min(float64(game.PlayTime)/float64(criteria.Minutes), 1)
+	} else {
+		total += timeWeight / 2
+	}
+
+	if len(criteria.Tags) > 0 {
+		total += tagsWeight * float64(len(game.MatchedTags)) / float64(len(criteria.Tags))
+	}
+
+	return total
+}
+
+// 1 in the middle of the player range, 0 at either end.
+// Games made for an exact player count are always comfortable.
+func playerComfort(game *models.BoardGame, players int) float64 {
+	low, high := playerRange(game)
+	if high <= low {
+		return 1
+	}
+
+	half := float64(high-low) / 2
+	distance := math.Min(float64(players-low), float64(high-players))
+	return math.Max(0, math.Min(distance/half, 1))
+}
+
+// max_players is optional, without it the game plays with exactly min_players
+func playerRange(game *models.BoardGame) (int, int) {
+	if game.MaxPlayers < game.MinPlayers {
+		return game.MinPlayers, game.MinPlayers
+	}
+	return game.MinPlayers, game.MaxPlayers
+}
+
+// Short explanation, e.g. "fits 5 players, 45 min, ages 8+"
+func reason(game *models.Recommendation, criteria models.RecommendationCriteria) string {
+	players := fmt.Sprintf("fits %d players", criteria.Players)
+	if low, high := playerRange(&game.BoardGame); low < high {
+		switch criteria.Players {
+		case low:
+			players += " (lower limit)"
+		case high:
+			players += " (upper limit)"
+		}
+	}
+
+	parts := []string{players}
+	if game.PlayTime > 0 {
+		parts = append(parts, fmt.Sprintf("%d min", game.PlayTime))
+	}
+	if game.MinAge > 0 {
+		parts = append(parts, fmt.Sprintf("ages %d+", game.MinAge))
+	}
+	if len(game.MatchedTags) > 0 {
+		parts = append(parts, "matches "+strings.Join(game.MatchedTags, ", "))
+	}
+	return strings.Join(parts, ", ")
+}
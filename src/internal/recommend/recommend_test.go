@@ -0,0 +1,82 @@
+package recommend
+
+import (
+	"testing"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+)
+
+func candidate(name string, minPlayers, maxPlayers, playTime, minAge int, tags ...string) *models.Recommendation {
+	return &models.Recommendation{
+		BoardGame: models.BoardGame{
+			Name:       name,
+			MinPlayers: minPlayers,
+			MaxPlayers: maxPlayers,
+			PlayTime:   playTime,
+			MinAge:     minAge,
+		},
+		MatchedTags: tags,
+	}
+}
+
+func TestRank_PrefersComfortablePlayerCount(t *testing.T) {
+	// Arrange
+	edge := candidate("Edge", 2, 5, 45, 8)
+	middle := candidate("Middle", 3, 7, 45, 8)
+
+	// Act
+	ranked := Rank([]*models.Recommendation{edge, middle}, models.RecommendationCriteria{Players: 5, Minutes: 60}, 0)
+
+	// Assert
+	if ranked[0].Name != "Middle" {
+		t.Fatalf("expected the game with 5 players in the middle of its range first, got %s", ranked[0].Name)
+	}
+	if ranked[1].Reason != "fits 5 players (upper limit), 45 min, ages 8+" {
+		t.Errorf("unexpected reason %q", ranked[1].Reason)
+	}
+	if ranked[0].Reason != "fits 5 players, 45 min, ages 8+" {
+		t.Errorf("unexpected reason %q", ranked[0].Reason)
+	}
+}
+
+func TestRank_TagsAndLimit(t *testing.T) {
+	criteria := models.RecommendationCriteria{Players: 4, Tags: []string{"party", "cards"}}
+	games := []*models.Recommendation{
+		candidate("No tags", 2, 6, 30, 0),
+		candidate("Both", 2, 6, 30, 0, "party", "cards"),
+		candidate("One", 2, 6, 30, 0, "party"),
+	}
+
+	ranked := Rank(games, criteria, 2)
+
+	if len(ranked) != 2 || ranked[0].Name != "Both" || ranked[1].Name != "One" {
+		t.Fatalf("expected Both then One, got %+v", ranked)
+	}
+	if ranked[0].Reason != "fits 4 players, 30 min, matches party, cards" {
+		t.Errorf("unexpected reason %q", ranked[0].Reason)
+	}
+}
+
+func TestPlayerComfort(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max int
+		players  int
+		expected float64
+	}{
+		{"middle", 2, 4, 3, 1},
+		{"lower edge", 2, 4, 2, 0},
+		{"upper edge", 2, 4, 4, 0},
+		{"exact count", 4, 4, 4, 1},
+		{"no max players", 2, 0, 2, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := &models.BoardGame{MinPlayers: tt.min, MaxPlayers: tt.max}
+			if got := playerComfort(game, tt.players); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
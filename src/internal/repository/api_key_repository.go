@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// API keys of a user, and the user signing in with one
+type APIKeyRepository struct {
+	db *pgxpool.Pool
+}
+
+type APIKeyRepo interface {
+	Create(ctx context.Context, userID int64, key *models.APIKey, keyHash string) error
+	GetAll(ctx context.Context, userID int64) ([]*models.APIKey, error)
+	Revoke(ctx context.Context, userID int64, id int64) error
+	GetAPIKeyUser(ctx context.Context, keyHash string) (*models.User, *models.APIKey, error)
+}
+
+func NewAPIKeyRepository(db *pgxpool.Pool) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+const apiKeyColumns = `api_keys.id, api_keys.name, api_keys.key_hint, api_keys.scopes, api_keys.created_at,
+	api_keys.expires_at, api_keys.last_used_at, api_keys.revoked_at`
+
+func scanAPIKey(row pgx.Row, extra ...any) (*models.APIKey, error) {
+	var key models.APIKey
+	var scopes []string
+	dest := append([]any{&key.ID, &key.Name, &key.Hint, &scopes, &key.CreatedAt,
+		&key.ExpiresAt, &key.LastUsedAt, &key.RevokedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	for _, scope := range scopes {
+		key.Scopes = append(key.Scopes, models.APIKeyScope(scope))
+	}
+	return &key, nil
+}
+
+// Stores the key by its hash, fills in its id and creation time
+func (r *APIKeyRepository) Create(ctx context.Context, userID int64, key *models.APIKey, keyHash string) error {
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	query := `INSERT INTO api_keys (user_id, name, key_hash, key_hint, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+
+	err := r.db.QueryRow(ctx, query, userID, key.Name, keyHash, key.Hint, scopes, key.ExpiresAt).Scan(
+		&key.ID, &key.CreatedAt)
+	if err != nil {
+		return ErrQueryFailed
+	}
+
+	return nil
+}
+
+// Every key of the user, revoked ones included, newest first
+func (r *APIKeyRepository) GetAll(ctx context.Context, userID int64) ([]*models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	keys := []*models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, ErrQueryFailed
+		}
+		keys = append(keys, key)
+	}
+	if rows.Err() != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return keys, nil
+}
+
+// The key stops working right away. Revoking it again is not an error.
+func (r *APIKeyRepository) Revoke(ctx context.Context, userID int64, id int64) error {
+	commandTag, err := r.db.Exec(ctx, `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW())
+		WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return ErrQueryFailed
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// The user of a key that is neither revoked nor expired, and the key with
+// its scopes. Records it as used.
+func (r *APIKeyRepository) GetAPIKeyUser(ctx context.Context, keyHash string) (*models.User, *models.APIKey, error) {
+	query := `UPDATE api_keys SET last_used_at = NOW()
+		FROM users
+		WHERE api_keys.key_hash = $1 AND users.id = api_keys.user_id
+			AND api_keys.revoked_at IS NULL
+			AND (api_keys.expires_at IS NULL OR api_keys.expires_at > NOW())
+		RETURNING ` + apiKeyColumns + `, ` + userColumns
+
+	var user models.User
+	key, err := scanAPIKey(r.db.QueryRow(ctx, query, keyHash),
+		&user.ID, &user.Email, &user.DisplayName, &user.PasswordHash, &user.IsAdmin,
+		&user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, ErrAPIKeyNotFound
+		}
+		return nil, nil, ErrQueryFailed
+	}
+
+	return &user, key, nil
+}
@@ -0,0 +1,379 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Every table at once, for backups. Tables and columns are read from the
+// schema so new migrations are backed up without touching this file.
+type BackupRepository struct {
+	db *pgxpool.Pool
+}
+
+type BackupRepo interface {
+	Dump(ctx context.Context, table func(dump *models.TableDump) error) (*models.DatabaseDump, error)
+	CheckRestorable(ctx context.Context, migrationVersion int64) error
+	Restore(ctx context.Context, migrationVersion int64, tables []*models.TableDump) error
+}
+
+func NewBackupRepository(db *pgxpool.Pool) *BackupRepository {
+	return &BackupRepository{db: db}
+}
+
+// golang-migrate's bookkeeping, the restoring database has its own
+const migrationsTable = "schema_migrations"
+
+// Reads every table from one snapshot, in an order that restores without
+// breaking foreign keys, and hands each one to table as JSON lines.
+// Returns ErrLegacyImages while images are still stored in the database.
+func (r *BackupRepository) Dump(ctx context.Context, table func(dump *models.TableDump) error) (*models.DatabaseDump, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer tx.Rollback(ctx) // Read only, nothing to commit
+
+	var legacy bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM board_game_images WHERE image_key IS NULL)`).Scan(&legacy)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	if legacy {
+		return nil, ErrLegacyImages
+	}
+
+	dump := &models.DatabaseDump{}
+	if dump.MigrationVersion, err = schemaVersion(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	tables, err := restoreOrder(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range tables {
+		tableDump, err := dumpTable(ctx, tx, name)
+		if err != nil {
+			return nil, err
+		}
+		if err := table(tableDump); err != nil {
+			return nil, err
+		}
+	}
+
+	// Every blob the rows point at
+	rows, err := tx.Query(ctx, `SELECT image_key FROM board_game_images
+		UNION SELECT thumbnail_key FROM board_game_images WHERE thumbnail_key IS NOT NULL
+		UNION SELECT storage_key FROM board_game_image_variants
+		UNION SELECT avatar_key FROM players WHERE avatar_key IS NOT NULL
+		ORDER BY 1`)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	dump.BlobKeys = []string{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, ErrQueryFailed
+		}
+		dump.BlobKeys = append(dump.BlobKeys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return dump, nil
+}
+
+func dumpTable(ctx context.Context, tx pgx.Tx, name string) (*models.TableDump, error) {
+	columns, err := tableColumns(ctx, tx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, fmt.Sprintf(`SELECT row_to_json(r) FROM (SELECT %s FROM %s ORDER BY 1) r`,
+		columns, pgx.Identifier{name}.Sanitize()))
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	dump := &models.TableDump{Name: name}
+	var data bytes.Buffer
+	for rows.Next() {
+		var row []byte
+		if err := rows.Scan(&row); err != nil {
+			return nil, ErrQueryFailed
+		}
+		data.Write(row)
+		data.WriteByte('\n')
+		dump.Rows++
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	dump.Data = data.Bytes()
+	return dump, nil
+}
+
+// Whether a backup made at migrationVersion can be restored here:
+// the schema must be at the same version and every table empty
+func (r *BackupRepository) CheckRestorable(ctx context.Context, migrationVersion int64) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return ErrQueryFailed
+	}
+	defer tx.Rollback(ctx) // Read only, nothing to commit
+
+	return checkRestorable(ctx, tx, migrationVersion)
+}
+
+func checkRestorable(ctx context.Context, tx pgx.Tx, migrationVersion int64) error {
+	version, err := schemaVersion(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if version != migrationVersion {
+		return fmt.Errorf("%w: backup is at %d, database at %d", ErrBackupVersionMismatch, migrationVersion, version)
+	}
+
+	tables, err := restoreOrder(ctx, tx)
+	if err != nil {
+		return err
+	}
+	for _, name := range tables {
+		var hasRows bool
+		err := tx.QueryRow(ctx, fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s)`, pgx.Identifier{name}.Sanitize())).Scan(&hasRows)
+		if err != nil {
+			return ErrQueryFailed
+		}
+		if hasRows {
+			return fmt.Errorf("%w: %s has rows", ErrDatabaseNotEmpty, name)
+		}
+	}
+
+	return nil
+}
+
+// Inserts the tables, parents first, all or nothing, and moves the id
+// sequences past the restored ids so new rows do not collide
+func (r *BackupRepository) Restore(ctx context.Context, migrationVersion int64, tables []*models.TableDump) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return ErrQueryFailed
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	if err := checkRestorable(ctx, tx, migrationVersion); err != nil {
+		return err
+	}
+
+	order, err := restoreOrder(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	byName := map[string]*models.TableDump{}
+	for _, table := range tables {
+		// Names come from the archive, only tables of this schema are written
+		if !slices.Contains(order, table.Name) {
+			return fmt.Errorf("%w: unknown table %s", ErrBackupVersionMismatch, table.Name)
+		}
+		byName[table.Name] = table
+	}
+
+	for _, name := range order {
+		table := byName[name]
+		if table == nil || table.Rows == 0 {
+			continue
+		}
+
+		columns, err := tableColumns(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+
+		lines := bytes.Split(bytes.TrimSpace(table.Data), []byte("\n"))
+		rows := append(append([]byte("["), bytes.Join(lines, []byte(","))...), ']')
+		identifier := pgx.Identifier{name}.Sanitize()
+		_, err = tx.Exec(ctx, fmt.Sprintf(`INSERT INTO %s (%s) SELECT %s FROM json_populate_recordset(NULL::%s, $1::json)`,
+			identifier, columns, columns, identifier), string(rows))
+		if err != nil {
+			return ErrQueryFailed
+		}
+	}
+
+	_, err = tx.Exec(ctx, `DO $$
+		DECLARE serial RECORD;
+		BEGIN
+			FOR serial IN
+				SELECT table_name, column_name, pg_get_serial_sequence(quote_ident(table_name), column_name) AS sequence
+				FROM information_schema.columns
+				WHERE table_schema = current_schema() AND column_default LIKE 'nextval(%'
+			LOOP
+				EXECUTE format('SELECT setval(%L, COALESCE(MAX(%I), 1), MAX(%I) IS NOT NULL) FROM %I',
+					serial.sequence, serial.column_name, serial.column_name, serial.table_name);
+			END LOOP;
+		END $$`)
+	if err != nil {
+		return ErrQueryFailed
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ErrQueryFailed
+	}
+
+	return nil
+}
+
+func schemaVersion(ctx context.Context, tx pgx.Tx) (int64, error) {
+	var version int64
+	var dirty bool
+	err := tx.QueryRow(ctx, `SELECT version, dirty FROM `+migrationsTable).Scan(&version, &dirty)
+	if err != nil {
+		return 0, ErrQueryFailed
+	}
+	if dirty {
+		return 0, fmt.Errorf("%w: migration %d is dirty", ErrBackupVersionMismatch, version)
+	}
+	return version, nil
+}
+
+// Tables of the schema, every table after the tables it references
+func restoreOrder(ctx context.Context, tx pgx.Tx) ([]string, error) {
+	rows, err := tx.Query(ctx, `SELECT table_name FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_type = 'BASE TABLE' AND table_name <> $1
+		ORDER BY table_name`, migrationsTable)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, ErrQueryFailed
+		}
+		tables = append(tables, table)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	references, err := tableReferences(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return dependencyOrder(tables, references), nil
+}
+
+// Tables each table references through foreign keys, itself left out
+func tableReferences(ctx context.Context, tx pgx.Tx) (map[string][]string, error) {
+	rows, err := tx.Query(ctx, `SELECT child.relname, parent.relname
+		FROM pg_constraint c
+		JOIN pg_class child ON child.oid = c.conrelid
+		JOIN pg_class parent ON parent.oid = c.confrelid
+		WHERE c.contype = 'f' AND child.relnamespace = (SELECT oid FROM pg_namespace WHERE nspname = current_schema())`)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	references := map[string][]string{}
+	for rows.Next() {
+		var child, parent string
+		if err := rows.Scan(&child, &parent); err != nil {
+			return nil, ErrQueryFailed
+		}
+		if child != parent {
+			references[child] = append(references[child], parent)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return references, nil
+}
+
+// Sorted tables, each one moved after the tables it references
+func dependencyOrder(tables []string, references map[string][]string) []string {
+	ordered := make([]string, 0, len(tables))
+	placed := map[string]bool{}
+	for len(ordered) < len(tables) {
+		progress := false
+		for _, table := range tables {
+			if placed[table] {
+				continue
+			}
+			ready := true
+			for _, parent := range references[table] {
+				if !placed[parent] && slices.Contains(tables, parent) {
+					ready = false
+				}
+			}
+			if ready {
+				ordered = append(ordered, table)
+				placed[table] = true
+				progress = true
+			}
+		}
+		// A reference loop, left for the database to complain about
+		if !progress {
+			for _, table := range tables {
+				if !placed[table] {
+					ordered = append(ordered, table)
+					placed[table] = true
+				}
+			}
+		}
+	}
+	return ordered
+}
+
+// Column list of the table without generated columns, quoted for SQL
+func tableColumns(ctx context.Context, tx pgx.Tx, table string) (string, error) {
+	rows, err := tx.Query(ctx, `SELECT column_name FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1 AND is_generated = 'NEVER'
+		ORDER BY ordinal_position`, table)
+	if err != nil {
+		return "", ErrQueryFailed
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return "", ErrQueryFailed
+		}
+		columns = append(columns, pgx.Identifier{column}.Sanitize())
+	}
+
+	if err := rows.Err(); err != nil {
+		return "", ErrQueryFailed
+	}
+	if len(columns) == 0 {
+		return "", ErrQueryFailed
+	}
+
+	return strings.Join(columns, ", "), nil
+}
@@ -2,61 +2,267 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/helpers"
 	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/storage"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// Image rows only hold metadata and blob keys, the bytes live in the BlobStore.
+// Rows uploaded before the blob store still have their BYTEA columns until
+// MigrateLegacyImages moves them.
 type BoardGameImageRepository struct {
-	db *pgxpool.Pool
+	db    *pgxpool.Pool
+	store storage.BlobStore
 }
 
+// Like BoardGameRepo, only the images of games in userID's households are
+// found and only their owners and editors change them
 type BoardGameImageRepo interface {
-	SaveImage(ctx context.Context, image *models.BoardGameImage) error
-	GetAllImagesForBoardGame(ctx context.Context, boardGameId int64, imageType string) ([]*models.BoardGameImage, error)
-	GetCoverThumbnail(ctx context.Context, boardGameId int64) (*models.BoardGameImage, error)
-	DeleteImage(ctx context.Context, id int64) error
+	SaveImage(ctx context.Context, userID int64, image *models.BoardGameImage, coverMode CoverMode) (int64, error)
+	GetAllImagesForBoardGame(ctx context.Context, userID int64, boardGameId int64, imageType string) ([]*models.BoardGameImage, error)
+	ListImages(ctx context.Context, userID int64, boardGameId int64) ([]*models.BoardGameImage, error)
+	GetImageByID(ctx context.Context, userID int64, id int64) (*models.BoardGameImage, error)
+	GetThumbnailByID(ctx context.Context, userID int64, id int64) (*models.BoardGameImage, error)
+	GetCoverThumbnail(ctx context.Context, userID int64, boardGameId int64) (*models.BoardGameImage, error)
+	ListVariants(ctx context.Context, userID int64, imageID int64) ([]models.ImageVariant, error)
+	GetVariant(ctx context.Context, userID int64, imageID int64, width int, mimeType string) (*models.ImageVariant, error)
+	ReorderImages(ctx context.Context, userID int64, boardGameId int64, imageIDs []int64) error
+	DeleteImage(ctx context.Context, userID int64, id int64) error
 }
 
-func NewBoardGameImageRepository(db *pgxpool.Pool) *BoardGameImageRepository {
-	return &BoardGameImageRepository{db: db}
+// What SaveImage does with the current cover when a new one is uploaded
+type CoverMode string
+
+const (
+	CoverModeReplace CoverMode = "replace" // Delete the old cover
+	CoverModeDemote  CoverMode = "demote"  // Keep it as the last gameplay image
+	CoverModeReject  CoverMode = "reject"  // Fail with ErrCoverExists
+)
+
+func NewBoardGameImageRepository(db *pgxpool.Pool, store storage.BlobStore) *BoardGameImageRepository {
+	return &BoardGameImageRepository{db: db, store: store}
+}
+
+// Condition keeping the rows whose board game is in a household of the user
+// passed as parameter $userArg
+func onShelf(column string, userArg int) string {
+	return fmt.Sprintf(`%s IN (SELECT id FROM board_games WHERE %s)`,
+		column, memberOf("household_id", fmt.Sprintf("$%d", userArg)))
 }
 
-func (r *BoardGameImageRepository) SaveImage(ctx context.Context, image *models.BoardGameImage) error {
+// Same as onShelf, for the games the user can change
+func onEditableShelf(column string, userArg int) string {
+	return fmt.Sprintf(`%s IN (SELECT id FROM board_games WHERE %s)`,
+		column, editorOf("household_id", fmt.Sprintf("$%d", userArg)))
+}
+
+// Saves the image and its variants. For a cover, coverMode says what happens to the
+// current one; the id of that previous cover is returned, 0 when there was none.
+func (r *BoardGameImageRepository) SaveImage(ctx context.Context, userID int64, image *models.BoardGameImage, coverMode CoverMode) (int64, error) {
+	// 1. Store the bytes first, a row never points to a missing blob
+	keys, err := r.putBlobs(ctx, image.BoardGameID, image.ImageData, image.ImageMimeType, image.ThumbnailData)
+	if err != nil {
+		return 0, err
+	}
+
+	variantKeys, err := r.putVariantBlobs(ctx, keys.base, image.Variants)
+	if err != nil {
+		r.deleteBlobs(ctx, keys.image, keys.thumbnail)
+		return 0, err
+	}
+
+	previousCoverID, removedKeys, err := r.insertImage(ctx, userID, image, coverMode, keys, variantKeys)
+	if err != nil {
+		r.deleteBlobs(ctx, append(variantKeys, keys.image, keys.thumbnail)...)
+		return 0, err
+	}
+
+	// The replaced cover's blobs go once nothing points to them anymore
+	r.deleteBlobs(ctx, removedKeys...)
+	return previousCoverID, nil
+}
+
+// The image row and its variant rows go in together, along with replacing or
+// demoting the current cover. Returns the previous cover id and the blob keys
+// of a replaced cover.
+func (r *BoardGameImageRepository) insertImage(ctx context.Context, userID int64, image *models.BoardGameImage, coverMode CoverMode, keys blobKeys, variantKeys []*string) (int64, []*string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	// The game can't change hands or go away while its image goes in
+	var boardGameID int64
+	err = tx.QueryRow(ctx, `SELECT id FROM board_games WHERE id = $1 AND `+editorOf("household_id", "$2")+` FOR SHARE`,
+		image.BoardGameID, userID).Scan(&boardGameID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil, boardGameDenied(ctx, tx, userID, image.BoardGameID)
+		}
+		return 0, nil, err
+	}
+
+	var previousCoverID int64
+	var removedKeys []*string
+	if image.ImageType == "cover" {
+		// Lock the current cover so two uploads can't both replace it
+		err := tx.QueryRow(ctx, `SELECT id FROM board_game_images
+			WHERE board_game_id = $1 AND image_type = 'cover' FOR UPDATE`, image.BoardGameID).Scan(&previousCoverID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil, err
+		}
+	}
+
+	if previousCoverID != 0 {
+		switch coverMode {
+		case CoverModeReplace:
+			removedKeys, err = deleteImageRows(ctx, tx, `id = $1`, previousCoverID)
+		case CoverModeDemote:
+			_, err = tx.Exec(ctx, `UPDATE board_game_images
+				SET image_type = 'gameplay',
+					display_order = (SELECT COALESCE(MAX(display_order) + 1, 0) FROM board_game_images
+						WHERE board_game_id = $2 AND image_type = 'gameplay')
+				WHERE id = $1`, previousCoverID, image.BoardGameID)
+		default:
+			err = ErrCoverExists
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	// 2. New images go after the existing ones of the same type
 	query := `INSERT into board_game_images
-	(board_game_id, image_data, image_mime_type, thumbnail_data, image_type, display_order, uploaded_at)
-	VALUES ($1, $2, $3, $4, $5, $6, NOW()) RETURNING id, uploaded_at`
+	(board_game_id, image_key, image_mime_type, thumbnail_key, image_type, content_hash, display_order, uploaded_at)
+	VALUES ($1, $2, $3, $4, $5, $6,
+		(SELECT COALESCE(MAX(display_order) + 1, 0) FROM board_game_images WHERE board_game_id = $1 AND image_type = $5),
+		NOW())
+	RETURNING id, display_order, uploaded_at`
 
-	err := r.db.QueryRow(ctx, query,
+	image.ContentHash = helpers.ContentHash(image.ImageData)
+	err = tx.QueryRow(ctx, query,
 		image.BoardGameID,
-		image.ImageData,
+		keys.image,
 		image.ImageMimeType,
-		image.ThumbnailData,
+		keys.thumbnail,
 		image.ImageType,
-		image.DisplayOrder,
-	).Scan(&image.ID, &image.UploadedAt)
+		image.ContentHash,
+	).Scan(&image.ID, &image.DisplayOrder, &image.UploadedAt)
+	if err != nil {
+		// Another cover was inserted after our check
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_one_cover_per_game" {
+			return 0, nil, ErrCoverExists
+		}
+		return 0, nil, err
+	}
+
+	// 3. One row per variant, pointing to the blob stored above
+	for i, variant := range image.Variants {
+		_, err = tx.Exec(ctx, `INSERT INTO board_game_image_variants
+			(image_id, width, height, mime_type, storage_key, byte_size)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			image.ID, variant.Width, variant.Height, variant.MimeType, *variantKeys[i], len(variant.Data))
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, nil, err
+	}
 
-	return err
+	return previousCoverID, removedKeys, nil
 }
 
-func (r *BoardGameImageRepository) GetAllImagesForBoardGame(ctx context.Context, boardGameId int64, imageType string) ([]*models.BoardGameImage, error) {
-	query := `SELECT id, board_game_id, image_data, image_mime_type, thumbnail_data, image_type, display_order, uploaded_at
-			FROM board_game_images`
+type blobKeys struct {
+	base      string
+	image     *string
+	thumbnail *string
+}
 
-	if imageType != "" {
-		query += ` WHERE board_game_id = $1 AND image_type = $2 ORDER BY display_order ASC`
-	} else {
-		query += ` WHERE board_game_id = $1 ORDER BY display_order ASC`
+func (r *BoardGameImageRepository) putBlobs(ctx context.Context, boardGameId int64, imageData []byte, mimeType string, thumbnailData []byte) (blobKeys, error) {
+	base, err := storage.NewKey(fmt.Sprintf("boardgames/%d", boardGameId))
+	if err != nil {
+		return blobKeys{}, err
 	}
 
+	imageKey := base + "/original"
+	if err := r.store.Put(ctx, imageKey, imageData, mimeType); err != nil {
+		return blobKeys{}, err
+	}
+	keys := blobKeys{base: base, image: &imageKey}
+
+	if thumbnailData != nil {
+		thumbnailKey := base + "/thumbnail"
+		if err := r.store.Put(ctx, thumbnailKey, thumbnailData, helpers.ThumbnailMimeType(mimeType)); err != nil {
+			r.deleteBlobs(ctx, keys.image)
+			return blobKeys{}, err
+		}
+		keys.thumbnail = &thumbnailKey
+	}
+
+	return keys, nil
+}
+
+// Variants live next to the original, e.g. boardgames/1/<hex>/800.webp
+func (r *BoardGameImageRepository) putVariantBlobs(ctx context.Context, base string, variants []models.ImageVariant) ([]*string, error) {
+	var keys []*string
+	for _, variant := range variants {
+		key := fmt.Sprintf("%s/%d.%s", base, variant.Width, strings.TrimPrefix(variant.MimeType, "image/"))
+		if err := r.store.Put(ctx, key, variant.Data, variant.MimeType); err != nil {
+			r.deleteBlobs(ctx, keys...)
+			return nil, err
+		}
+		keys = append(keys, &key)
+	}
+	return keys, nil
+}
+
+func (r *BoardGameImageRepository) deleteBlobs(ctx context.Context, keys ...*string) {
+	deleteBlobs(ctx, r.store, keys...)
+}
+
+// Best effort, an orphaned blob only costs disk space
+func deleteBlobs(ctx context.Context, store storage.BlobStore, keys ...*string) {
+	for _, key := range keys {
+		if key != nil {
+			_ = store.Delete(ctx, *key)
+		}
+	}
+}
+
+// loadBlob reads from the blob store, or from the BYTEA column of rows not migrated yet
+func (r *BoardGameImageRepository) loadBlob(ctx context.Context, key *string, legacyData []byte) ([]byte, error) {
+	if key == nil {
+		return legacyData, nil
+	}
+	return r.store.Get(ctx, *key)
+}
+
+func (r *BoardGameImageRepository) GetAllImagesForBoardGame(ctx context.Context, userID int64, boardGameId int64, imageType string) ([]*models.BoardGameImage, error) {
+	query := `SELECT id, board_game_id, image_key, image_data, image_mime_type, thumbnail_key, thumbnail_data,
+				image_type, COALESCE(content_hash, ''), display_order, uploaded_at
+			FROM board_game_images
+			WHERE board_game_id = $1 AND ` + onShelf("board_game_id", 2)
+
 	var rows pgx.Rows
 	var err error
 
 	if imageType != "" {
-		rows, err = r.db.Query(ctx, query, boardGameId, imageType)
+		query += ` AND image_type = $3 ORDER BY display_order ASC`
+		rows, err = r.db.Query(ctx, query, boardGameId, userID, imageType)
 	} else {
-		rows, err = r.db.Query(ctx, query, boardGameId)
+		query += ` ORDER BY display_order ASC`
+		rows, err = r.db.Query(ctx, query, boardGameId, userID)
 	}
 
 	if err != nil {
@@ -64,40 +270,236 @@ func (r *BoardGameImageRepository) GetAllImagesForBoardGame(ctx context.Context,
 	}
 	defer rows.Close()
 
-	var images []*models.BoardGameImage
+	type storedImage struct {
+		image        *models.BoardGameImage
+		imageKey     *string
+		thumbnailKey *string
+	}
+
+	var stored []storedImage
 	for rows.Next() {
 		var image models.BoardGameImage
-		err := rows.Scan(&image.ID, &image.BoardGameID, &image.ImageData, &image.ImageMimeType,
-			&image.ThumbnailData, &image.ImageType, &image.DisplayOrder, &image.UploadedAt)
+		var imageKey, thumbnailKey *string
+		err := rows.Scan(&image.ID, &image.BoardGameID, &imageKey, &image.ImageData, &image.ImageMimeType,
+			&thumbnailKey, &image.ThumbnailData, &image.ImageType, &image.ContentHash, &image.DisplayOrder, &image.UploadedAt)
 		if err != nil {
 			return nil, err
 		}
-		images = append(images, &image)
+		stored = append(stored, storedImage{&image, imageKey, thumbnailKey})
 	}
 
 	// Check for errors from iterating over rows
 	if err = rows.Err(); err != nil {
 		return nil, err
 	}
+	rows.Close()
+
+	// Load the bytes once the result set is released
+	var images []*models.BoardGameImage
+	for _, s := range stored {
+		if s.image.ImageData, err = r.loadBlob(ctx, s.imageKey, s.image.ImageData); err != nil {
+			return nil, err
+		}
+		if s.image.ThumbnailData, err = r.loadBlob(ctx, s.thumbnailKey, s.image.ThumbnailData); err != nil {
+			return nil, err
+		}
+		images = append(images, s.image)
+	}
 
 	return images, nil
 }
 
-// For list views - only get thumbnails
-func (r *BoardGameImageRepository) GetCoverThumbnail(ctx context.Context, boardGameId int64) (*models.BoardGameImage, error) {
-	query := `SELECT id, board_game_id, thumbnail_data, image_mime_type, image_type
+// Metadata only, no image bytes. Cover first, then gameplay images in display order.
+func (r *BoardGameImageRepository) ListImages(ctx context.Context, userID int64, boardGameId int64) ([]*models.BoardGameImage, error) {
+	query := `SELECT id, board_game_id, image_mime_type, image_type, COALESCE(content_hash, ''), display_order, uploaded_at
+			FROM board_game_images
+			WHERE board_game_id = $1 AND ` + onShelf("board_game_id", 2) + `
+			ORDER BY image_type = 'cover' DESC, display_order ASC, id ASC`
+
+	rows, err := r.db.Query(ctx, query, boardGameId, userID)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	images := []*models.BoardGameImage{}
+	for rows.Next() {
+		var image models.BoardGameImage
+		err := rows.Scan(&image.ID, &image.BoardGameID, &image.ImageMimeType,
+			&image.ImageType, &image.ContentHash, &image.DisplayOrder, &image.UploadedAt)
+		if err != nil {
+			return nil, ErrQueryFailed
+		}
+		version := models.ImageVersion(image.ContentHash)
+		image.URL = models.ImageURL(image.ID, version)
+		image.ThumbnailURL = models.ImageThumbnailURL(image.ID, version)
+		images = append(images, &image)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+	rows.Close()
+
+	variants, err := r.listVariants(ctx, `WHERE i.board_game_id = $1 AND `+onShelf("i.board_game_id", 2), boardGameId, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, image := range images {
+		image.Variants = variants[image.ID]
+		image.SrcSet = models.ImageSrcSet(image.ID, models.ImageVersion(image.ContentHash), image.Variants)
+	}
+
+	return images, nil
+}
+
+// Variant metadata of one image, smallest first
+func (r *BoardGameImageRepository) ListVariants(ctx context.Context, userID int64, imageID int64) ([]models.ImageVariant, error) {
+	variants, err := r.listVariants(ctx, `WHERE v.image_id = $1 AND `+onShelf("i.board_game_id", 2), imageID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return variants[imageID], nil
+}
+
+// Variant metadata grouped by image id
+func (r *BoardGameImageRepository) listVariants(ctx context.Context, where string, args ...any) (map[int64][]models.ImageVariant, error) {
+	query := `SELECT v.image_id, v.width, v.height, v.mime_type
+			FROM board_game_image_variants v
+			JOIN board_game_images i ON i.id = v.image_id ` + where + `
+			ORDER BY v.width ASC, v.mime_type ASC`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	variants := map[int64][]models.ImageVariant{}
+	for rows.Next() {
+		var imageID int64
+		var variant models.ImageVariant
+		if err := rows.Scan(&imageID, &variant.Width, &variant.Height, &variant.MimeType); err != nil {
+			return nil, ErrQueryFailed
+		}
+		variants[imageID] = append(variants[imageID], variant)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return variants, nil
+}
+
+// One variant with its bytes
+func (r *BoardGameImageRepository) GetVariant(ctx context.Context, userID int64, imageID int64, width int, mimeType string) (*models.ImageVariant, error) {
+	query := `SELECT v.width, v.height, v.mime_type, v.storage_key, COALESCE(i.content_hash, ''), i.uploaded_at
+			FROM board_game_image_variants v
+			JOIN board_game_images i ON i.id = v.image_id
+			WHERE v.image_id = $1 AND v.width = $2 AND v.mime_type = $3 AND ` + onShelf("i.board_game_id", 4)
+
+	var variant models.ImageVariant
+	var key string
+	err := r.db.QueryRow(ctx, query, imageID, width, mimeType, userID).Scan(&variant.Width, &variant.Height, &variant.MimeType, &key,
+		&variant.ContentHash, &variant.UploadedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrImageNotFound
+		}
+		return nil, ErrQueryFailed
+	}
+
+	if variant.Data, err = r.store.Get(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return &variant, nil
+}
+
+// Full size image
+func (r *BoardGameImageRepository) GetImageByID(ctx context.Context, userID int64, id int64) (*models.BoardGameImage, error) {
+	query := `SELECT id, board_game_id, image_key, image_data, image_mime_type, image_type,
+				COALESCE(content_hash, ''), display_order, uploaded_at
 			FROM board_game_images
-			WHERE board_game_id = $1 AND image_type = 'cover'`
+			WHERE id = $1 AND ` + onShelf("board_game_id", 2)
 
 	var image models.BoardGameImage
-	err := r.db.QueryRow(ctx, query, boardGameId).Scan(
+	var imageKey *string
+	err := r.db.QueryRow(ctx, query, id, userID).Scan(
 		&image.ID,
 		&image.BoardGameID,
-		&image.ThumbnailData,
+		&imageKey,
+		&image.ImageData,
 		&image.ImageMimeType,
 		&image.ImageType,
+		&image.ContentHash,
+		&image.DisplayOrder,
+		&image.UploadedAt,
 	)
 
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrImageNotFound
+		}
+		return nil, ErrQueryFailed
+	}
+
+	if image.ImageData, err = r.loadBlob(ctx, imageKey, image.ImageData); err != nil {
+		return nil, err
+	}
+
+	return &image, nil
+}
+
+// Thumbnail only, falls back to the original for images stored without one
+func (r *BoardGameImageRepository) GetThumbnailByID(ctx context.Context, userID int64, id int64) (*models.BoardGameImage, error) {
+	return r.getThumbnail(ctx, `WHERE id = $1 AND `+onShelf("board_game_id", 2), id, userID)
+}
+
+// For list views - only get thumbnails
+func (r *BoardGameImageRepository) GetCoverThumbnail(ctx context.Context, userID int64, boardGameId int64) (*models.BoardGameImage, error) {
+	return r.getThumbnail(ctx, `WHERE board_game_id = $1 AND image_type = 'cover' AND `+onShelf("board_game_id", 2),
+		boardGameId, userID)
+}
+
+func (r *BoardGameImageRepository) getThumbnail(ctx context.Context, where string, args ...any) (*models.BoardGameImage, error) {
+	// The original is only read when there is no thumbnail at all
+	query := `SELECT id, board_game_id, image_mime_type, image_type, COALESCE(content_hash, ''), display_order, uploaded_at,
+				thumbnail_key, thumbnail_data, image_key,
+				CASE WHEN thumbnail_key IS NULL AND thumbnail_data IS NULL THEN image_data END
+			FROM board_game_images ` + where
+
+	var image models.BoardGameImage
+	var thumbnailKey, imageKey *string
+	var originalData []byte
+	err := r.db.QueryRow(ctx, query, args...).Scan(
+		&image.ID,
+		&image.BoardGameID,
+		&image.ImageMimeType,
+		&image.ImageType,
+		&image.ContentHash,
+		&image.DisplayOrder,
+		&image.UploadedAt,
+		&thumbnailKey,
+		&image.ThumbnailData,
+		&imageKey,
+		&originalData,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrImageNotFound
+		}
+		return nil, ErrQueryFailed
+	}
+
+	if thumbnailKey != nil || image.ThumbnailData != nil {
+		image.ThumbnailData, err = r.loadBlob(ctx, thumbnailKey, image.ThumbnailData)
+		image.ImageMimeType = helpers.ThumbnailMimeType(image.ImageMimeType)
+	} else {
+		image.ThumbnailData, err = r.loadBlob(ctx, imageKey, originalData)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -105,9 +507,230 @@ func (r *BoardGameImageRepository) GetCoverThumbnail(ctx context.Context, boardG
 	return &image, nil
 }
 
-func (r *BoardGameImageRepository) DeleteImage(ctx context.Context, id int64) error {
-	query := `DELETE FROM board_game_images WHERE id = $1`
+// Sets display_order of the gameplay images to their position in imageIDs
+func (r *BoardGameImageRepository) ReorderImages(ctx context.Context, userID int64, boardGameId int64, imageIDs []int64) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return ErrQueryFailed
+	}
+	defer tx.Rollback(ctx) // No-op once committed
 
-	_, err := r.db.Exec(ctx, query, id)
-	return err
+	role, err := boardGameRole(ctx, tx, userID, boardGameId)
+	if err != nil {
+		return err
+	}
+	if !role.CanEdit() {
+		return ErrForbidden
+	}
+
+	// Lock the rows so a concurrent upload or delete can't change the set
+	rows, err := tx.Query(ctx, `SELECT id FROM board_game_images
+		WHERE board_game_id = $1 AND image_type = 'gameplay' FOR UPDATE`, boardGameId)
+	if err != nil {
+		return ErrQueryFailed
+	}
+
+	current := map[int64]bool{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return ErrQueryFailed
+		}
+		current[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return ErrQueryFailed
+	}
+
+	if len(current) != len(imageIDs) {
+		return ErrInvalidImageOrder
+	}
+	seen := map[int64]bool{}
+	for _, id := range imageIDs {
+		if !current[id] || seen[id] {
+			return ErrInvalidImageOrder
+		}
+		seen[id] = true
+	}
+
+	for order, id := range imageIDs {
+		if _, err := tx.Exec(ctx, `UPDATE board_game_images SET display_order = $1 WHERE id = $2`, order, id); err != nil {
+			return ErrQueryFailed
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ErrQueryFailed
+	}
+
+	return nil
+}
+
+func (r *BoardGameImageRepository) DeleteImage(ctx context.Context, userID int64, id int64) error {
+	keys, err := r.deleteImages(ctx, `id = $1 AND `+onEditableShelf("board_game_id", 2), id, userID)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return r.imageDenied(ctx, userID, id)
+	}
+
+	r.deleteBlobs(ctx, keys...)
+	return nil
+}
+
+// Why deleting an image matched nothing: ErrForbidden when the user can see
+// it, ErrImageNotFound otherwise
+func (r *BoardGameImageRepository) imageDenied(ctx context.Context, userID int64, id int64) error {
+	var visible bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM board_game_images
+		WHERE id = $1 AND `+onShelf("board_game_id", 2)+`)`, id, userID).Scan(&visible)
+	if err != nil {
+		return ErrQueryFailed
+	}
+	if visible {
+		return ErrForbidden
+	}
+	return ErrImageNotFound
+}
+
+// Deletes the matching image rows and their variants, returns the blob keys to remove.
+// Every deleted image returns at least its original key, even a nil one for legacy rows.
+func (r *BoardGameImageRepository) deleteImages(ctx context.Context, where string, args ...any) ([]*string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	keys, err := deleteImageRows(ctx, tx, where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return keys, nil
+}
+
+// Same as deleteImages inside a transaction the caller commits
+func deleteImageRows(ctx context.Context, tx pgx.Tx, where string, args ...any) ([]*string, error) {
+	// Variants first, the cascade would drop their keys
+	variantKeys, err := collectKeys(tx.Query(ctx, `DELETE FROM board_game_image_variants
+		WHERE image_id IN (SELECT id FROM board_game_images WHERE `+where+`)
+		RETURNING storage_key, NULL::text`, args...))
+	if err != nil {
+		return nil, err
+	}
+
+	imageKeys, err := collectKeys(tx.Query(ctx, `DELETE FROM board_game_images WHERE `+where+`
+		RETURNING image_key, thumbnail_key`, args...))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(imageKeys, variantKeys...), nil
+}
+
+// Reads rows of two nullable keys
+func collectKeys(rows pgx.Rows, err error) ([]*string, error) {
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	var keys []*string
+	for rows.Next() {
+		var first, second *string
+		if err := rows.Scan(&first, &second); err != nil {
+			return nil, ErrQueryFailed
+		}
+		keys = append(keys, first, second)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return keys, nil
+}
+
+// MigrateLegacyImages moves the BYTEA data of old rows into the blob store,
+// one image at a time to keep memory flat. Safe to run again after a failure.
+func (r *BoardGameImageRepository) MigrateLegacyImages(ctx context.Context) (int, error) {
+	migrated := 0
+
+	for {
+		var id, boardGameId int64
+		var imageData, thumbnailData []byte
+		var mimeType string
+
+		err := r.db.QueryRow(ctx, `SELECT id, board_game_id, image_data, thumbnail_data, image_mime_type
+			FROM board_game_images WHERE image_key IS NULL ORDER BY id LIMIT 1`).
+			Scan(&id, &boardGameId, &imageData, &thumbnailData, &mimeType)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return migrated, nil
+		}
+		if err != nil {
+			return migrated, err
+		}
+
+		keys, err := r.putBlobs(ctx, boardGameId, imageData, mimeType, thumbnailData)
+		if err != nil {
+			return migrated, fmt.Errorf("image %d: %w", id, err)
+		}
+
+		commandTag, err := r.db.Exec(ctx, `UPDATE board_game_images
+			SET image_key = $1, thumbnail_key = $2, image_data = NULL, thumbnail_data = NULL, content_hash = $4
+			WHERE id = $3 AND image_key IS NULL`, keys.image, keys.thumbnail, id, helpers.ContentHash(imageData))
+		if err != nil {
+			r.deleteBlobs(ctx, keys.image, keys.thumbnail)
+			return migrated, fmt.Errorf("image %d: %w", id, err)
+		}
+		// Deleted or migrated by someone else meanwhile, nothing points to the new blobs
+		if commandTag.RowsAffected() == 0 {
+			r.deleteBlobs(ctx, keys.image, keys.thumbnail)
+			continue
+		}
+
+		migrated++
+	}
+}
+
+// BackfillContentHashes hashes the images stored before content_hash existed,
+// one at a time like MigrateLegacyImages
+func (r *BoardGameImageRepository) BackfillContentHashes(ctx context.Context) (int, error) {
+	hashed := 0
+
+	for {
+		var id int64
+		var imageKey *string
+		var imageData []byte
+
+		err := r.db.QueryRow(ctx, `SELECT id, image_key, image_data
+			FROM board_game_images WHERE content_hash IS NULL ORDER BY id LIMIT 1`).
+			Scan(&id, &imageKey, &imageData)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return hashed, nil
+		}
+		if err != nil {
+			return hashed, err
+		}
+
+		data, err := r.loadBlob(ctx, imageKey, imageData)
+		if err != nil {
+			return hashed, fmt.Errorf("image %d: %w", id, err)
+		}
+
+		_, err = r.db.Exec(ctx, `UPDATE board_game_images SET content_hash = $1 WHERE id = $2`,
+			helpers.ContentHash(data), id)
+		if err != nil {
+			return hashed, fmt.Errorf("image %d: %w", id, err)
+		}
+
+		hashed++
+	}
 }
@@ -2,51 +2,368 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/recommend"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// Create the type here to have a pointer to the db pool
+// Create the type here to have a pointer to the db pool, the store holds the
+// image files removed along with a game
 type BoardGameRepository struct {
-	db *pgxpool.Pool
+	db    *pgxpool.Pool
+	store storage.BlobStore
 }
 
+// Every method acts as userID: games of households they are not a member of
+// are not found, and changes fail with ErrForbidden unless they are an owner
+// or editor of the game's household
 type BoardGameRepo interface {
-	Create(ctx context.Context, game *models.BoardGame) error
-	GetAll(ctx context.Context) ([]*models.BoardGame, error)
-	GetByID(ctx context.Context, id int64) (*models.BoardGame, error)
-	Delete(ctx context.Context, id int64) error
+	Create(ctx context.Context, userID int64, game *models.BoardGame) error
+	GetAll(ctx context.Context, userID int64, filter models.BoardGameFilter) (*models.BoardGamePage, error)
+	GetByID(ctx context.Context, userID int64, id int64) (*models.BoardGame, error)
+	Access(ctx context.Context, userID int64, id int64) (models.HouseholdRole, error)
+	Search(ctx context.Context, userID int64, text string, limit int) ([]*models.BoardGameSearchResult, error)
+	Recommend(ctx context.Context, userID int64, criteria models.RecommendationCriteria) ([]*models.Recommendation, error)
+	Update(ctx context.Context, userID int64, game *models.BoardGame) error
+	Patch(ctx context.Context, userID int64, id int64, fields map[string]any) (*models.BoardGame, error)
+	Delete(ctx context.Context, userID int64, id int64, withExpansions bool) error
 }
 
-func NewBoardGameRepository(db *pgxpool.Pool) *BoardGameRepository {
-	return &BoardGameRepository{db: db}
+// Columns that can be changed through Patch, keyed by their JSON name
+var patchableColumns = map[string]string{
+	"name":           "name",
+	"min_players":    "min_players",
+	"max_players":    "max_players",
+	"play_time":      "play_time",
+	"min_age":        "min_age",
+	"description":    "description",
+	"year_published": "year_published",
 }
 
-func (r *BoardGameRepository) Create(ctx context.Context, game *models.BoardGame) error {
+// IsPatchableField reports whether a JSON field can be changed through Patch
+func IsPatchableField(field string) bool {
+	_, ok := patchableColumns[field]
+	return ok
+}
+
+const (
+	DefaultPageSize        = 50
+	MaxPageSize            = 200
+	DefaultRecommendations = 10
+)
+
+// Columns read for every board game, in the order scanBoardGame expects.
+// Image metadata (never the bytes), tags, credits and expansions come along
+// as JSON so a page of games is still a single query.
+const boardGameColumns = `id, name, min_players, COALESCE(max_players, 0), play_time, min_age, description,
+	year_published, bgg_id, COALESCE(household_id, 0), created_at, updated_at,
+	COALESCE((SELECT json_agg(json_build_object('id', i.id, 'type', i.image_type, 'hash', i.content_hash)
+			ORDER BY i.image_type = 'cover' DESC, i.display_order, i.id)
+		FROM board_game_images i WHERE i.board_game_id = board_games.id), '[]'),
+	COALESCE((SELECT json_agg(json_build_object('id', t.id, 'name', t.name, 'kind', t.kind)
+			ORDER BY t.kind, LOWER(t.name))
+		FROM board_game_tags bgt JOIN tags t ON t.id = bgt.tag_id
+		WHERE bgt.board_game_id = board_games.id), '[]'),
+	COALESCE((SELECT json_agg(json_build_object('id', p.id, 'name', p.name, 'role', bgp.role) ORDER BY bgp.position)
+		FROM board_game_people bgp JOIN people p ON p.id = bgp.person_id
+		WHERE bgp.board_game_id = board_games.id), '[]'),
+	COALESCE((SELECT json_agg(json_build_object('id', p.id, 'name', p.name, 'year_published', bgp.year_published)
+			ORDER BY bgp.position)
+		FROM board_game_publishers bgp JOIN publishers p ON p.id = bgp.publisher_id
+		WHERE bgp.board_game_id = board_games.id), '[]'),
+	COALESCE((SELECT json_agg(json_build_object('id', e.id, 'name', e.name, 'relation', bge.relation,
+				'max_players', bge.max_players, 'play_time', bge.play_time)
+			ORDER BY LOWER(e.name), e.id)
+		FROM board_game_expansions bge JOIN board_games e ON e.id = bge.expansion_id
+		WHERE bge.base_game_id = board_games.id), '[]'),
+	COALESCE((SELECT json_build_object('id', b.id, 'name', b.name, 'relation', bge.relation,
+				'max_players', bge.max_players, 'play_time', bge.play_time)
+		FROM board_game_expansions bge JOIN board_games b ON b.id = bge.base_game_id
+		WHERE bge.expansion_id = board_games.id), 'null'),
+	(SELECT l.borrower_name FROM loans l WHERE l.board_game_id = board_games.id AND l.returned_at IS NULL)`
+
+// extra receives any columns selected after boardGameColumns
+func scanBoardGame(row pgx.Row, extra ...any) (*models.BoardGame, error) {
+	var game models.BoardGame
+	var images, tags, people, publishers, expansions, baseGame []byte
+	dest := []any{
+		&game.ID,
+		&game.Name,
+		&game.MinPlayers,
+		&game.MaxPlayers,
+		&game.PlayTime,
+		&game.MinAge,
+		&game.Description,
+		&game.YearPublished,
+		&game.BGGID,
+		&game.HouseholdID,
+		&game.CreatedAt,
+		&game.UpdatedAt,
+		&images,
+		&tags,
+		&people,
+		&publishers,
+		&expansions,
+		&baseGame,
+		&game.LentTo,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setBoardGameImages(&game, images); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(tags, &game.Tags); err != nil {
+		return nil, err
+	}
+	if err := setBoardGamePeople(&game, people); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(publishers, &game.Publishers); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(expansions, &game.Expansions); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(baseGame, &game.BaseGame); err != nil {
+		return nil, err
+	}
+	game.Combined = models.CombineExpansions(&game)
+	return &game, nil
+}
+
+// Fills the image references and only sets the cover URL when a cover exists
+func setBoardGameImages(game *models.BoardGame, imagesJSON []byte) error {
+	var images []struct {
+		ID   int64  `json:"id"`
+		Type string `json:"type"`
+		Hash string `json:"hash"`
+	}
+	if err := json.Unmarshal(imagesJSON, &images); err != nil {
+		return err
+	}
+
+	game.ImageIDs = nil
+	game.Images = nil
+	game.CoverImageUrL = ""
+	for _, image := range images {
+		version := models.ImageVersion(image.Hash)
+		game.ImageIDs = append(game.ImageIDs, image.ID)
+		game.Images = append(game.Images, models.BoardGameImageRef{
+			ID:   image.ID,
+			URL:  models.ImageURL(image.ID, version),
+			Type: image.Type,
+		})
+		if image.Type == "cover" {
+			// Versioned, replacing the cover changes the URL
+			game.CoverImageUrL = models.CoverImageURL(game.ID, version)
+		}
+	}
+	return nil
+}
+
+// Splits the credited people by role
+func setBoardGamePeople(game *models.BoardGame, peopleJSON []byte) error {
+	var people []struct {
+		models.PersonRef
+		Role string `json:"role"`
+	}
+	if err := json.Unmarshal(peopleJSON, &people); err != nil {
+		return err
+	}
+
+	game.Designers = nil
+	game.Artists = nil
+	for _, person := range people {
+		switch person.Role {
+		case models.RoleDesigner:
+			game.Designers = append(game.Designers, person.PersonRef)
+		case models.RoleArtist:
+			game.Artists = append(game.Artists, person.PersonRef)
+		}
+	}
+	return nil
+}
+
+// How GetAll can sort. The cursor keeps the sort value as text and casts it back.
+type sortColumn struct {
+	expr  string
+	cast  string
+	value func(game *models.BoardGame) string
+}
+
+var boardGameSortColumns = map[string]sortColumn{
+	"":            {"id", "bigint", func(g *models.BoardGame) string { return strconv.FormatInt(g.ID, 10) }},
+	"id":          {"id", "bigint", func(g *models.BoardGame) string { return strconv.FormatInt(g.ID, 10) }},
+	"name":        {"name", "text", func(g *models.BoardGame) string { return g.Name }},
+	"play_time":   {"COALESCE(play_time, 0)", "integer", func(g *models.BoardGame) string { return strconv.Itoa(g.PlayTime) }},
+	"min_players": {"min_players", "integer", func(g *models.BoardGame) string { return strconv.Itoa(g.MinPlayers) }},
+	"min_age":     {"COALESCE(min_age, 0)", "integer", func(g *models.BoardGame) string { return strconv.Itoa(g.MinAge) }},
+	"created_at":  {"created_at", "timestamp", func(g *models.BoardGame) string { return g.CreatedAt.Format(time.RFC3339Nano) }},
+}
+
+type boardGameCursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
+}
+
+func encodeBoardGameCursor(cursor boardGameCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeBoardGameCursor(token string) (*boardGameCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor boardGameCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+func NewBoardGameRepository(db *pgxpool.Pool, store storage.BlobStore) *BoardGameRepository {
+	return &BoardGameRepository{db: db, store: store}
+}
+
+// The game goes to game.HouseholdID, or the first household the user can edit
+func (r *BoardGameRepository) Create(ctx context.Context, userID int64, game *models.BoardGame) error {
+	householdID, err := editableHousehold(ctx, r.db, userID, game.HouseholdID)
+	if err != nil {
+		return err
+	}
+
+	// Checked again on insert, the role may have changed in between
 	query := `INSERT into board_games 
-		(name, min_players, max_players, play_time, min_age, description)
-		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`
+		(name, min_players, max_players, play_time, min_age, description, year_published, household_id)
+		SELECT $1, $2, NULLIF($3, 0), $4, $5, $6, $7, $8
+		WHERE ` + editorOf("$8::int", "$9") + `
+		RETURNING id, household_id, created_at, updated_at`
 
 	//Here we execute the query and assign the returned id and created_at to the game struct
-	err := r.db.QueryRow(ctx, query,
+	err = r.db.QueryRow(ctx, query,
 		game.Name,
 		game.MinPlayers,
 		game.MaxPlayers,
 		game.PlayTime,
 		game.MinAge,
 		game.Description,
-	).Scan(&game.ID, &game.CreatedAt)
+		game.YearPublished,
+		householdID,
+		userID,
+	).Scan(&game.ID, &game.HouseholdID, &game.CreatedAt, &game.UpdatedAt)
 
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrForbidden
+	}
 	return err
 }
 
-func (r *BoardGameRepository) GetAll(ctx context.Context) ([]*models.BoardGame, error) {
-	query := `SELECT id, name, min_players, max_players, play_time, min_age, description, created_at, updated_at
-		FROM board_games ORDER BY id ASC`
+func (r *BoardGameRepository) GetAll(ctx context.Context, userID int64, filter models.BoardGameFilter) (*models.BoardGamePage, error) {
+	sort, ok := boardGameSortColumns[filter.Sort]
+	if !ok {
+		return nil, ErrInvalidSort
+	}
+
+	descending := false
+	switch filter.Order {
+	case "", "asc":
+	case "desc":
+		descending = true
+	default:
+		return nil, ErrInvalidSort
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	// 1. Filters shared by the count and the page queries
+	filters := &conditions{}
+	filters.add(memberOf("household_id", "?"), userID)
+	if filter.Players > 0 {
+		filters.add("min_players <= ? AND COALESCE(max_players, min_players) >= ?", filter.Players, filter.Players)
+	}
+	if filter.MaxPlayTime > 0 {
+		filters.add("play_time <= ?", filter.MaxPlayTime)
+	}
+	if filter.Age > 0 {
+		filters.add("COALESCE(min_age, 0) <= ?", filter.Age)
+	}
+	if filter.NamePrefix != "" {
+		filters.add("name ILIKE ?", escapeLike(filter.NamePrefix)+"%")
+	}
+	if len(filter.Tags) > 0 {
+		if err := addTagFilter(filters, filter.Tags, filter.TagMatch); err != nil {
+			return nil, err
+		}
+	}
+	if filter.Designer != "" {
+		filters.add(`EXISTS (SELECT 1 FROM board_game_people bgp JOIN people p ON p.id = bgp.person_id
+			WHERE bgp.board_game_id = board_games.id AND bgp.role = 'designer' AND p.name ILIKE ?)`,
+			"%"+escapeLike(filter.Designer)+"%")
+	}
+	if filter.Publisher != "" {
+		filters.add(`EXISTS (SELECT 1 FROM board_game_publishers bgp JOIN publishers p ON p.id = bgp.publisher_id
+			WHERE bgp.board_game_id = board_games.id AND p.name ILIKE ?)`,
+			"%"+escapeLike(filter.Publisher)+"%")
+	}
+	if filter.OnShelf != nil {
+		exists := "EXISTS"
+		if *filter.OnShelf {
+			exists = "NOT EXISTS"
+		}
+		filters.add(exists + ` (SELECT 1 FROM loans l WHERE l.board_game_id = board_games.id AND l.returned_at IS NULL)`)
+	}
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM board_games` + filters.where()
+	if err := r.db.QueryRow(ctx, countQuery, filters.args...).Scan(&total); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	// 2. Keyset pagination: continue after the last row of the previous page
+	page := filters.clone()
+	comparison, direction := ">", "ASC"
+	if descending {
+		comparison, direction = "<", "DESC"
+	}
+
+	if filter.Cursor != "" {
+		cursor, err := decodeBoardGameCursor(filter.Cursor)
+		if err != nil || cursor.Sort != filter.Sort || cursor.Order != filter.Order {
+			return nil, ErrInvalidCursor
+		}
+		page.add(fmt.Sprintf("(%s, id) %s (?::%s, ?)", sort.expr, comparison, sort.cast), cursor.Value, cursor.ID)
+	}
 
-	rows, err := r.db.Query(ctx, query)
+	// Fetch one extra row to know if there is a next page
+	query := fmt.Sprintf(`SELECT %s FROM board_games%s ORDER BY %s %s, id %s LIMIT %s`,
+		boardGameColumns, page.where(), sort.expr, direction, direction, page.arg(limit+1))
+
+	rows, err := r.db.Query(ctx, query, page.args...)
 
 	if err != nil {
 		return nil, ErrQueryFailed
@@ -55,68 +372,364 @@ func (r *BoardGameRepository) GetAll(ctx context.Context) ([]*models.BoardGame,
 	//Need to close resultset
 	defer rows.Close()
 
-	var boardGames []*models.BoardGame
+	boardGames := []*models.BoardGame{}
 	for rows.Next() {
-		boardGame := &models.BoardGame{}
-		err := rows.Scan(
-			&boardGame.ID,
-			&boardGame.Name,
-			&boardGame.MinPlayers,
-			&boardGame.MaxPlayers,
-			&boardGame.PlayTime,
-			&boardGame.MinAge,
-			&boardGame.Description,
-			&boardGame.CreatedAt,
-			&boardGame.UpdatedAt,
-		)
+		boardGame, err := scanBoardGame(rows)
+		if err != nil {
+			return nil, ErrQueryFailed
+		}
+
+		boardGames = append(boardGames, boardGame)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	result := &models.BoardGamePage{Games: boardGames, Total: total}
+	if len(boardGames) > limit {
+		result.Games = boardGames[:limit]
+		last := result.Games[limit-1]
+		result.Next = encodeBoardGameCursor(boardGameCursor{
+			Sort:  filter.Sort,
+			Order: filter.Order,
+			Value: sort.value(last),
+			ID:    last.ID,
+		})
+	}
+
+	return result, nil
+}
+
+func (r *BoardGameRepository) GetByID(ctx context.Context, userID int64, id int64) (*models.BoardGame, error) {
+	query := `SELECT ` + boardGameColumns + ` FROM board_games WHERE id = $1 AND ` + memberOf("household_id", "$2")
+
+	game, err := scanBoardGame(r.db.QueryRow(ctx, query, id, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrBoardGameNotFound
+		}
+		return nil, ErrQueryFailed
+	}
+
+	return game, nil
+}
+
+// Role of the user in the household of the game, ErrBoardGameNotFound when
+// they are not a member
+func (r *BoardGameRepository) Access(ctx context.Context, userID int64, id int64) (models.HouseholdRole, error) {
+	return boardGameRole(ctx, r.db, userID, id)
+}
+
+// Search ranks games by full text relevance over name and description.
+// Names that are only similar (typos, partial words) are matched through pg_trgm.
+func (r *BoardGameRepository) Search(ctx context.Context, userID int64, text string, limit int) ([]*models.BoardGameSearchResult, error) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	query := `SELECT ` + boardGameColumns + `,
+			ts_rank(search_vector, search_query) + similarity(name, $1) AS rank
+		FROM board_games, websearch_to_tsquery('english', $1) AS search_query
+		WHERE ` + memberOf("household_id", "$3") + ` AND (search_vector @@ search_query OR name % $1 OR $1 <% name)
+		ORDER BY rank DESC, name ASC
+		LIMIT $2`
 
-		boardGame.CoverImageUrL = fmt.Sprintf("/api/boardgame/%d/images/cover", boardGame.ID)
+	rows, err := r.db.Query(ctx, query, text, limit, userID)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
 
+	results := []*models.BoardGameSearchResult{}
+	for rows.Next() {
+		var rank float64
+		game, err := scanBoardGame(rows, &rank)
 		if err != nil {
 			return nil, ErrQueryFailed
 		}
-		boardGames = append(boardGames, boardGame)
+		results = append(results, &models.BoardGameSearchResult{BoardGame: *game, Rank: rank})
 	}
 
-	return boardGames, nil
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return results, nil
 }
 
-func (r *BoardGameRepository) GetByID(ctx context.Context, id int64) (*models.BoardGame, error) {
-	query := `SELECT id, name, min_players, max_players, play_time, min_age, description, created_at, updated_at
-		FROM board_games WHERE id = $1`
+// Games that can be played with these players, time and age, best fit first.
+// Tags are a preference, they are matched against the description.
+func (r *BoardGameRepository) Recommend(ctx context.Context, userID int64, criteria models.RecommendationCriteria) ([]*models.Recommendation, error) {
+	limit := criteria.Limit
+	if limit <= 0 {
+		limit = DefaultRecommendations
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
 
-	var game models.BoardGame
+	filters := &conditions{}
+	filters.add(memberOf("household_id", "?"), userID)
+	filters.add("min_players <= ? AND COALESCE(NULLIF(max_players, 0), min_players) >= ?", criteria.Players, criteria.Players)
+	if criteria.Minutes > 0 {
+		filters.add("COALESCE(play_time, 0) <= ?", criteria.Minutes)
+	}
+	if criteria.Age > 0 {
+		filters.add("COALESCE(min_age, 0) <= ?", criteria.Age)
+	}
+	if len(criteria.ExcludeIDs) > 0 {
+		filters.add("NOT (id = ANY(?))", criteria.ExcludeIDs)
+	}
+	// Expansions and promos need their base game on the table
+	filters.add(`NOT EXISTS (SELECT 1 FROM board_game_expansions bge
+		WHERE bge.expansion_id = board_games.id AND bge.relation <> ?)`, models.RelationStandaloneExpansion)
 
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&game.ID,
-		&game.Name,
-		&game.MinPlayers,
-		&game.MaxPlayers,
-		&game.PlayTime,
-		&game.MinAge,
-		&game.Description,
-		&game.CreatedAt,
-		&game.UpdatedAt,
-	)
+	tags := criteria.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	// A tag matches when the game has it, or when the description talks about it
+	query := `SELECT ` + boardGameColumns + `,
+			ARRAY(SELECT tag FROM unnest(` + filters.arg(tags) + `::text[]) AS tag
+				WHERE EXISTS (SELECT 1 FROM board_game_tags bgt JOIN tags t ON t.id = bgt.tag_id
+						WHERE bgt.board_game_id = board_games.id AND LOWER(t.name) = LOWER(tag))
+					OR search_vector @@ plainto_tsquery('english', tag))
+		FROM board_games` + filters.where()
 
+	rows, err := r.db.Query(ctx, query, filters.args...)
 	if err != nil {
 		return nil, ErrQueryFailed
 	}
+	defer rows.Close()
 
-	return &game, nil
+	candidates := []*models.Recommendation{}
+	for rows.Next() {
+		var matched []string
+		game, err := scanBoardGame(rows, &matched)
+		if err != nil {
+			return nil, ErrQueryFailed
+		}
+		candidates = append(candidates, &models.Recommendation{BoardGame: *game, MatchedTags: matched})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return recommend.Rank(candidates, criteria, limit), nil
+}
+
+// Keeps the games tagged with every name (match "all") or with at least one of them ("any")
+func addTagFilter(filters *conditions, names []string, match string) error {
+	seen := map[string]bool{}
+	var lowered []string
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" && !seen[name] {
+			seen[name] = true
+			lowered = append(lowered, name)
+		}
+	}
+
+	switch match {
+	case "", "all":
+		filters.add(`(SELECT COUNT(*) FROM board_game_tags bgt JOIN tags t ON t.id = bgt.tag_id
+			WHERE bgt.board_game_id = board_games.id AND LOWER(t.name) = ANY(?)) = ?`, lowered, len(lowered))
+	case "any":
+		filters.add(`EXISTS (SELECT 1 FROM board_game_tags bgt JOIN tags t ON t.id = bgt.tag_id
+			WHERE bgt.board_game_id = board_games.id AND LOWER(t.name) = ANY(?))`, lowered)
+	default:
+		return ErrInvalidTagMatch
+	}
+	return nil
 }
 
-func (r *BoardGameRepository) Delete(ctx context.Context, id int64) error {
-	query := `DELETE FROM board_games WHERE id = $1`
+// Update replaces every editable column of an existing board game, a
+// MaxPlayers of 0 stores NULL
+func (r *BoardGameRepository) Update(ctx context.Context, userID int64, game *models.BoardGame) error {
+	query := `UPDATE board_games
+		SET name = $1, min_players = $2, max_players = NULLIF($3, 0), play_time = $4, min_age = $5, description = $6,
+			year_published = $7, updated_at = NOW()
+		WHERE id = $8 AND ` + editorOf("household_id", "$9") + `
+		RETURNING household_id, created_at, updated_at`
+
+	err := r.db.QueryRow(ctx, query,
+		game.Name,
+		game.MinPlayers,
+		game.MaxPlayers,
+		game.PlayTime,
+		game.MinAge,
+		game.Description,
+		game.YearPublished,
+		game.ID,
+		userID,
+	).Scan(&game.HouseholdID, &game.CreatedAt, &game.UpdatedAt)
 
-	commandTag, err := r.db.Exec(ctx, query, id)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return boardGameDenied(ctx, r.db, userID, game.ID)
+		}
 		return ErrQueryFailed
 	}
 
+	return nil
+}
+
+// Patch only updates the given fields. A nil value stores NULL. When the
+// player counts change, the new range is checked against the row being
+// written, so a concurrent change to the other count fails with
+// ErrInvalidPlayerRange rather than storing max_players below min_players.
+func (r *BoardGameRepository) Patch(ctx context.Context, userID int64, id int64, fields map[string]any) (*models.BoardGame, error) {
+	var sets []string
+	var args []any
+	newValues := map[string]string{"min_players": "min_players", "max_players": "max_players"}
+
+	for field, value := range fields {
+		column, ok := patchableColumns[field]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPatchField, field)
+		}
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+		if _, ok := newValues[column]; ok {
+			newValues[column] = fmt.Sprintf("$%d::integer", len(args))
+		}
+	}
+	sets = append(sets, "updated_at = NOW()")
+	args = append(args, id, userID)
+
+	_, minPatched := fields["min_players"]
+	_, maxPatched := fields["max_players"]
+	checkRange := minPatched || maxPatched
+	rangeCheck := ""
+	if checkRange {
+		rangeCheck = fmt.Sprintf(" AND (%[1]s IS NULL OR %[1]s >= %[2]s)", newValues["max_players"], newValues["min_players"])
+	}
+
+	query := fmt.Sprintf(`UPDATE board_games SET %s WHERE id = $%d AND %s%s RETURNING %s`,
+		strings.Join(sets, ", "), len(args)-1, editorOf("household_id", fmt.Sprintf("$%d", len(args))), rangeCheck,
+		boardGameColumns)
+
+	game, err := scanBoardGame(r.db.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, patchDenied(ctx, r.db, userID, id, checkRange)
+		}
+		return nil, ErrQueryFailed
+	}
+
+	return game, nil
+}
+
+// Why a patch matched no game: ErrForbidden or ErrBoardGameNotFound like
+// boardGameDenied, else the player range no longer held
+func patchDenied(ctx context.Context, q queryRower, userID int64, id int64, checkedRange bool) error {
+	role, err := boardGameRole(ctx, q, userID, id)
+	if err != nil {
+		return err
+	}
+	if !role.CanEdit() {
+		return ErrForbidden
+	}
+	if checkedRange {
+		return ErrInvalidPlayerRange
+	}
+	// Editable after all, it was deleted in between
+	return ErrBoardGameNotFound
+}
+
+// Deletes the game with its images, all or nothing. The image files are
+// removed once the rows are gone, the ON DELETE CASCADE alone would leave
+// them behind. Fails with ErrHasExpansions while other games are expansions
+// of this one, unless withExpansions deletes them too.
+func (r *BoardGameRepository) Delete(ctx context.Context, userID int64, id int64, withExpansions bool) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return ErrQueryFailed
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	// Expansions are in the household of their base game, editing it is enough
+	role, err := boardGameRole(ctx, tx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !role.CanEdit() {
+		return ErrForbidden
+	}
+
+	ids, err := expansionTree(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if len(ids) > 0 && !withExpansions {
+		return ErrHasExpansions
+	}
+	ids = append(ids, id)
+
+	keys, err := deleteImageRows(ctx, tx, `board_game_id = ANY($1)`, ids)
+	if err != nil {
+		return err
+	}
+
+	// The deepest expansions go first, a game cannot be deleted while something expands it
+	var commandTag pgconn.CommandTag
+	for _, gameID := range ids {
+		commandTag, err = tx.Exec(ctx, `DELETE FROM board_games WHERE id = $1`, gameID)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" && pgErr.TableName == "board_game_expansions" {
+				return ErrHasExpansions
+			}
+			return ErrQueryFailed
+		}
+	}
 	if commandTag.RowsAffected() == 0 {
 		return ErrBoardGameNotFound
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return ErrQueryFailed
+	}
+
+	deleteBlobs(ctx, r.store, keys...)
 	return nil
 }
+
+// Ids of the expansions of the game, their expansions and so on, the deepest
+// first so they can be deleted in order
+func expansionTree(ctx context.Context, tx pgx.Tx, id int64) ([]int64, error) {
+	query := `WITH RECURSIVE tree (id, depth) AS (
+			SELECT expansion_id, 1 FROM board_game_expansions WHERE base_game_id = $1
+			UNION ALL
+			SELECT bge.expansion_id, tree.depth + 1
+			FROM board_game_expansions bge JOIN tree ON bge.base_game_id = tree.id
+		)
+		SELECT id FROM tree ORDER BY depth DESC, id`
+
+	rows, err := tx.Query(ctx, query, id)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var expansionID int64
+		if err := rows.Scan(&expansionID); err != nil {
+			return nil, ErrQueryFailed
+		}
+		ids = append(ids, expansionID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,371 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/helpers"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// The whole shelf at once, for CSV and JSON exports. Imports copy the image
+// files the exports point to in the store.
+type CollectionRepository struct {
+	db    *pgxpool.Pool
+	store storage.BlobStore
+}
+
+type CollectionRepo interface {
+	ExportBoardGames(ctx context.Context, userID int64) ([]*models.CollectionGame, error)
+	ImportBoardGames(ctx context.Context, userID int64, games []*models.CollectionGame, onDuplicate string) (*models.CollectionImportReport, error)
+}
+
+func NewCollectionRepository(db *pgxpool.Pool, store storage.BlobStore) *CollectionRepository {
+	return &CollectionRepository{db: db, store: store}
+}
+
+// Every game of the user's households by id, with its tags and image references
+func (r *CollectionRepository) ExportBoardGames(ctx context.Context, userID int64) ([]*models.CollectionGame, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+boardGameColumns+` FROM board_games WHERE `+memberOf("household_id", "$1")+` ORDER BY id`, userID)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	games := []*models.CollectionGame{}
+	for rows.Next() {
+		game, err := scanBoardGame(rows)
+		if err != nil {
+			return nil, ErrQueryFailed
+		}
+		games = append(games, models.NewCollectionGame(game))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return games, nil
+}
+
+// Adds the games in one transaction to the first household the user can edit,
+// ErrForbidden when there is none. A game named like one already in that
+// household (whatever the case) is skipped, updated or, with DuplicateFail, listed
+// in the report's errors; then nothing is written and ErrDuplicateName is
+// returned along with the report. Ids in the file are ignored, the tags of a
+// game are set to the ones listed and its images are restored from the image
+// references, see restoreCollectionImage.
+func (r *CollectionRepository) ImportBoardGames(ctx context.Context, userID int64, games []*models.CollectionGame, onDuplicate string) (*models.CollectionImportReport, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	// Files copied for restored images, removed again unless the import commits
+	var copiedKeys []*string
+	committed := false
+	defer func() {
+		if !committed {
+			deleteBlobs(ctx, r.store, copiedKeys...)
+		}
+	}()
+
+	householdID, err := editableHousehold(ctx, tx, userID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.CollectionImportReport{
+		Created: []models.CollectionImportEntry{},
+		Updated: []models.CollectionImportEntry{},
+		Skipped: []models.CollectionImportEntry{},
+		Errors:  []models.CollectionRowError{},
+	}
+	duplicates := false
+
+	for _, game := range games {
+		entry := models.CollectionImportEntry{Line: game.Line, Name: game.Name}
+
+		var id int64
+		err := tx.QueryRow(ctx, `SELECT id FROM board_games WHERE LOWER(name) = LOWER($1) AND household_id = $2
+			ORDER BY id LIMIT 1`, game.Name, householdID).Scan(&id)
+		found := err == nil
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrQueryFailed
+		}
+
+		if found && onDuplicate != models.DuplicateUpdate {
+			entry.BoardGameID = id
+			if onDuplicate == models.DuplicateFail {
+				duplicates = true
+				report.Errors = append(report.Errors, models.CollectionRowError{
+					Line: game.Line, Name: game.Name, Error: ErrDuplicateName.Error(),
+				})
+			} else {
+				report.Skipped = append(report.Skipped, entry)
+			}
+			continue
+		}
+
+		// bgg_id is unique in a household, the row is left out rather than failing the whole import
+		if game.BGGID != nil {
+			var owner int64
+			err := tx.QueryRow(ctx, `SELECT id FROM board_games WHERE bgg_id = $1 AND id <> $2 AND household_id = $3`,
+				*game.BGGID, id, householdID).Scan(&owner)
+			if err == nil {
+				report.Errors = append(report.Errors, models.CollectionRowError{
+					Line: game.Line, Name: game.Name, Error: fmt.Sprintf("bgg_id already belongs to board game %d", owner),
+				})
+				continue
+			}
+			if !errors.Is(err, pgx.ErrNoRows) {
+				return nil, ErrQueryFailed
+			}
+		}
+
+		if found {
+			err = updateCollectionGame(ctx, tx, id, game)
+		} else {
+			id, err = createCollectionGame(ctx, tx, householdID, game)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err := setCollectionTags(ctx, tx, id, game.Tags); err != nil {
+			return nil, err
+		}
+
+		for _, image := range game.Images {
+			status, keys, err := restoreCollectionImage(ctx, tx, r.store, userID, id, image.ID)
+			copiedKeys = append(copiedKeys, keys...)
+			if err != nil {
+				return nil, err
+			}
+			entry.Images = append(entry.Images, models.CollectionImageResult{ID: image.ID, Type: image.Type, Status: status})
+		}
+
+		entry.BoardGameID = id
+		if found {
+			report.Updated = append(report.Updated, entry)
+		} else {
+			report.Created = append(report.Created, entry)
+		}
+	}
+
+	if duplicates {
+		return report, ErrDuplicateName
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, ErrQueryFailed
+	}
+	committed = true
+
+	return report, nil
+}
+
+// Keeps the timestamps of the file, NOW() when it has none
+func createCollectionGame(ctx context.Context, tx pgx.Tx, householdID int64, game *models.CollectionGame) (int64, error) {
+	var id int64
+	err := tx.QueryRow(ctx, `INSERT INTO board_games
+		(name, min_players, max_players, play_time, min_age, description, year_published, bgg_id, created_at, updated_at,
+			household_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE($9, NOW()), COALESCE($10, NOW()), $11) RETURNING id`,
+		game.Name, game.MinPlayers, nullIfZero(game.MaxPlayers), game.PlayTime, game.MinAge, game.Description,
+		game.YearPublished, game.BGGID, nullIfZeroTime(game.CreatedAt), nullIfZeroTime(game.UpdatedAt), householdID).Scan(&id)
+	if err != nil {
+		return 0, ErrQueryFailed
+	}
+	return id, nil
+}
+
+func updateCollectionGame(ctx context.Context, tx pgx.Tx, id int64, game *models.CollectionGame) error {
+	_, err := tx.Exec(ctx, `UPDATE board_games
+		SET name = $1, min_players = $2, max_players = $3, play_time = $4, min_age = $5, description = $6,
+			year_published = $7, bgg_id = COALESCE($8, bgg_id), updated_at = NOW()
+		WHERE id = $9`,
+		game.Name, game.MinPlayers, nullIfZero(game.MaxPlayers), game.PlayTime, game.MinAge, game.Description,
+		game.YearPublished, game.BGGID, id)
+	if err != nil {
+		return ErrQueryFailed
+	}
+	return nil
+}
+
+// Copies the image the file refers to onto the game, with its thumbnail and
+// variants, so an export imported into another household or after its games
+// were deleted gets them back. The image must still be on a shelf of the user
+// and its files in the store. Returns the status for the report and the keys
+// of the files copied.
+func restoreCollectionImage(ctx context.Context, tx pgx.Tx, store storage.BlobStore, userID int64, boardGameID int64, imageID int64) (string, []*string, error) {
+	var imageKey, thumbnailKey, contentHash *string
+	var mimeType, imageType string
+	err := tx.QueryRow(ctx, `SELECT image_key, thumbnail_key, image_mime_type, image_type, content_hash
+		FROM board_game_images WHERE id = $1 AND `+onShelf("board_game_id", 2), imageID, userID).
+		Scan(&imageKey, &thumbnailKey, &mimeType, &imageType, &contentHash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ImageMissing, nil, nil
+	}
+	if err != nil {
+		return "", nil, ErrQueryFailed
+	}
+	// Images still stored in the database have no file to copy
+	if imageKey == nil {
+		return models.ImageMissing, nil, nil
+	}
+
+	// The game already has it, e.g. when the same file is imported twice
+	var kept, coverExists bool
+	err = tx.QueryRow(ctx, `SELECT
+			EXISTS (SELECT 1 FROM board_game_images WHERE board_game_id = $1 AND content_hash = $2),
+			EXISTS (SELECT 1 FROM board_game_images WHERE board_game_id = $1 AND image_type = 'cover')`,
+		boardGameID, contentHash).Scan(&kept, &coverExists)
+	if err != nil {
+		return "", nil, ErrQueryFailed
+	}
+	if kept {
+		return models.ImageKept, nil, nil
+	}
+	if imageType == "cover" && coverExists {
+		return models.ImageCoverExists, nil, nil
+	}
+
+	variants, err := collectImageVariants(ctx, tx, imageID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Same layout as an upload, see BoardGameImageRepository.putBlobs
+	base, err := storage.NewKey(fmt.Sprintf("boardgames/%d", boardGameID))
+	if err != nil {
+		return "", nil, err
+	}
+	var copied []*string
+	copyBlob := func(source *string, target string, mimeType string) (*string, error) {
+		if source == nil {
+			return nil, nil
+		}
+		data, err := store.Get(ctx, *source)
+		if err != nil {
+			return nil, err
+		}
+		if err := store.Put(ctx, target, data, mimeType); err != nil {
+			return nil, err
+		}
+		copied = append(copied, &target)
+		return &target, nil
+	}
+
+	newImageKey, err := copyBlob(imageKey, base+"/original", mimeType)
+	var newThumbnailKey *string
+	if err == nil {
+		newThumbnailKey, err = copyBlob(thumbnailKey, base+"/thumbnail", helpers.ThumbnailMimeType(mimeType))
+	}
+	for i := range variants {
+		if err != nil {
+			break
+		}
+		key := fmt.Sprintf("%s/%d.%s", base, variants[i].Width, strings.TrimPrefix(variants[i].MimeType, "image/"))
+		_, err = copyBlob(&variants[i].key, key, variants[i].MimeType)
+		variants[i].key = key
+	}
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		deleteBlobs(ctx, store, copied...)
+		return models.ImageMissing, nil, nil
+	}
+	if err != nil {
+		return "", copied, err
+	}
+
+	var newID int64
+	err = tx.QueryRow(ctx, `INSERT INTO board_game_images
+		(board_game_id, image_key, image_mime_type, thumbnail_key, image_type, content_hash, display_order, uploaded_at)
+		VALUES ($1, $2, $3, $4, $5, $6,
+			(SELECT COALESCE(MAX(display_order) + 1, 0) FROM board_game_images WHERE board_game_id = $1 AND image_type = $5),
+			NOW())
+		RETURNING id`, boardGameID, newImageKey, mimeType, newThumbnailKey, imageType, contentHash).Scan(&newID)
+	if err != nil {
+		return "", copied, ErrQueryFailed
+	}
+
+	for _, variant := range variants {
+		_, err := tx.Exec(ctx, `INSERT INTO board_game_image_variants
+			(image_id, width, height, mime_type, storage_key, byte_size)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			newID, variant.Width, variant.Height, variant.MimeType, variant.key, variant.byteSize)
+		if err != nil {
+			return "", copied, ErrQueryFailed
+		}
+	}
+
+	return models.ImageRestored, copied, nil
+}
+
+type storedVariant struct {
+	models.ImageVariant
+	key      string
+	byteSize int
+}
+
+func collectImageVariants(ctx context.Context, tx pgx.Tx, imageID int64) ([]storedVariant, error) {
+	rows, err := tx.Query(ctx, `SELECT width, height, mime_type, storage_key, byte_size
+		FROM board_game_image_variants WHERE image_id = $1 ORDER BY width, mime_type`, imageID)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	var variants []storedVariant
+	for rows.Next() {
+		var variant storedVariant
+		if err := rows.Scan(&variant.Width, &variant.Height, &variant.MimeType, &variant.key, &variant.byteSize); err != nil {
+			return nil, ErrQueryFailed
+		}
+		variants = append(variants, variant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return variants, nil
+}
+
+// Tags are matched by name, missing ones are created with the kind of the file
+func setCollectionTags(ctx context.Context, tx pgx.Tx, id int64, tags []models.TagRef) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM board_game_tags WHERE board_game_id = $1`, id); err != nil {
+		return ErrQueryFailed
+	}
+
+	for _, tag := range tags {
+		var tagID int64
+		err := tx.QueryRow(ctx, `INSERT INTO tags (name, kind) VALUES ($1, $2)
+			ON CONFLICT (LOWER(name)) DO UPDATE SET name = tags.name
+			RETURNING id`, tag.Name, tag.Kind).Scan(&tagID)
+		if err != nil {
+			return ErrQueryFailed
+		}
+
+		_, err = tx.Exec(ctx, `INSERT INTO board_game_tags (board_game_id, tag_id) VALUES ($1, $2)
+			ON CONFLICT DO NOTHING`, id, tagID)
+		if err != nil {
+			return ErrQueryFailed
+		}
+	}
+
+	return nil
+}
+
+func nullIfZeroTime(value time.Time) *time.Time {
+	if value.IsZero() {
+		return nil
+	}
+	return &value
+}
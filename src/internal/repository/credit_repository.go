@@ -0,0 +1,384 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// People (designers, artists) and publishers, and who made which game
+type CreditRepository struct {
+	db *pgxpool.Pool
+}
+
+type CreditRepo interface {
+	CreatePerson(ctx context.Context, person *models.Person) error
+	GetPeople(ctx context.Context, role string) ([]*models.Person, error)
+	GetPersonByID(ctx context.Context, id int64) (*models.Person, error)
+	UpdatePerson(ctx context.Context, person *models.Person) error
+	DeletePerson(ctx context.Context, id int64) error
+	GetPersonGames(ctx context.Context, userID int64, id int64, role string) ([]*models.BoardGame, error)
+
+	CreatePublisher(ctx context.Context, publisher *models.Publisher) error
+	GetPublishers(ctx context.Context) ([]*models.Publisher, error)
+	GetPublisherByID(ctx context.Context, id int64) (*models.Publisher, error)
+	UpdatePublisher(ctx context.Context, publisher *models.Publisher) error
+	DeletePublisher(ctx context.Context, id int64) error
+	GetPublisherGames(ctx context.Context, userID int64, id int64) ([]*models.BoardGame, error)
+
+	SetBoardGameCredits(ctx context.Context, boardGameID int64, credits *models.BoardGameCredits) (*models.BoardGame, error)
+}
+
+func NewCreditRepository(db *pgxpool.Pool) *CreditRepository {
+	return &CreditRepository{db: db}
+}
+
+// People and publishers are stored the same way, only the tables differ.
+// Every name here is a constant, never user input.
+type creditTable struct {
+	table      string
+	links      string // Table linking it to board games
+	linkColumn string
+	nameIndex  string
+	roleMatch  string // Condition on the link alias l, true for every link when $1 is ''
+	notFound   error
+	duplicate  error
+}
+
+var (
+	peopleTable = creditTable{
+		table:      "people",
+		links:      "board_game_people",
+		linkColumn: "person_id",
+		nameIndex:  "idx_people_name",
+		roleMatch:  "($1 = '' OR l.role = $1)",
+		notFound:   ErrPersonNotFound,
+		duplicate:  ErrDuplicatePersonName,
+	}
+	publishersTable = creditTable{
+		table:      "publishers",
+		links:      "board_game_publishers",
+		linkColumn: "publisher_id",
+		nameIndex:  "idx_publishers_name",
+		roleMatch:  "$1 = ''", // Publishers have no role
+		notFound:   ErrPublisherNotFound,
+		duplicate:  ErrDuplicatePublisherName,
+	}
+)
+
+// Columns read for every person or publisher. $1 is a role, ” counts every game.
+func (t creditTable) columns() string {
+	return `id, name,
+		(SELECT COUNT(DISTINCT l.board_game_id) FROM ` + t.links + ` l
+			WHERE l.` + t.linkColumn + ` = ` + t.table + `.id AND ` + t.roleMatch + `),
+		created_at, updated_at`
+}
+
+func (t creditTable) mapError(err error) error {
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return t.notFound
+	case errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == t.nameIndex:
+		return t.duplicate
+	default:
+		return ErrQueryFailed
+	}
+}
+
+// Publishers are scanned as people, both models have the same fields
+func scanPerson(row pgx.Row) (*models.Person, error) {
+	var person models.Person
+	err := row.Scan(&person.ID, &person.Name, &person.GameCount, &person.CreatedAt, &person.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &person, nil
+}
+
+func (r *CreditRepository) create(ctx context.Context, t creditTable, name string) (*models.Person, error) {
+	query := `INSERT INTO ` + t.table + ` (name) VALUES ($2) RETURNING ` + t.columns()
+
+	created, err := scanPerson(r.db.QueryRow(ctx, query, "", strings.TrimSpace(name)))
+	if err != nil {
+		return nil, t.mapError(err)
+	}
+	return created, nil
+}
+
+func (r *CreditRepository) getByID(ctx context.Context, t creditTable, id int64) (*models.Person, error) {
+	query := `SELECT ` + t.columns() + ` FROM ` + t.table + ` WHERE id = $2`
+
+	found, err := scanPerson(r.db.QueryRow(ctx, query, "", id))
+	if err != nil {
+		return nil, t.mapError(err)
+	}
+	return found, nil
+}
+
+func (r *CreditRepository) rename(ctx context.Context, t creditTable, id int64, name string) (*models.Person, error) {
+	query := `UPDATE ` + t.table + ` SET name = $2, updated_at = NOW() WHERE id = $3 RETURNING ` + t.columns()
+
+	updated, err := scanPerson(r.db.QueryRow(ctx, query, "", strings.TrimSpace(name), id))
+	if err != nil {
+		return nil, t.mapError(err)
+	}
+	return updated, nil
+}
+
+// Removes the credits too, the games stay
+func (r *CreditRepository) delete(ctx context.Context, t creditTable, id int64) error {
+	commandTag, err := r.db.Exec(ctx, `DELETE FROM `+t.table+` WHERE id = $1`, id)
+	if err != nil {
+		return ErrQueryFailed
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return t.notFound
+	}
+
+	return nil
+}
+
+// Ordered by name, only the ones credited in role when role is set
+func (r *CreditRepository) list(ctx context.Context, t creditTable, role string) ([]*models.Person, error) {
+	query := `SELECT ` + t.columns() + ` FROM ` + t.table + `
+		WHERE $1 = '' OR EXISTS (SELECT 1 FROM ` + t.links + ` l WHERE l.` + t.linkColumn + ` = ` + t.table + `.id AND ` + t.roleMatch + `)
+		ORDER BY LOWER(name)`
+
+	rows, err := r.db.Query(ctx, query, role)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	list := []*models.Person{}
+	for rows.Next() {
+		item, err := scanPerson(rows)
+		if err != nil {
+			return nil, ErrQueryFailed
+		}
+		list = append(list, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return list, nil
+}
+
+// Games in the user's households credited to one person or publisher, by name.
+// People and publishers themselves are shared by every user.
+func (r *CreditRepository) games(ctx context.Context, t creditTable, userID int64, id int64, role string) ([]*models.BoardGame, error) {
+	if _, err := r.getByID(ctx, t, id); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + boardGameColumns + ` FROM board_games
+		WHERE ` + memberOf("household_id", "$3") + `
+			AND id IN (SELECT l.board_game_id FROM ` + t.links + ` l WHERE l.` + t.linkColumn + ` = $2 AND ` + t.roleMatch + `)
+		ORDER BY LOWER(name), id`
+
+	rows, err := r.db.Query(ctx, query, role, id, userID)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	games := []*models.BoardGame{}
+	for rows.Next() {
+		game, err := scanBoardGame(rows)
+		if err != nil {
+			return nil, ErrQueryFailed
+		}
+		games = append(games, game)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return games, nil
+}
+
+func (r *CreditRepository) CreatePerson(ctx context.Context, person *models.Person) error {
+	created, err := r.create(ctx, peopleTable, person.Name)
+	if err != nil {
+		return err
+	}
+	*person = *created
+	return nil
+}
+
+func (r *CreditRepository) GetPeople(ctx context.Context, role string) ([]*models.Person, error) {
+	return r.list(ctx, peopleTable, role)
+}
+
+func (r *CreditRepository) GetPersonByID(ctx context.Context, id int64) (*models.Person, error) {
+	return r.getByID(ctx, peopleTable, id)
+}
+
+func (r *CreditRepository) UpdatePerson(ctx context.Context, person *models.Person) error {
+	updated, err := r.rename(ctx, peopleTable, person.ID, person.Name)
+	if err != nil {
+		return err
+	}
+	*person = *updated
+	return nil
+}
+
+func (r *CreditRepository) DeletePerson(ctx context.Context, id int64) error {
+	return r.delete(ctx, peopleTable, id)
+}
+
+func (r *CreditRepository) GetPersonGames(ctx context.Context, userID int64, id int64, role string) ([]*models.BoardGame, error) {
+	return r.games(ctx, peopleTable, userID, id, role)
+}
+
+func (r *CreditRepository) CreatePublisher(ctx context.Context, publisher *models.Publisher) error {
+	created, err := r.create(ctx, publishersTable, publisher.Name)
+	if err != nil {
+		return err
+	}
+	*publisher = models.Publisher(*created)
+	return nil
+}
+
+func (r *CreditRepository) GetPublishers(ctx context.Context) ([]*models.Publisher, error) {
+	list, err := r.list(ctx, publishersTable, "")
+	if err != nil {
+		return nil, err
+	}
+
+	publishers := make([]*models.Publisher, len(list))
+	for i, item := range list {
+		publisher := models.Publisher(*item)
+		publishers[i] = &publisher
+	}
+	return publishers, nil
+}
+
+func (r *CreditRepository) GetPublisherByID(ctx context.Context, id int64) (*models.Publisher, error) {
+	found, err := r.getByID(ctx, publishersTable, id)
+	if err != nil {
+		return nil, err
+	}
+	publisher := models.Publisher(*found)
+	return &publisher, nil
+}
+
+func (r *CreditRepository) UpdatePublisher(ctx context.Context, publisher *models.Publisher) error {
+	updated, err := r.rename(ctx, publishersTable, publisher.ID, publisher.Name)
+	if err != nil {
+		return err
+	}
+	*publisher = models.Publisher(*updated)
+	return nil
+}
+
+func (r *CreditRepository) DeletePublisher(ctx context.Context, id int64) error {
+	return r.delete(ctx, publishersTable, id)
+}
+
+func (r *CreditRepository) GetPublisherGames(ctx context.Context, userID int64, id int64) ([]*models.BoardGame, error) {
+	return r.games(ctx, publishersTable, userID, id, "")
+}
+
+// Replaces every designer, artist and publisher of the game, in the order given
+func (r *CreditRepository) SetBoardGameCredits(ctx context.Context, boardGameID int64, credits *models.BoardGameCredits) (*models.BoardGame, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	var id int64
+	err = tx.QueryRow(ctx, `SELECT id FROM board_games WHERE id = $1 FOR UPDATE`, boardGameID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrBoardGameNotFound
+		}
+		return nil, ErrQueryFailed
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM board_game_people WHERE board_game_id = $1`, boardGameID); err != nil {
+		return nil, ErrQueryFailed
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM board_game_publishers WHERE board_game_id = $1`, boardGameID); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	roles := []struct {
+		role    string
+		credits []models.CreditInput
+	}{
+		{models.RoleDesigner, credits.Designers},
+		{models.RoleArtist, credits.Artists},
+	}
+	for _, role := range roles {
+		for position, credit := range role.credits {
+			personID, err := resolveCredit(ctx, tx, peopleTable, credit)
+			if err != nil {
+				return nil, err
+			}
+
+			// Listing someone twice keeps the first position
+			_, err = tx.Exec(ctx, `INSERT INTO board_game_people (board_game_id, person_id, role, position)
+				VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`, boardGameID, personID, role.role, position)
+			if err != nil {
+				return nil, ErrQueryFailed
+			}
+		}
+	}
+
+	for position, credit := range credits.Publishers {
+		publisherID, err := resolveCredit(ctx, tx, publishersTable, credit.CreditInput)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = tx.Exec(ctx, `INSERT INTO board_game_publishers (board_game_id, publisher_id, year_published, position)
+			VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`, boardGameID, publisherID, credit.YearPublished, position)
+		if err != nil {
+			return nil, ErrQueryFailed
+		}
+	}
+
+	game, err := scanBoardGame(tx.QueryRow(ctx, `SELECT `+boardGameColumns+` FROM board_games WHERE id = $1`, boardGameID))
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return game, nil
+}
+
+// Id of the person or publisher, names that are not known yet are added
+func resolveCredit(ctx context.Context, tx pgx.Tx, t creditTable, credit models.CreditInput) (int64, error) {
+	var id int64
+	if credit.ID != nil {
+		err := tx.QueryRow(ctx, `SELECT id FROM `+t.table+` WHERE id = $1`, *credit.ID).Scan(&id)
+		if err != nil {
+			return 0, t.mapError(err)
+		}
+		return id, nil
+	}
+
+	// The no-op update makes RETURNING work for an existing name too
+	err := tx.QueryRow(ctx, `INSERT INTO `+t.table+` (name) VALUES ($1)
+		ON CONFLICT (LOWER(name)) DO UPDATE SET name = `+t.table+`.name
+		RETURNING id`, strings.TrimSpace(credit.Name)).Scan(&id)
+	if err != nil {
+		return 0, ErrQueryFailed
+	}
+	return id, nil
+}
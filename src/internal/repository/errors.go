@@ -4,8 +4,76 @@ import "errors"
 
 var (
 	// Board game errors
-	ErrBoardGameNotFound = errors.New("Board game not found")
-	ErrDuplicateName     = errors.New("Board game with this name already exists")
+	ErrBoardGameNotFound  = errors.New("Board game not found")
+	ErrDuplicateName      = errors.New("Board game with this name already exists")
+	ErrInvalidPatchField  = errors.New("Field cannot be patched")
+	ErrInvalidSort        = errors.New("Invalid sort field or order")
+	ErrInvalidCursor      = errors.New("Invalid pagination cursor")
+	ErrInvalidTagMatch    = errors.New("Invalid tag_match: must be all or any")
+	ErrHasExpansions      = errors.New("Board game has expansions")
+	ErrInvalidPlayerRange = errors.New("max_players must be at least min_players")
+
+	// Expansion errors
+	ErrExpansionCycle = errors.New("A game cannot expand itself or one of its own expansions")
+	ErrNotAnExpansion = errors.New("Board game is not an expansion")
+
+	// Image errors
+	ErrImageNotFound     = errors.New("Image not found")
+	ErrInvalidImageOrder = errors.New("Image order must list every gameplay image of the board game exactly once")
+	ErrCoverExists       = errors.New("Board game already has a cover image")
+
+	// Play session errors
+	ErrPlaySessionNotFound  = errors.New("Play session not found")
+	ErrDuplicateParticipant = errors.New("A player can only take part once in a play")
+
+	// Player errors
+	ErrPlayerNotFound      = errors.New("Player not found")
+	ErrDuplicatePlayerName = errors.New("A player with this name already exists")
+
+	// Tag errors
+	ErrTagNotFound      = errors.New("Tag not found")
+	ErrDuplicateTagName = errors.New("A tag with this name already exists")
+	ErrInvalidTagMerge  = errors.New("A tag cannot be merged into itself")
+
+	// People and publisher errors
+	ErrPersonNotFound         = errors.New("Person not found")
+	ErrDuplicatePersonName    = errors.New("A person with this name already exists")
+	ErrPublisherNotFound      = errors.New("Publisher not found")
+	ErrDuplicatePublisherName = errors.New("A publisher with this name already exists")
+
+	// Backup errors
+	ErrLegacyImages          = errors.New("Some images are still stored in the database, run migrate-images first")
+	ErrBackupVersionMismatch = errors.New("Backup does not match the database schema")
+	ErrDatabaseNotEmpty      = errors.New("Database is not empty, restore needs an empty schema")
+
+	// User errors
+	ErrUserNotFound    = errors.New("User not found")
+	ErrDuplicateEmail  = errors.New("An account with this email already exists")
+	ErrSessionNotFound = errors.New("Session not found or expired")
+
+	// OpenID Connect errors
+	ErrOIDCLoginNotFound     = errors.New("Sign in not found or expired, start again")
+	ErrIdentityEmailRequired = errors.New("The identity provider did not share an email address")
+	ErrIdentityNotLinked     = errors.New("An account with this email already exists, sign in with its password")
+
+	// Household errors
+	ErrHouseholdNotFound  = errors.New("Household not found")
+	ErrForbidden          = errors.New("Your role in this household does not allow this")
+	ErrMemberNotFound     = errors.New("Member not found")
+	ErrAlreadyMember      = errors.New("Already a member of this household")
+	ErrLastOwner          = errors.New("A household needs at least one owner")
+	ErrInvitationNotFound = errors.New("Invitation not found, already used or expired")
+
+	// Loan errors
+	ErrLoanNotFound      = errors.New("Loan not found")
+	ErrBoardGameLent     = errors.New("This game is already lent out")
+	ErrLoanReturned      = errors.New("This loan was already returned")
+	ErrBorrowerNotFound  = errors.New("Borrower not found")
+	ErrInvalidLoanDates  = errors.New("Due and return dates cannot be before the game was lent")
+	ErrInvalidLoanStatus = errors.New("Invalid status: must be active, overdue, returned or all")
+
+	// API key errors
+	ErrAPIKeyNotFound = errors.New("API key not found")
 
 	// Database errors
 	ErrQueryFailed = errors.New("Database query failed")
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Which games expand which
+type ExpansionRepository struct {
+	db *pgxpool.Pool
+}
+
+// Both games of a link are in the same household of userID, who must be
+// able to edit it to change links
+type ExpansionRepo interface {
+	SetBaseGame(ctx context.Context, userID int64, expansionID int64, link *models.ExpansionLink) (*models.BoardGame, error)
+	RemoveBaseGame(ctx context.Context, userID int64, expansionID int64) error
+	GetExpansions(ctx context.Context, userID int64, baseGameID int64) ([]*models.BoardGame, error)
+}
+
+func NewExpansionRepository(db *pgxpool.Pool) *ExpansionRepository {
+	return &ExpansionRepository{db: db}
+}
+
+// Makes the game an expansion of link.BaseGameID, replacing any base game it had.
+// Returns ErrExpansionCycle when the base game is the game itself or one of its expansions.
+func (r *ExpansionRepository) SetBaseGame(ctx context.Context, userID int64, expansionID int64, link *models.ExpansionLink) (*models.BoardGame, error) {
+	if expansionID == link.BaseGameID {
+		return nil, ErrExpansionCycle
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	// Two links changing at once could each pass the cycle check and close a loop together
+	if _, err := tx.Exec(ctx, `LOCK TABLE board_game_expansions IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	role, err := boardGameRole(ctx, tx, userID, expansionID)
+	if err != nil {
+		return nil, err
+	}
+	if !role.CanEdit() {
+		return nil, ErrForbidden
+	}
+
+	var sameHousehold bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM board_games e JOIN board_games b ON b.household_id = e.household_id
+		WHERE e.id = $1 AND b.id = $2)`, expansionID, link.BaseGameID).Scan(&sameHousehold)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	if !sameHousehold {
+		return nil, ErrBoardGameNotFound
+	}
+
+	// Walks up from the new base game, finding the expansion there would close a loop
+	var cycle bool
+	err = tx.QueryRow(ctx, `WITH RECURSIVE ancestors (id) AS (
+			SELECT $1::integer
+			UNION
+			SELECT bge.base_game_id FROM board_game_expansions bge JOIN ancestors ON bge.expansion_id = ancestors.id
+		)
+		SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2)`, link.BaseGameID, expansionID).Scan(&cycle)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	if cycle {
+		return nil, ErrExpansionCycle
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO board_game_expansions (expansion_id, base_game_id, relation, max_players, play_time)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (expansion_id) DO UPDATE SET base_game_id = EXCLUDED.base_game_id,
+			relation = EXCLUDED.relation, max_players = EXCLUDED.max_players, play_time = EXCLUDED.play_time`,
+		expansionID, link.BaseGameID, link.Relation, link.MaxPlayers, link.PlayTime)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	game, err := scanBoardGame(tx.QueryRow(ctx, `SELECT `+boardGameColumns+` FROM board_games WHERE id = $1`, expansionID))
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return game, nil
+}
+
+// The game stays, it just stops being an expansion
+func (r *ExpansionRepository) RemoveBaseGame(ctx context.Context, userID int64, expansionID int64) error {
+	commandTag, err := r.db.Exec(ctx, `DELETE FROM board_game_expansions
+		WHERE expansion_id = (SELECT id FROM board_games WHERE id = $1 AND `+editorOf("household_id", "$2")+`)`,
+		expansionID, userID)
+	if err != nil {
+		return ErrQueryFailed
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		role, err := boardGameRole(ctx, r.db, userID, expansionID)
+		if err != nil {
+			return err
+		}
+		if !role.CanEdit() {
+			return ErrForbidden
+		}
+		return ErrNotAnExpansion
+	}
+
+	return nil
+}
+
+// Direct expansions of the game, by name
+func (r *ExpansionRepository) GetExpansions(ctx context.Context, userID int64, baseGameID int64) ([]*models.BoardGame, error) {
+	if _, err := boardGameRole(ctx, r.db, userID, baseGameID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + boardGameColumns + ` FROM board_games
+		WHERE id IN (SELECT expansion_id FROM board_game_expansions WHERE base_game_id = $1)
+		ORDER BY LOWER(name), id`
+
+	rows, err := r.db.Query(ctx, query, baseGameID)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	games := []*models.BoardGame{}
+	for rows.Next() {
+		game, err := scanBoardGame(rows)
+		if err != nil {
+			return nil, ErrQueryFailed
+		}
+		games = append(games, game)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return games, nil
+}
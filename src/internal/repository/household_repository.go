@@ -0,0 +1,428 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Households, their members and invitations. Every method acts as userID:
+// households they are not a member of are not found, and only owners manage them.
+type HouseholdRepository struct {
+	db *pgxpool.Pool
+}
+
+type HouseholdRepo interface {
+	Create(ctx context.Context, userID int64, household *models.Household) error
+	GetAll(ctx context.Context, userID int64) ([]*models.Household, error)
+	GetByID(ctx context.Context, userID int64, id int64) (*models.Household, error)
+	Rename(ctx context.Context, userID int64, id int64, name string) (*models.Household, error)
+	CreateInvitation(ctx context.Context, userID int64, householdID int64, role models.HouseholdRole, tokenHash string, ttl time.Duration) (*models.Invitation, error)
+	AcceptInvitation(ctx context.Context, userID int64, tokenHash string) (*models.Household, error)
+	SetMemberRole(ctx context.Context, userID int64, householdID int64, memberID int64, role models.HouseholdRole) (*models.Household, error)
+	RemoveMember(ctx context.Context, userID int64, householdID int64, memberID int64) error
+}
+
+func NewHouseholdRepository(db *pgxpool.Pool) *HouseholdRepository {
+	return &HouseholdRepository{db: db}
+}
+
+// The pool or a transaction
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Condition keeping the rows whose household_id column is a household of the
+// user sent as the user placeholder ($n or ? for conditions)
+func memberOf(column string, user string) string {
+	return fmt.Sprintf(`%s IN (SELECT household_id FROM household_members WHERE user_id = %s)`, column, user)
+}
+
+// Same as memberOf, for the households where the user can change the games
+func editorOf(column string, user string) string {
+	return fmt.Sprintf(`%s IN (SELECT household_id FROM household_members
+		WHERE user_id = %s AND role IN ('owner', 'editor'))`, column, user)
+}
+
+// Role of the user in the household of the game, ErrBoardGameNotFound when
+// they are not a member
+func boardGameRole(ctx context.Context, q queryRower, userID int64, boardGameID int64) (models.HouseholdRole, error) {
+	var role models.HouseholdRole
+	err := q.QueryRow(ctx, `SELECT hm.role FROM board_games g
+		JOIN household_members hm ON hm.household_id = g.household_id
+		WHERE g.id = $1 AND hm.user_id = $2`, boardGameID, userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrBoardGameNotFound
+		}
+		return "", ErrQueryFailed
+	}
+	return role, nil
+}
+
+// Why a change limited to editorOf matched no game: ErrForbidden when the
+// user can see the game, ErrBoardGameNotFound otherwise
+func boardGameDenied(ctx context.Context, q queryRower, userID int64, boardGameID int64) error {
+	role, err := boardGameRole(ctx, q, userID, boardGameID)
+	if err != nil {
+		return err
+	}
+	if !role.CanEdit() {
+		return ErrForbidden
+	}
+	// Editable after all, it was deleted in between
+	return ErrBoardGameNotFound
+}
+
+// The household new games of the user go to: householdID, or when it is 0
+// the first household they joined where they can edit
+func editableHousehold(ctx context.Context, q queryRower, userID int64, householdID int64) (int64, error) {
+	var role models.HouseholdRole
+	err := q.QueryRow(ctx, `SELECT household_id, role FROM household_members
+		WHERE user_id = $1 AND ($2 = 0 OR household_id = $2)
+		ORDER BY role IN ('owner', 'editor') DESC, joined_at, household_id
+		LIMIT 1`, userID, householdID).Scan(&householdID, &role)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrQueryFailed
+		}
+		if householdID != 0 {
+			return 0, ErrHouseholdNotFound
+		}
+		return 0, ErrForbidden
+	}
+
+	if !role.CanEdit() {
+		return 0, ErrForbidden
+	}
+	return householdID, nil
+}
+
+// Role of the user in the household, ErrHouseholdNotFound when not a member
+func householdRole(ctx context.Context, q queryRower, userID int64, householdID int64) (models.HouseholdRole, error) {
+	var role models.HouseholdRole
+	err := q.QueryRow(ctx, `SELECT role FROM household_members WHERE household_id = $1 AND user_id = $2`,
+		householdID, userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrHouseholdNotFound
+		}
+		return "", ErrQueryFailed
+	}
+	return role, nil
+}
+
+// Why a change for owners only matched no household: ErrForbidden for other
+// members, ErrHouseholdNotFound otherwise
+func householdDenied(ctx context.Context, q queryRower, userID int64, householdID int64) error {
+	role, err := householdRole(ctx, q, userID, householdID)
+	if err != nil {
+		return err
+	}
+	if role != models.RoleOwner {
+		return ErrForbidden
+	}
+	return ErrHouseholdNotFound
+}
+
+// Inserts a household with the user as its owner, inside the caller's transaction
+func insertHousehold(ctx context.Context, tx pgx.Tx, userID int64, name string) (*models.Household, error) {
+	household := models.Household{Name: name, Role: models.RoleOwner}
+	err := tx.QueryRow(ctx, `INSERT INTO households (name) VALUES ($1) RETURNING id, created_at, updated_at`,
+		name).Scan(&household.ID, &household.CreatedAt, &household.UpdatedAt)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO household_members (household_id, user_id, role) VALUES ($1, $2, $3)`,
+		household.ID, userID, models.RoleOwner)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return &household, nil
+}
+
+// The user creating the household is its owner
+func (r *HouseholdRepository) Create(ctx context.Context, userID int64, household *models.Household) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return ErrQueryFailed
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	created, err := insertHousehold(ctx, tx, userID, household.Name)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ErrQueryFailed
+	}
+
+	*household = *created
+	return nil
+}
+
+// Households of the user with their role, in the order they joined them
+func (r *HouseholdRepository) GetAll(ctx context.Context, userID int64) ([]*models.Household, error) {
+	rows, err := r.db.Query(ctx, `SELECT h.id, h.name, hm.role, h.created_at, h.updated_at
+		FROM households h JOIN household_members hm ON hm.household_id = h.id
+		WHERE hm.user_id = $1
+		ORDER BY hm.joined_at, h.id`, userID)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	households := []*models.Household{}
+	for rows.Next() {
+		var household models.Household
+		if err := rows.Scan(&household.ID, &household.Name, &household.Role,
+			&household.CreatedAt, &household.UpdatedAt); err != nil {
+			return nil, ErrQueryFailed
+		}
+		households = append(households, &household)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return households, nil
+}
+
+// The household with its members
+func (r *HouseholdRepository) GetByID(ctx context.Context, userID int64, id int64) (*models.Household, error) {
+	var household models.Household
+	err := r.db.QueryRow(ctx, `SELECT h.id, h.name, hm.role, h.created_at, h.updated_at
+		FROM households h JOIN household_members hm ON hm.household_id = h.id
+		WHERE h.id = $1 AND hm.user_id = $2`, id, userID).Scan(
+		&household.ID, &household.Name, &household.Role, &household.CreatedAt, &household.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrHouseholdNotFound
+		}
+		return nil, ErrQueryFailed
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT u.id, u.email, u.display_name, hm.role, hm.joined_at
+		FROM household_members hm JOIN users u ON u.id = hm.user_id
+		WHERE hm.household_id = $1
+		ORDER BY hm.joined_at, u.id`, id)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var member models.HouseholdMember
+		if err := rows.Scan(&member.UserID, &member.Email, &member.DisplayName, &member.Role,
+			&member.JoinedAt); err != nil {
+			return nil, ErrQueryFailed
+		}
+		household.Members = append(household.Members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return &household, nil
+}
+
+// Owners only
+func (r *HouseholdRepository) Rename(ctx context.Context, userID int64, id int64, name string) (*models.Household, error) {
+	commandTag, err := r.db.Exec(ctx, `UPDATE households SET name = $1, updated_at = NOW()
+		WHERE id = $2 AND id IN (SELECT household_id FROM household_members WHERE user_id = $3 AND role = 'owner')`,
+		name, id, userID)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return nil, householdDenied(ctx, r.db, userID, id)
+	}
+
+	return r.GetByID(ctx, userID, id)
+}
+
+// Owners only. The invitation lasts ttl and joins the first user accepting
+// it with the role.
+func (r *HouseholdRepository) CreateInvitation(ctx context.Context, userID int64, householdID int64, role models.HouseholdRole, tokenHash string, ttl time.Duration) (*models.Invitation, error) {
+	query := `INSERT INTO household_invitations (household_id, role, token_hash, invited_by, expires_at)
+		SELECT $1, $2, $3, $4, NOW() + $5 * INTERVAL '1 second'
+		WHERE EXISTS (SELECT 1 FROM household_members WHERE household_id = $1 AND user_id = $4 AND role = 'owner')
+		RETURNING id, household_id, role, created_at, expires_at`
+
+	var invitation models.Invitation
+	err := r.db.QueryRow(ctx, query, householdID, role, tokenHash, userID, int64(ttl.Seconds())).Scan(
+		&invitation.ID, &invitation.HouseholdID, &invitation.Role, &invitation.CreatedAt, &invitation.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, householdDenied(ctx, r.db, userID, householdID)
+		}
+		return nil, ErrQueryFailed
+	}
+
+	return &invitation, nil
+}
+
+// Joins the user to the household of the invitation, which cannot be used
+// again. Members keep their role and the invitation stays open.
+func (r *HouseholdRepository) AcceptInvitation(ctx context.Context, userID int64, tokenHash string) (*models.Household, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	// Two users accepting at once: the second one waits on the row and no
+	// longer matches
+	var householdID int64
+	var role models.HouseholdRole
+	err = tx.QueryRow(ctx, `UPDATE household_invitations SET accepted_by = $2, accepted_at = NOW()
+		WHERE token_hash = $1 AND accepted_at IS NULL AND expires_at > NOW()
+		RETURNING household_id, role`, tokenHash, userID).Scan(&householdID, &role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, ErrQueryFailed
+	}
+
+	commandTag, err := tx.Exec(ctx, `INSERT INTO household_members (household_id, user_id, role)
+		VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`, householdID, userID, role)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	if commandTag.RowsAffected() == 0 {
+		return nil, ErrAlreadyMember
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return r.GetByID(ctx, userID, householdID)
+}
+
+// Owners only, the last owner cannot step down
+func (r *HouseholdRepository) SetMemberRole(ctx context.Context, userID int64, householdID int64, memberID int64, role models.HouseholdRole) (*models.Household, error) {
+	tx, err := r.lockHousehold(ctx, userID, householdID)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	if err := requireOwner(ctx, tx, userID, householdID); err != nil {
+		return nil, err
+	}
+
+	commandTag, err := tx.Exec(ctx, `UPDATE household_members SET role = $3 WHERE household_id = $1 AND user_id = $2`,
+		householdID, memberID, role)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	if commandTag.RowsAffected() == 0 {
+		return nil, ErrMemberNotFound
+	}
+
+	if err := ensureOwner(ctx, tx, householdID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return r.GetByID(ctx, userID, householdID)
+}
+
+// Owners remove anyone, other members only themselves. The last owner cannot leave.
+func (r *HouseholdRepository) RemoveMember(ctx context.Context, userID int64, householdID int64, memberID int64) error {
+	tx, err := r.lockHousehold(ctx, userID, householdID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	if memberID != userID {
+		if err := requireOwner(ctx, tx, userID, householdID); err != nil {
+			return err
+		}
+	}
+
+	commandTag, err := tx.Exec(ctx, `DELETE FROM household_members WHERE household_id = $1 AND user_id = $2`,
+		householdID, memberID)
+	if err != nil {
+		return ErrQueryFailed
+	}
+	if commandTag.RowsAffected() == 0 {
+		if memberID == userID {
+			return ErrHouseholdNotFound
+		}
+		return ErrMemberNotFound
+	}
+
+	if err := ensureOwner(ctx, tx, householdID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ErrQueryFailed
+	}
+
+	return nil
+}
+
+// Starts a transaction holding the household row, so role changes of its
+// members happen one at a time and the owner count stays right
+func (r *HouseholdRepository) lockHousehold(ctx context.Context, userID int64, householdID int64) (pgx.Tx, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	var id int64
+	err = tx.QueryRow(ctx, `SELECT id FROM households WHERE id = $1 AND `+memberOf("id", "$2")+` FOR UPDATE`,
+		householdID, userID).Scan(&id)
+	if err != nil {
+		tx.Rollback(ctx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrHouseholdNotFound
+		}
+		return nil, ErrQueryFailed
+	}
+
+	return tx, nil
+}
+
+func requireOwner(ctx context.Context, q queryRower, userID int64, householdID int64) error {
+	role, err := householdRole(ctx, q, userID, householdID)
+	if err != nil {
+		return err
+	}
+	if role != models.RoleOwner {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func ensureOwner(ctx context.Context, q queryRower, householdID int64) error {
+	var hasOwner bool
+	err := q.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM household_members WHERE household_id = $1 AND role = 'owner')`,
+		householdID).Scan(&hasOwner)
+	if err != nil {
+		return ErrQueryFailed
+	}
+	if !hasOwner {
+		return ErrLastOwner
+	}
+	return nil
+}
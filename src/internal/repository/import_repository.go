@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Board games coming from import files
+type ImportRepository struct {
+	db *pgxpool.Pool
+}
+
+type ImportRepo interface {
+	ImportBoardGames(ctx context.Context, userID int64, games []*models.ImportedBoardGame, dryRun bool) (*models.ImportReport, error)
+}
+
+func NewImportRepository(db *pgxpool.Pool) *ImportRepository {
+	return &ImportRepository{db: db}
+}
+
+// Creates the games not imported before to the first household the user can
+// edit (ErrForbidden when there is none) and updates the ones that were,
+// matching them by BGG id. A game with the name of one added by hand is a
+// conflict and left alone. Everything is written in one transaction, a dry
+// run rolls it back so the report shows exactly what would happen.
+// Covers are not saved here, entries say whether one should be attached.
+func (r *ImportRepository) ImportBoardGames(ctx context.Context, userID int64, games []*models.ImportedBoardGame, dryRun bool) (*models.ImportReport, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer tx.Rollback(ctx) // No-op once committed, how a dry run ends
+
+	householdID, err := editableHousehold(ctx, tx, userID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ImportReport{
+		DryRun:    dryRun,
+		Created:   []models.ImportEntry{},
+		Updated:   []models.ImportEntry{},
+		Conflicts: []models.ImportEntry{},
+	}
+	seen := map[int64]bool{}
+
+	for _, game := range games {
+		entry := models.ImportEntry{BGGID: *game.BGGID, Name: game.Name}
+
+		if reason := invalidImport(game); reason != "" {
+			entry.Reason = reason
+			report.Conflicts = append(report.Conflicts, entry)
+			continue
+		}
+		if seen[*game.BGGID] {
+			entry.Reason = "listed more than once in the file"
+			report.Conflicts = append(report.Conflicts, entry)
+			continue
+		}
+		seen[*game.BGGID] = true
+
+		var id int64
+		created := false
+		err := tx.QueryRow(ctx, `SELECT id FROM board_games WHERE bgg_id = $1 AND household_id = $2`,
+			*game.BGGID, householdID).Scan(&id)
+		switch {
+		case err == nil:
+			if err := updateImportedGame(ctx, tx, id, game); err != nil {
+				return nil, err
+			}
+		case errors.Is(err, pgx.ErrNoRows):
+			var existingID int64
+			err := tx.QueryRow(ctx, `SELECT id FROM board_games WHERE LOWER(name) = LOWER($1) AND household_id = $2
+				ORDER BY id LIMIT 1`, game.Name, householdID).Scan(&existingID)
+			if err == nil {
+				entry.BoardGameID = existingID
+				entry.Reason = fmt.Sprintf("board game %d already has this name", existingID)
+				report.Conflicts = append(report.Conflicts, entry)
+				continue
+			}
+			if !errors.Is(err, pgx.ErrNoRows) {
+				return nil, ErrQueryFailed
+			}
+
+			id, err = createImportedGame(ctx, tx, householdID, game)
+			if err != nil {
+				return nil, err
+			}
+			created = true
+		default:
+			return nil, ErrQueryFailed
+		}
+
+		if err := linkImportedGame(ctx, tx, id, game); err != nil {
+			return nil, err
+		}
+
+		entry.Cover, err = importedCover(ctx, tx, id, game)
+		if err != nil {
+			return nil, err
+		}
+
+		if created {
+			// The id of a dry run game is rolled back with it
+			if !dryRun {
+				entry.BoardGameID = id
+			}
+			report.Created = append(report.Created, entry)
+		} else {
+			entry.BoardGameID = id
+			report.Updated = append(report.Updated, entry)
+		}
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return report, nil
+}
+
+// Same rules as creating a game by hand, except for the description and age
+func invalidImport(game *models.ImportedBoardGame) string {
+	switch {
+	case strings.TrimSpace(game.Name) == "":
+		return "missing name"
+	case game.MinPlayers <= 0:
+		return "missing player count"
+	case game.MaxPlayers != 0 && game.MaxPlayers < game.MinPlayers:
+		return "max players below min players"
+	case game.PlayTime <= 0:
+		return "missing playing time"
+	}
+	return ""
+}
+
+// Zero means unknown, stored as NULL
+func nullIfZero(value int) *int {
+	if value == 0 {
+		return nil
+	}
+	return &value
+}
+
+func createImportedGame(ctx context.Context, tx pgx.Tx, householdID int64, game *models.ImportedBoardGame) (int64, error) {
+	var id int64
+	err := tx.QueryRow(ctx, `INSERT INTO board_games
+		(name, min_players, max_players, play_time, min_age, description, year_published, bgg_id, household_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`,
+		game.Name, game.MinPlayers, nullIfZero(game.MaxPlayers), game.PlayTime, game.MinAge, game.Description,
+		game.YearPublished, *game.BGGID, householdID).Scan(&id)
+	if err != nil {
+		return 0, ErrQueryFailed
+	}
+	return id, nil
+}
+
+// A collection export keeps the description and age already stored
+func updateImportedGame(ctx context.Context, tx pgx.Tx, id int64, game *models.ImportedBoardGame) error {
+	var minAge *int
+	var description *string
+	if !game.Partial {
+		minAge = &game.MinAge
+		description = &game.Description
+	}
+
+	_, err := tx.Exec(ctx, `UPDATE board_games
+		SET name = $1, min_players = $2, max_players = $3, play_time = $4,
+			min_age = COALESCE($5, min_age), description = COALESCE($6, description),
+			year_published = COALESCE($7, year_published), updated_at = NOW()
+		WHERE id = $8`,
+		game.Name, game.MinPlayers, nullIfZero(game.MaxPlayers), game.PlayTime, minAge, description,
+		game.YearPublished, id)
+	if err != nil {
+		return ErrQueryFailed
+	}
+	return nil
+}
+
+// Adds the categories and mechanics as tags, and the credits. What the game
+// already has stays, so tags added by hand survive a new import.
+func linkImportedGame(ctx context.Context, tx pgx.Tx, id int64, game *models.ImportedBoardGame) error {
+	tagKinds := []struct {
+		kind  string
+		names []string
+	}{
+		{models.TagKindCategory, game.Categories},
+		{models.TagKindMechanic, game.Mechanics},
+	}
+	for _, tagKind := range tagKinds {
+		for _, name := range tagKind.names {
+			// A tag that exists with another kind is used as it is
+			var tagID int64
+			err := tx.QueryRow(ctx, `INSERT INTO tags (name, kind) VALUES ($1, $2)
+				ON CONFLICT (LOWER(name)) DO UPDATE SET name = tags.name
+				RETURNING id`, name, tagKind.kind).Scan(&tagID)
+			if err != nil {
+				return ErrQueryFailed
+			}
+
+			_, err = tx.Exec(ctx, `INSERT INTO board_game_tags (board_game_id, tag_id) VALUES ($1, $2)
+				ON CONFLICT DO NOTHING`, id, tagID)
+			if err != nil {
+				return ErrQueryFailed
+			}
+		}
+	}
+
+	roles := []struct {
+		role  string
+		names []string
+	}{
+		{models.RoleDesigner, game.Designers},
+		{models.RoleArtist, game.Artists},
+	}
+	for _, role := range roles {
+		for position, name := range role.names {
+			personID, err := resolveCredit(ctx, tx, peopleTable, models.CreditInput{Name: name})
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.Exec(ctx, `INSERT INTO board_game_people (board_game_id, person_id, role, position)
+				VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`, id, personID, role.role, position)
+			if err != nil {
+				return ErrQueryFailed
+			}
+		}
+	}
+
+	for position, name := range game.Publishers {
+		publisherID, err := resolveCredit(ctx, tx, publishersTable, models.CreditInput{Name: name})
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `INSERT INTO board_game_publishers (board_game_id, publisher_id, position)
+			VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`, id, publisherID, position)
+		if err != nil {
+			return ErrQueryFailed
+		}
+	}
+
+	return nil
+}
+
+// Whether the bundled cover should be attached, a cover already there is kept
+func importedCover(ctx context.Context, tx pgx.Tx, id int64, game *models.ImportedBoardGame) (string, error) {
+	if game.Cover == nil {
+		if game.CoverURL != "" {
+			return models.CoverNotBundled, nil
+		}
+		return "", nil
+	}
+
+	var hasCover bool
+	err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM board_game_images WHERE board_game_id = $1 AND image_type = 'cover')`,
+		id).Scan(&hasCover)
+	if err != nil {
+		return "", ErrQueryFailed
+	}
+	if hasCover {
+		return models.CoverKept, nil
+	}
+	return models.CoverAttached, nil
+}
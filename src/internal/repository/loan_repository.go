@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Games lent out of the households of the user. Members see the loans of
+// their games, editors lend and take them back.
+type LoanRepository struct {
+	db *pgxpool.Pool
+}
+
+type LoanRepo interface {
+	Lend(ctx context.Context, userID int64, boardGameID int64, input *models.LoanInput) (*models.Loan, error)
+	Return(ctx context.Context, userID int64, id int64, input *models.LoanReturn) (*models.Loan, error)
+	GetAll(ctx context.Context, userID int64, status string) ([]*models.Loan, error)
+	GetForBoardGame(ctx context.Context, userID int64, boardGameID int64) ([]*models.Loan, error)
+}
+
+func NewLoanRepository(db *pgxpool.Pool) *LoanRepository {
+	return &LoanRepository{db: db}
+}
+
+// Selected from loans l joined with their game as g
+const loanColumns = `l.id, l.board_game_id, g.name, l.borrower_name, l.borrower_user_id, l.lent_at, l.due_at,
+	l.returned_at, COALESCE(l.condition_note, ''), ` + loanOverdue + `, l.created_at, l.updated_at`
+
+const loanOverdue = `COALESCE(l.returned_at IS NULL AND l.due_at < NOW(), FALSE)`
+
+// Active loans first, the soonest due at the top, then the latest returned
+const loanOrder = ` ORDER BY l.returned_at DESC NULLS FIRST, l.due_at NULLS LAST, l.lent_at DESC, l.id DESC`
+
+func scanLoan(row pgx.Row) (*models.Loan, error) {
+	var loan models.Loan
+	err := row.Scan(&loan.ID, &loan.BoardGameID, &loan.BoardGameName, &loan.BorrowerName, &loan.BorrowerUserID,
+		&loan.LentAt, &loan.DueAt, &loan.ReturnedAt, &loan.ConditionNote, &loan.Overdue,
+		&loan.CreatedAt, &loan.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+func mapLoanError(err error) error {
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return ErrLoanNotFound
+	case errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_loans_active":
+		return ErrBoardGameLent
+	case errors.As(err, &pgErr) && pgErr.Code == "23514" && pgErr.ConstraintName == "check_loan_dates":
+		return ErrInvalidLoanDates
+	default:
+		return ErrQueryFailed
+	}
+}
+
+// Fails with ErrBoardGameLent while the game is away. A borrower with an
+// account must be a member of the game's household, their display name is
+// used when the input has no name.
+func (r *LoanRepository) Lend(ctx context.Context, userID int64, boardGameID int64, input *models.LoanInput) (*models.Loan, error) {
+	role, err := boardGameRole(ctx, r.db, userID, boardGameID)
+	if err != nil {
+		return nil, err
+	}
+	if !role.CanEdit() {
+		return nil, ErrForbidden
+	}
+
+	name := input.BorrowerName
+	if input.BorrowerUserID != nil {
+		var displayName string
+		err := r.db.QueryRow(ctx, `SELECT u.display_name FROM users u
+			JOIN household_members hm ON hm.user_id = u.id
+			JOIN board_games g ON g.household_id = hm.household_id
+			WHERE u.id = $1 AND g.id = $2`, *input.BorrowerUserID, boardGameID).Scan(&displayName)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, ErrBorrowerNotFound
+			}
+			return nil, ErrQueryFailed
+		}
+		if name == "" {
+			name = displayName
+		}
+	}
+
+	query := `WITH lent AS (
+			INSERT INTO loans (board_game_id, borrower_name, borrower_user_id, lent_at, due_at)
+			VALUES ($1, $2, $3, COALESCE($4, NOW()), $5)
+			RETURNING *
+		)
+		SELECT ` + loanColumns + ` FROM lent l JOIN board_games g ON g.id = l.board_game_id`
+
+	loan, err := scanLoan(r.db.QueryRow(ctx, query, boardGameID, name, input.BorrowerUserID, input.LentAt, input.DueAt))
+	if err != nil {
+		return nil, mapLoanError(err)
+	}
+
+	return loan, nil
+}
+
+// Records the game as back home. Fails with ErrLoanReturned the second time.
+func (r *LoanRepository) Return(ctx context.Context, userID int64, id int64, input *models.LoanReturn) (*models.Loan, error) {
+	query := `UPDATE loans l SET returned_at = COALESCE($3, NOW()), condition_note = NULLIF($4, ''), updated_at = NOW()
+		FROM board_games g
+		WHERE l.id = $1 AND g.id = l.board_game_id AND l.returned_at IS NULL AND ` + editorOf("g.household_id", "$2") + `
+		RETURNING ` + loanColumns
+
+	loan, err := scanLoan(r.db.QueryRow(ctx, query, id, userID, input.ReturnedAt, input.ConditionNote))
+	if err == nil {
+		return loan, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, mapLoanError(err)
+	}
+
+	// Nothing updated: unknown loan, already back, or a game the user only sees
+	var boardGameID int64
+	var returned bool
+	err = r.db.QueryRow(ctx, `SELECT l.board_game_id, l.returned_at IS NOT NULL FROM loans l
+		JOIN board_games g ON g.id = l.board_game_id
+		WHERE l.id = $1 AND `+memberOf("g.household_id", "$2"), id, userID).Scan(&boardGameID, &returned)
+	if err != nil {
+		return nil, mapLoanError(err)
+	}
+	if returned {
+		return nil, ErrLoanReturned
+	}
+	if err := boardGameDenied(ctx, r.db, userID, boardGameID); !errors.Is(err, ErrBoardGameNotFound) {
+		return nil, err
+	}
+	return nil, ErrLoanNotFound
+}
+
+// Loans of every game the user can see: active (lent out, overdue included),
+// overdue, returned or all
+func (r *LoanRepository) GetAll(ctx context.Context, userID int64, status string) ([]*models.Loan, error) {
+	filters := &conditions{}
+	filters.add(memberOf("g.household_id", "?"), userID)
+	switch status {
+	case "", models.LoanStatusActive:
+		filters.add("l.returned_at IS NULL")
+	case models.LoanStatusOverdue:
+		filters.add(loanOverdue)
+	case models.LoanStatusReturned:
+		filters.add("l.returned_at IS NOT NULL")
+	case models.LoanStatusAll:
+	default:
+		return nil, ErrInvalidLoanStatus
+	}
+
+	query := `SELECT ` + loanColumns + ` FROM loans l JOIN board_games g ON g.id = l.board_game_id` +
+		filters.where() + loanOrder
+
+	return r.queryLoans(ctx, query, filters.args...)
+}
+
+// Every loan of the game, the current one first
+func (r *LoanRepository) GetForBoardGame(ctx context.Context, userID int64, boardGameID int64) ([]*models.Loan, error) {
+	if _, err := boardGameRole(ctx, r.db, userID, boardGameID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + loanColumns + ` FROM loans l JOIN board_games g ON g.id = l.board_game_id
+		WHERE l.board_game_id = $1` + loanOrder
+
+	return r.queryLoans(ctx, query, boardGameID)
+}
+
+func (r *LoanRepository) queryLoans(ctx context.Context, query string, args ...any) ([]*models.Loan, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	loans := []*models.Loan{}
+	for rows.Next() {
+		loan, err := scanLoan(rows)
+		if err != nil {
+			return nil, ErrQueryFailed
+		}
+		loans = append(loans, loan)
+	}
+	if rows.Err() != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return loans, nil
+}
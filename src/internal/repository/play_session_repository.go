@@ -0,0 +1,339 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Plays belong to the household of their game. The routes under a game check
+// the user can see it, or change it, the recent plays are limited here.
+type PlaySessionRepository struct {
+	db *pgxpool.Pool
+}
+
+type PlaySessionRepo interface {
+	Create(ctx context.Context, session *models.PlaySession) error
+	GetAllForBoardGame(ctx context.Context, boardGameID int64) ([]*models.PlaySession, error)
+	GetRecent(ctx context.Context, userID int64, limit int) ([]*models.PlaySession, error)
+	GetByID(ctx context.Context, boardGameID int64, id int64) (*models.PlaySession, error)
+	Update(ctx context.Context, session *models.PlaySession) error
+	Delete(ctx context.Context, boardGameID int64, id int64) error
+}
+
+func NewPlaySessionRepository(db *pgxpool.Pool) *PlaySessionRepository {
+	return &PlaySessionRepository{db: db}
+}
+
+// Columns read for every session, in the order scanPlaySession expects
+const playSessionColumns = `id, board_game_id, board_game_name, played_at, COALESCE(duration_minutes, 0),
+	COALESCE(location, ''), COALESCE(notes, ''), created_at, updated_at`
+
+func scanPlaySession(row pgx.Row) (*models.PlaySession, error) {
+	var session models.PlaySession
+	err := row.Scan(
+		&session.ID,
+		&session.BoardGameID,
+		&session.BoardGameName,
+		&session.PlayedAt,
+		&session.DurationMinutes,
+		&session.Location,
+		&session.Notes,
+		&session.CreatedAt,
+		&session.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	session.Players = []models.PlaySessionPlayer{}
+	return &session, nil
+}
+
+// Creates the session and its players, the game name and household are copied from board_games
+func (r *PlaySessionRepository) Create(ctx context.Context, session *models.PlaySession) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return ErrQueryFailed
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	query := `INSERT INTO play_sessions
+		(board_game_id, board_game_name, household_id, played_at, duration_minutes, location, notes)
+		SELECT id, name, household_id, $2, NULLIF($3, 0), NULLIF($4, ''), NULLIF($5, '')
+		FROM board_games WHERE id = $1
+		RETURNING id, board_game_name, household_id, created_at, updated_at`
+
+	var householdID int64
+	err = tx.QueryRow(ctx, query,
+		session.BoardGameID,
+		session.PlayedAt,
+		session.DurationMinutes,
+		session.Location,
+		session.Notes,
+	).Scan(&session.ID, &session.BoardGameName, &householdID, &session.CreatedAt, &session.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrBoardGameNotFound
+		}
+		return ErrQueryFailed
+	}
+
+	if err := insertPlaySessionPlayers(ctx, tx, householdID, session.ID, session.Players); err != nil {
+		return err
+	}
+
+	if err := updateRatingsAfterCreate(ctx, tx, session); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ErrQueryFailed
+	}
+
+	return nil
+}
+
+// Links every participant to a player of the household first, players given
+// by name are found or added. ErrDuplicateParticipant when two of them are the
+// same player, e.g. by id and by name.
+func insertPlaySessionPlayers(ctx context.Context, tx pgx.Tx, householdID int64, sessionID int64, players []models.PlaySessionPlayer) error {
+	seen := make(map[int64]bool, len(players))
+	for position := range players {
+		player := &players[position]
+		if err := resolvePlayer(ctx, tx, householdID, player); err != nil {
+			return err
+		}
+		if seen[*player.PlayerID] {
+			return ErrDuplicateParticipant
+		}
+		seen[*player.PlayerID] = true
+
+		_, err := tx.Exec(ctx, `INSERT INTO play_session_players
+			(play_session_id, position, player_id, player_name, score, finish_rank, team, winner, first_player)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			sessionID, position, player.PlayerID, player.Name, player.Score, player.Rank, player.Team,
+			player.Winner, player.FirstPlayer)
+		if err != nil {
+			return ErrQueryFailed
+		}
+	}
+	return nil
+}
+
+// Sets PlayerID and Name from the roster of the household, players of other
+// households are not found
+func resolvePlayer(ctx context.Context, tx pgx.Tx, householdID int64, player *models.PlaySessionPlayer) error {
+	var err error
+	if player.PlayerID != nil {
+		err = tx.QueryRow(ctx, `SELECT name FROM players WHERE id = $1 AND household_id = $2`,
+			*player.PlayerID, householdID).Scan(&player.Name)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrPlayerNotFound
+		}
+	} else {
+		// The no-op update makes RETURNING work for an existing name too
+		var id int64
+		err = tx.QueryRow(ctx, `INSERT INTO players (name, household_id) VALUES ($1, $2)
+			ON CONFLICT (household_id, LOWER(name)) DO UPDATE SET name = players.name
+			RETURNING id, name`, strings.TrimSpace(player.Name), householdID).Scan(&id, &player.Name)
+		player.PlayerID = &id
+	}
+	if err != nil {
+		return ErrQueryFailed
+	}
+	return nil
+}
+
+// Sessions of one game, most recent first
+func (r *PlaySessionRepository) GetAllForBoardGame(ctx context.Context, boardGameID int64) ([]*models.PlaySession, error) {
+	query := `SELECT ` + playSessionColumns + ` FROM play_sessions
+		WHERE board_game_id = $1
+		ORDER BY played_at DESC, id DESC`
+
+	return r.getSessions(ctx, query, boardGameID)
+}
+
+// Latest sessions in the households of the user, including the ones of games deleted since
+func (r *PlaySessionRepository) GetRecent(ctx context.Context, userID int64, limit int) ([]*models.PlaySession, error) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	query := `SELECT ` + playSessionColumns + ` FROM play_sessions
+		WHERE ` + memberOf("household_id", "$2") + `
+		ORDER BY played_at DESC, id DESC
+		LIMIT $1`
+
+	return r.getSessions(ctx, query, limit, userID)
+}
+
+func (r *PlaySessionRepository) getSessions(ctx context.Context, query string, args ...any) ([]*models.PlaySession, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	sessions := []*models.PlaySession{}
+	for rows.Next() {
+		session, err := scanPlaySession(rows)
+		if err != nil {
+			return nil, ErrQueryFailed
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+	rows.Close()
+
+	if err := r.loadPlayers(ctx, sessions); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// Fills the players of all the sessions with a single query
+func (r *PlaySessionRepository) loadPlayers(ctx context.Context, sessions []*models.PlaySession) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(sessions))
+	byID := make(map[int64]*models.PlaySession, len(sessions))
+	for i, session := range sessions {
+		ids[i] = session.ID
+		byID[session.ID] = session
+	}
+
+	// The current name of the player, or the one logged if the player is gone
+	rows, err := r.db.Query(ctx, `SELECT psp.play_session_id, psp.player_id, COALESCE(p.name, psp.player_name),
+			psp.score, psp.finish_rank, psp.team, psp.winner, psp.first_player
+		FROM play_session_players psp
+		LEFT JOIN players p ON p.id = psp.player_id
+		WHERE psp.play_session_id = ANY($1)
+		ORDER BY psp.play_session_id, psp.position`, ids)
+	if err != nil {
+		return ErrQueryFailed
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sessionID int64
+		var player models.PlaySessionPlayer
+		if err := rows.Scan(&sessionID, &player.PlayerID, &player.Name, &player.Score, &player.Rank, &player.Team,
+			&player.Winner, &player.FirstPlayer); err != nil {
+			return ErrQueryFailed
+		}
+		byID[sessionID].Players = append(byID[sessionID].Players, player)
+	}
+
+	if err := rows.Err(); err != nil {
+		return ErrQueryFailed
+	}
+
+	return nil
+}
+
+func (r *PlaySessionRepository) GetByID(ctx context.Context, boardGameID int64, id int64) (*models.PlaySession, error) {
+	query := `SELECT ` + playSessionColumns + ` FROM play_sessions WHERE id = $1 AND board_game_id = $2`
+
+	session, err := scanPlaySession(r.db.QueryRow(ctx, query, id, boardGameID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPlaySessionNotFound
+		}
+		return nil, ErrQueryFailed
+	}
+
+	if err := r.loadPlayers(ctx, []*models.PlaySession{session}); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// Replaces the session details and its whole player list
+func (r *PlaySessionRepository) Update(ctx context.Context, session *models.PlaySession) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return ErrQueryFailed
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	query := `UPDATE play_sessions
+		SET played_at = $1, duration_minutes = NULLIF($2, 0), location = NULLIF($3, ''), notes = NULLIF($4, ''),
+			updated_at = NOW()
+		WHERE id = $5 AND board_game_id = $6
+		RETURNING board_game_name, household_id, created_at, updated_at`
+
+	var householdID int64
+	err = tx.QueryRow(ctx, query,
+		session.PlayedAt,
+		session.DurationMinutes,
+		session.Location,
+		session.Notes,
+		session.ID,
+		session.BoardGameID,
+	).Scan(&session.BoardGameName, &householdID, &session.CreatedAt, &session.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrPlaySessionNotFound
+		}
+		return ErrQueryFailed
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM play_session_players WHERE play_session_id = $1`, session.ID); err != nil {
+		return ErrQueryFailed
+	}
+	if err := insertPlaySessionPlayers(ctx, tx, householdID, session.ID, session.Players); err != nil {
+		return err
+	}
+
+	// Editing the past changes every rating that came after it
+	if err := recomputeRatings(ctx, tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ErrQueryFailed
+	}
+
+	return nil
+}
+
+func (r *PlaySessionRepository) Delete(ctx context.Context, boardGameID int64, id int64) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return ErrQueryFailed
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	commandTag, err := tx.Exec(ctx, `DELETE FROM play_sessions WHERE id = $1 AND board_game_id = $2`, id, boardGameID)
+	if err != nil {
+		return ErrQueryFailed
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrPlaySessionNotFound
+	}
+
+	if err := recomputeRatings(ctx, tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ErrQueryFailed
+	}
+
+	return nil
+}
@@ -0,0 +1,386 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/helpers"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/storage"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Players of the households of the user, every method acts as userID: members
+// see them, editors change them. Avatars live in the BlobStore like board game
+// images.
+type PlayerRepository struct {
+	db    *pgxpool.Pool
+	store storage.BlobStore
+}
+
+type PlayerRepo interface {
+	Create(ctx context.Context, userID int64, player *models.Player) error
+	GetAll(ctx context.Context, userID int64) ([]*models.Player, error)
+	GetByID(ctx context.Context, userID int64, id int64) (*models.Player, error)
+	Update(ctx context.Context, userID int64, player *models.Player) error
+	Delete(ctx context.Context, userID int64, id int64) error
+	SetAvatar(ctx context.Context, userID int64, id int64, data []byte, mimeType string) error
+	GetAvatar(ctx context.Context, userID int64, id int64) (*models.PlayerAvatar, error)
+	DeleteAvatar(ctx context.Context, userID int64, id int64) error
+	GetStats(ctx context.Context, userID int64, id int64) (*models.PlayerStats, error)
+}
+
+func NewPlayerRepository(db *pgxpool.Pool, store storage.BlobStore) *PlayerRepository {
+	return &PlayerRepository{db: db, store: store}
+}
+
+const playerColumns = `id, name, COALESCE(household_id, 0), avatar_hash, created_at, updated_at`
+
+func scanPlayer(row pgx.Row) (*models.Player, error) {
+	var player models.Player
+	var avatarHash *string
+	err := row.Scan(&player.ID, &player.Name, &player.HouseholdID, &avatarHash, &player.CreatedAt, &player.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if avatarHash != nil {
+		player.AvatarURL = models.PlayerAvatarURL(player.ID, models.ImageVersion(*avatarHash))
+	}
+	return &player, nil
+}
+
+// Names are unique in a household whatever their case
+func isDuplicatePlayerName(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_players_name"
+}
+
+// Role of the user in the household of the player, ErrPlayerNotFound when
+// they are not a member
+func playerRole(ctx context.Context, q queryRower, userID int64, playerID int64) (models.HouseholdRole, error) {
+	var role models.HouseholdRole
+	err := q.QueryRow(ctx, `SELECT hm.role FROM players p
+		JOIN household_members hm ON hm.household_id = p.household_id
+		WHERE p.id = $1 AND hm.user_id = $2`, playerID, userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrPlayerNotFound
+		}
+		return "", ErrQueryFailed
+	}
+	return role, nil
+}
+
+// ErrForbidden unless the user can change the player
+func requirePlayerEditor(ctx context.Context, q queryRower, userID int64, playerID int64) error {
+	role, err := playerRole(ctx, q, userID, playerID)
+	if err != nil {
+		return err
+	}
+	if !role.CanEdit() {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// Why a change limited to editorOf matched no player: ErrForbidden when the
+// user can see the player, ErrPlayerNotFound otherwise
+func playerDenied(ctx context.Context, q queryRower, userID int64, playerID int64) error {
+	if err := requirePlayerEditor(ctx, q, userID, playerID); err != nil {
+		return err
+	}
+	// Editable after all, it was deleted in between
+	return ErrPlayerNotFound
+}
+
+// The player joins player.HouseholdID, or the first household the user can edit
+func (r *PlayerRepository) Create(ctx context.Context, userID int64, player *models.Player) error {
+	householdID, err := editableHousehold(ctx, r.db, userID, player.HouseholdID)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO players (name, household_id) VALUES ($1, $2) RETURNING ` + playerColumns
+
+	created, err := scanPlayer(r.db.QueryRow(ctx, query, player.Name, householdID))
+	if err != nil {
+		if isDuplicatePlayerName(err) {
+			return ErrDuplicatePlayerName
+		}
+		return ErrQueryFailed
+	}
+
+	*player = *created
+	return nil
+}
+
+func (r *PlayerRepository) GetAll(ctx context.Context, userID int64) ([]*models.Player, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+playerColumns+` FROM players
+		WHERE `+memberOf("household_id", "$1")+` ORDER BY LOWER(name), id`, userID)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	players := []*models.Player{}
+	for rows.Next() {
+		player, err := scanPlayer(rows)
+		if err != nil {
+			return nil, ErrQueryFailed
+		}
+		players = append(players, player)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return players, nil
+}
+
+func (r *PlayerRepository) GetByID(ctx context.Context, userID int64, id int64) (*models.Player, error) {
+	player, err := scanPlayer(r.db.QueryRow(ctx, `SELECT `+playerColumns+` FROM players
+		WHERE id = $1 AND `+memberOf("household_id", "$2"), id, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPlayerNotFound
+		}
+		return nil, ErrQueryFailed
+	}
+
+	return player, nil
+}
+
+// Renames the player, past sessions show the new name
+func (r *PlayerRepository) Update(ctx context.Context, userID int64, player *models.Player) error {
+	query := `UPDATE players SET name = $1, updated_at = NOW()
+		WHERE id = $2 AND ` + editorOf("household_id", "$3") + `
+		RETURNING ` + playerColumns
+
+	updated, err := scanPlayer(r.db.QueryRow(ctx, query, player.Name, player.ID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return playerDenied(ctx, r.db, userID, player.ID)
+		}
+		if isDuplicatePlayerName(err) {
+			return ErrDuplicatePlayerName
+		}
+		return ErrQueryFailed
+	}
+
+	*player = *updated
+	return nil
+}
+
+// Sessions keep the name the player had, only the link is removed.
+// The others' ratings are replayed without the games against the player.
+func (r *PlayerRepository) Delete(ctx context.Context, userID int64, id int64) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return ErrQueryFailed
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	var avatarKey *string
+	err = tx.QueryRow(ctx, `DELETE FROM players WHERE id = $1 AND `+editorOf("household_id", "$2")+`
+		RETURNING avatar_key`, id, userID).Scan(&avatarKey)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return playerDenied(ctx, tx, userID, id)
+		}
+		return ErrQueryFailed
+	}
+
+	if err := recomputeRatings(ctx, tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ErrQueryFailed
+	}
+
+	r.deleteAvatarBlob(ctx, avatarKey)
+	return nil
+}
+
+// Stores a new avatar and removes the previous one. The blob is written first
+// and removed again when the user can't edit the player.
+func (r *PlayerRepository) SetAvatar(ctx context.Context, userID int64, id int64, data []byte, mimeType string) error {
+	key, err := storage.NewKey(fmt.Sprintf("players/%d", id))
+	if err != nil {
+		return err
+	}
+	key += "/avatar"
+	if err := r.store.Put(ctx, key, data, mimeType); err != nil {
+		return err
+	}
+
+	// The old key comes from a subquery, RETURNING only sees the new values
+	var previousKey *string
+	err = r.db.QueryRow(ctx, `UPDATE players p
+		SET avatar_key = $1, avatar_mime_type = $2, avatar_hash = $3, updated_at = NOW()
+		FROM (SELECT id, avatar_key FROM players
+			WHERE id = $4 AND `+editorOf("household_id", "$5")+` FOR UPDATE) previous
+		WHERE p.id = previous.id
+		RETURNING previous.avatar_key`, key, mimeType, helpers.ContentHash(data), id, userID).Scan(&previousKey)
+	if err != nil {
+		_ = r.store.Delete(ctx, key)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return playerDenied(ctx, r.db, userID, id)
+		}
+		return ErrQueryFailed
+	}
+
+	r.deleteAvatarBlob(ctx, previousKey)
+	return nil
+}
+
+func (r *PlayerRepository) GetAvatar(ctx context.Context, userID int64, id int64) (*models.PlayerAvatar, error) {
+	var avatar models.PlayerAvatar
+	var key *string
+	err := r.db.QueryRow(ctx, `SELECT avatar_key, COALESCE(avatar_mime_type, ''), COALESCE(avatar_hash, ''), updated_at
+		FROM players WHERE id = $1 AND `+memberOf("household_id", "$2"), id, userID).Scan(&key, &avatar.MimeType, &avatar.ContentHash, &avatar.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPlayerNotFound
+		}
+		return nil, ErrQueryFailed
+	}
+	if key == nil {
+		return nil, ErrImageNotFound
+	}
+
+	if avatar.Data, err = r.store.Get(ctx, *key); err != nil {
+		return nil, err
+	}
+
+	return &avatar, nil
+}
+
+func (r *PlayerRepository) DeleteAvatar(ctx context.Context, userID int64, id int64) error {
+	var previousKey *string
+	err := r.db.QueryRow(ctx, `UPDATE players p
+		SET avatar_key = NULL, avatar_mime_type = NULL, avatar_hash = NULL, updated_at = NOW()
+		FROM (SELECT id, avatar_key FROM players
+			WHERE id = $1 AND `+editorOf("household_id", "$2")+` FOR UPDATE) previous
+		WHERE p.id = previous.id
+		RETURNING previous.avatar_key`, id, userID).Scan(&previousKey)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return playerDenied(ctx, r.db, userID, id)
+		}
+		return ErrQueryFailed
+	}
+	if previousKey == nil {
+		return ErrImageNotFound
+	}
+
+	r.deleteAvatarBlob(ctx, previousKey)
+	return nil
+}
+
+// Best effort, an orphaned blob only costs disk space
+func (r *PlayerRepository) deleteAvatarBlob(ctx context.Context, key *string) {
+	if key != nil {
+		_ = r.store.Delete(ctx, *key)
+	}
+}
+
+// Games played, wins and streaks, from the plays of the player's household.
+// Games show their current name, plays of deleted games still count and are
+// grouped by the name the game had.
+func (r *PlayerRepository) GetStats(ctx context.Context, userID int64, id int64) (*models.PlayerStats, error) {
+	player, err := r.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.PlayerStats{
+		PlayerID: player.ID,
+		Name:     player.Name,
+		PerGame:  []*models.PlayerGameStats{},
+	}
+
+	// Most played first, so the first row is the favourite game
+	rows, err := r.db.Query(ctx, `SELECT s.board_game_id, COALESCE(g.name, MIN(s.board_game_name)), COUNT(*),
+			COUNT(*) FILTER (WHERE psp.winner), MAX(s.played_at)
+		FROM play_session_players psp
+		JOIN play_sessions s ON s.id = psp.play_session_id
+		LEFT JOIN board_games g ON g.id = s.board_game_id
+		WHERE psp.player_id = $1 AND s.household_id = $2
+		GROUP BY s.board_game_id, g.name, CASE WHEN s.board_game_id IS NULL THEN s.board_game_name END
+		ORDER BY COUNT(*) DESC, MAX(s.played_at) DESC`, id, player.HouseholdID)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var game models.PlayerGameStats
+		if err := rows.Scan(&game.BoardGameID, &game.BoardGameName, &game.Plays, &game.Wins, &game.LastPlayedAt); err != nil {
+			return nil, ErrQueryFailed
+		}
+		game.WinRate = winRate(game.Wins, game.Plays)
+		stats.GamesPlayed += game.Plays
+		stats.Wins += game.Wins
+		stats.PerGame = append(stats.PerGame, &game)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+	rows.Close()
+
+	stats.WinRate = winRate(stats.Wins, stats.GamesPlayed)
+	if len(stats.PerGame) > 0 {
+		stats.FavouriteGame = stats.PerGame[0]
+	}
+
+	if stats.LongestWinStreak, err = r.longestWinStreak(ctx, id, player.HouseholdID); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// Consecutive wins in play order, across all games
+func (r *PlayerRepository) longestWinStreak(ctx context.Context, id int64, householdID int64) (int, error) {
+	rows, err := r.db.Query(ctx, `SELECT psp.winner
+		FROM play_session_players psp
+		JOIN play_sessions s ON s.id = psp.play_session_id
+		WHERE psp.player_id = $1 AND s.household_id = $2
+		ORDER BY s.played_at, s.id`, id, householdID)
+	if err != nil {
+		return 0, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	longest, current := 0, 0
+	for rows.Next() {
+		var winner bool
+		if err := rows.Scan(&winner); err != nil {
+			return 0, ErrQueryFailed
+		}
+		if !winner {
+			current = 0
+			continue
+		}
+		current++
+		longest = max(longest, current)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, ErrQueryFailed
+	}
+
+	return longest, nil
+}
+
+func winRate(wins int, plays int) float64 {
+	if plays == 0 {
+		return 0
+	}
+	return float64(wins) / float64(plays)
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+)
+
+// conditions collects the WHERE clauses of a dynamic query and their positional arguments.
+// Values are always sent as arguments, never concatenated into the SQL.
+type conditions struct {
+	clauses []string
+	args    []any
+}
+
+// add appends a clause, each ? in it is replaced by the next $n placeholder
+func (c *conditions) add(clause string, args ...any) {
+	for _, arg := range args {
+		c.args = append(c.args, arg)
+		clause = strings.Replace(clause, "?", fmt.Sprintf("$%d", len(c.args)), 1)
+	}
+	c.clauses = append(c.clauses, clause)
+}
+
+// arg registers an extra argument (e.g. for LIMIT) and returns its placeholder
+func (c *conditions) arg(value any) string {
+	c.args = append(c.args, value)
+	return fmt.Sprintf("$%d", len(c.args))
+}
+
+func (c *conditions) where() string {
+	if len(c.clauses) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(c.clauses, " AND ")
+}
+
+func (c *conditions) clone() *conditions {
+	return &conditions{
+		clauses: append([]string(nil), c.clauses...),
+		args:    append([]any(nil), c.args...),
+	}
+}
+
+// escapeLike escapes the LIKE wildcards so user input is matched literally
+func escapeLike(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(value)
+}
@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"context"
+	"math"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/rating"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Ratings are derived from the play sessions, the play session repository
+// keeps them up to date in the same transaction as every change
+type RatingRepository struct {
+	db *pgxpool.Pool
+}
+
+type RatingRepo interface {
+	GetLeaderboard(ctx context.Context, userID int64, boardGameID *int64) ([]*models.LeaderboardEntry, error)
+	Recompute(ctx context.Context) error
+	RecomputeIfMissing(ctx context.Context) (bool, error)
+}
+
+func NewRatingRepository(db *pgxpool.Pool) *RatingRepository {
+	return &RatingRepository{db: db}
+}
+
+// Players of the households of the user, overall when boardGameID is nil
+func (r *RatingRepository) GetLeaderboard(ctx context.Context, userID int64, boardGameID *int64) ([]*models.LeaderboardEntry, error) {
+	if boardGameID != nil {
+		if _, err := boardGameRole(ctx, r.db, userID, *boardGameID); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT r.player_id, p.name, p.avatar_hash, r.rating, r.games_played, r.wins
+		FROM player_ratings r
+		JOIN players p ON p.id = r.player_id
+		WHERE r.board_game_id IS NOT DISTINCT FROM $1 AND `+memberOf("p.household_id", "$2")+`
+		ORDER BY r.rating DESC, r.games_played DESC, LOWER(p.name)`, boardGameID, userID)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	entries := []*models.LeaderboardEntry{}
+	for rows.Next() {
+		var entry models.LeaderboardEntry
+		var avatarHash *string
+		var value float64
+		if err := rows.Scan(&entry.PlayerID, &entry.Name, &avatarHash, &value, &entry.GamesPlayed, &entry.Wins); err != nil {
+			return nil, ErrQueryFailed
+		}
+
+		entry.Position = len(entries) + 1
+		entry.Rating = int(math.Round(value))
+		entry.WinRate = winRate(entry.Wins, entry.GamesPlayed)
+		if avatarHash != nil {
+			entry.AvatarURL = models.PlayerAvatarURL(entry.PlayerID, models.ImageVersion(*avatarHash))
+		}
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return entries, nil
+}
+
+// Replays the whole history, for the ratings that predate it or after a bulk change
+func (r *RatingRepository) Recompute(ctx context.Context) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return ErrQueryFailed
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	if err := recomputeRatings(ctx, tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ErrQueryFailed
+	}
+
+	return nil
+}
+
+// Replays the history when there are rated plays but no ratings, as after a
+// migration that throws them away. Reports whether it did.
+func (r *RatingRepository) RecomputeIfMissing(ctx context.Context) (bool, error) {
+	var missing bool
+	err := r.db.QueryRow(ctx, `SELECT NOT EXISTS (SELECT 1 FROM player_ratings)
+		AND EXISTS (SELECT 1 FROM play_session_players WHERE player_id IS NOT NULL)`).Scan(&missing)
+	if err != nil {
+		return false, ErrQueryFailed
+	}
+	if !missing {
+		return false, nil
+	}
+
+	return true, r.Recompute(ctx)
+}
+
+// A rated session, in the order it was played
+type ratedSession struct {
+	id           int64
+	boardGameID  *int64
+	participants []rating.Participant
+}
+
+// Only one transaction at a time writes ratings, a replay must not interleave with an update
+func lockRatings(ctx context.Context, tx pgx.Tx) error {
+	if _, err := tx.Exec(ctx, `LOCK TABLE player_ratings IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return ErrQueryFailed
+	}
+	return nil
+}
+
+// Participants of the sessions matching where, oldest session first.
+// Participants whose player was deleted are left out.
+func loadRatedSessions(ctx context.Context, tx pgx.Tx, where string, args ...any) ([]*ratedSession, error) {
+	rows, err := tx.Query(ctx, `SELECT s.id, s.board_game_id, psp.player_id,
+			COALESCE(psp.finish_rank, 0), COALESCE(psp.team, 0), psp.winner
+		FROM play_sessions s
+		JOIN play_session_players psp ON psp.play_session_id = s.id
+		WHERE psp.player_id IS NOT NULL AND `+where+`
+		ORDER BY s.played_at, s.id, psp.position`, args...)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	var sessions []*ratedSession
+	for rows.Next() {
+		var sessionID int64
+		var boardGameID *int64
+		var participant rating.Participant
+		err := rows.Scan(&sessionID, &boardGameID, &participant.PlayerID,
+			&participant.Rank, &participant.Team, &participant.Winner)
+		if err != nil {
+			return nil, ErrQueryFailed
+		}
+
+		if len(sessions) == 0 || sessions[len(sessions)-1].id != sessionID {
+			sessions = append(sessions, &ratedSession{id: sessionID, boardGameID: boardGameID})
+		}
+		current := sessions[len(sessions)-1]
+		current.participants = append(current.participants, participant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return sessions, nil
+}
+
+// Throws the ratings away and replays every session from the first one
+func recomputeRatings(ctx context.Context, tx pgx.Tx) error {
+	if err := lockRatings(ctx, tx); err != nil {
+		return err
+	}
+
+	sessions, err := loadRatedSessions(ctx, tx, `TRUE`)
+	if err != nil {
+		return err
+	}
+
+	overall := rating.Table{}
+	perGame := map[int64]rating.Table{}
+	for _, session := range sessions {
+		overall.Apply(session.participants)
+		if session.boardGameID != nil {
+			table, ok := perGame[*session.boardGameID]
+			if !ok {
+				table = rating.Table{}
+				perGame[*session.boardGameID] = table
+			}
+			table.Apply(session.participants)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM player_ratings`); err != nil {
+		return ErrQueryFailed
+	}
+
+	var rows [][]any
+	addRows := func(boardGameID *int64, table rating.Table) {
+		for playerID, r := range table {
+			rows = append(rows, []any{playerID, boardGameID, r.Value, r.Games, r.Wins})
+		}
+	}
+	addRows(nil, overall)
+	for boardGameID, table := range perGame {
+		addRows(&boardGameID, table)
+	}
+
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"player_ratings"},
+		[]string{"player_id", "board_game_id", "rating", "games_played", "wins"},
+		pgx.CopyFromRows(rows))
+	if err != nil {
+		return ErrQueryFailed
+	}
+
+	return nil
+}
+
+// Moves the ratings of the players of a session played after all the others
+func applySessionRatings(ctx context.Context, tx pgx.Tx, sessionID int64) error {
+	if err := lockRatings(ctx, tx); err != nil {
+		return err
+	}
+
+	sessions, err := loadRatedSessions(ctx, tx, `s.id = $1`, sessionID)
+	if err != nil || len(sessions) == 0 {
+		return err
+	}
+	session := sessions[0]
+
+	if err := applyToScope(ctx, tx, session.participants, nil); err != nil {
+		return err
+	}
+	if session.boardGameID != nil {
+		return applyToScope(ctx, tx, session.participants, session.boardGameID)
+	}
+	return nil
+}
+
+// Reads the current ratings of the participants in one scope, applies the game and writes them back
+func applyToScope(ctx context.Context, tx pgx.Tx, participants []rating.Participant, boardGameID *int64) error {
+	playerIDs := make([]int64, len(participants))
+	for i, participant := range participants {
+		playerIDs[i] = participant.PlayerID
+	}
+
+	rows, err := tx.Query(ctx, `SELECT player_id, rating, games_played, wins FROM player_ratings
+		WHERE player_id = ANY($1) AND board_game_id IS NOT DISTINCT FROM $2`, playerIDs, boardGameID)
+	if err != nil {
+		return ErrQueryFailed
+	}
+	defer rows.Close()
+
+	table := rating.Table{}
+	for rows.Next() {
+		var playerID int64
+		var r rating.Rating
+		if err := rows.Scan(&playerID, &r.Value, &r.Games, &r.Wins); err != nil {
+			return ErrQueryFailed
+		}
+		table[playerID] = &r
+	}
+	if err := rows.Err(); err != nil {
+		return ErrQueryFailed
+	}
+	rows.Close()
+
+	table.Apply(participants)
+
+	for playerID, r := range table {
+		_, err := tx.Exec(ctx, `INSERT INTO player_ratings (player_id, board_game_id, rating, games_played, wins)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (player_id, COALESCE(board_game_id, 0))
+			DO UPDATE SET rating = EXCLUDED.rating, games_played = EXCLUDED.games_played,
+				wins = EXCLUDED.wins, updated_at = NOW()`,
+			playerID, boardGameID, r.Value, r.Games, r.Wins)
+		if err != nil {
+			return ErrQueryFailed
+		}
+	}
+
+	return nil
+}
+
+// Keeps the ratings right after a session is added: a session played after
+// every other one only moves its players, an older one changes what came next.
+// The lock comes first so the check sees every session added before this one,
+// two creates can't both take the incremental path out of order.
+func updateRatingsAfterCreate(ctx context.Context, tx pgx.Tx, session *models.PlaySession) error {
+	if err := lockRatings(ctx, tx); err != nil {
+		return err
+	}
+
+	var replay bool
+	err := tx.QueryRow(ctx, `SELECT EXISTS (
+		SELECT 1 FROM play_sessions WHERE (played_at, id) > ($1, $2))`, session.PlayedAt, session.ID).Scan(&replay)
+	if err != nil {
+		return ErrQueryFailed
+	}
+
+	if replay {
+		return recomputeRatings(ctx, tx)
+	}
+	return applySessionRatings(ctx, tx, session.ID)
+}
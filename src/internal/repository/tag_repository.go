@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TagRepository struct {
+	db *pgxpool.Pool
+}
+
+type TagRepo interface {
+	Create(ctx context.Context, userID int64, tag *models.Tag) error
+	GetAll(ctx context.Context, userID int64, kind string) ([]*models.Tag, error)
+	GetByID(ctx context.Context, userID int64, id int64) (*models.Tag, error)
+	Update(ctx context.Context, userID int64, tag *models.Tag) error
+	Delete(ctx context.Context, id int64) error
+	Merge(ctx context.Context, userID int64, sourceID int64, targetID int64) (*models.Tag, error)
+	GetForBoardGame(ctx context.Context, userID int64, boardGameID int64) ([]*models.Tag, error)
+	Assign(ctx context.Context, boardGameID int64, tagIDs []int64) error
+	Unassign(ctx context.Context, boardGameID int64, tagID int64) error
+}
+
+func NewTagRepository(db *pgxpool.Pool) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+// Columns read for every tag, in the order scanTag expects. Tags are shared,
+// the game count only covers the households of the user in placeholder user.
+func tagColumns(user string) string {
+	return `id, name, kind,
+	(SELECT COUNT(*) FROM board_game_tags bgt JOIN board_games g ON g.id = bgt.board_game_id
+		WHERE bgt.tag_id = tags.id AND ` + memberOf("g.household_id", user) + `),
+	created_at, updated_at`
+}
+
+func scanTag(row pgx.Row) (*models.Tag, error) {
+	var tag models.Tag
+	err := row.Scan(&tag.ID, &tag.Name, &tag.Kind, &tag.GameCount, &tag.CreatedAt, &tag.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// Names are unique whatever their case and kind
+func isDuplicateTagName(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_tags_name"
+}
+
+func (r *TagRepository) Create(ctx context.Context, userID int64, tag *models.Tag) error {
+	query := `INSERT INTO tags (name, kind) VALUES ($1, $2) RETURNING ` + tagColumns("$3")
+
+	created, err := scanTag(r.db.QueryRow(ctx, query, tag.Name, tag.Kind, userID))
+	if err != nil {
+		if isDuplicateTagName(err) {
+			return ErrDuplicateTagName
+		}
+		return ErrQueryFailed
+	}
+
+	*tag = *created
+	return nil
+}
+
+// Every tag with its game count, only the ones of a kind when kind is set
+func (r *TagRepository) GetAll(ctx context.Context, userID int64, kind string) ([]*models.Tag, error) {
+	filters := &conditions{}
+	user := filters.arg(userID)
+	if kind != "" {
+		filters.add("kind = ?", kind)
+	}
+
+	query := `SELECT ` + tagColumns(user) + ` FROM tags` + filters.where() + ` ORDER BY kind, LOWER(name)`
+	return r.getTags(ctx, query, filters.args...)
+}
+
+func (r *TagRepository) getTags(ctx context.Context, query string, args ...any) ([]*models.Tag, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer rows.Close()
+
+	tags := []*models.Tag{}
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			return nil, ErrQueryFailed
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return tags, nil
+}
+
+func (r *TagRepository) GetByID(ctx context.Context, userID int64, id int64) (*models.Tag, error) {
+	tag, err := scanTag(r.db.QueryRow(ctx, `SELECT `+tagColumns("$2")+` FROM tags WHERE id = $1`, id, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTagNotFound
+		}
+		return nil, ErrQueryFailed
+	}
+
+	return tag, nil
+}
+
+// Renames the tag or changes its kind, the games keep it
+func (r *TagRepository) Update(ctx context.Context, userID int64, tag *models.Tag) error {
+	query := `UPDATE tags SET name = $1, kind = $2, updated_at = NOW() WHERE id = $3 RETURNING ` + tagColumns("$4")
+
+	updated, err := scanTag(r.db.QueryRow(ctx, query, tag.Name, tag.Kind, tag.ID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrTagNotFound
+		}
+		if isDuplicateTagName(err) {
+			return ErrDuplicateTagName
+		}
+		return ErrQueryFailed
+	}
+
+	*tag = *updated
+	return nil
+}
+
+// Removes the tag from every game
+func (r *TagRepository) Delete(ctx context.Context, id int64) error {
+	commandTag, err := r.db.Exec(ctx, `DELETE FROM tags WHERE id = $1`, id)
+	if err != nil {
+		return ErrQueryFailed
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrTagNotFound
+	}
+
+	return nil
+}
+
+// Moves every game of the source tag to the target and deletes the source,
+// games that had both keep the target once
+func (r *TagRepository) Merge(ctx context.Context, userID int64, sourceID int64, targetID int64) (*models.Tag, error) {
+	if sourceID == targetID {
+		return nil, ErrInvalidTagMerge
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	// Lock both so a concurrent assign cannot land on the source halfway through
+	var found int
+	err = tx.QueryRow(ctx, `SELECT COUNT(*) FROM (SELECT id FROM tags WHERE id IN ($1, $2) FOR UPDATE) locked`,
+		sourceID, targetID).Scan(&found)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	if found != 2 {
+		return nil, ErrTagNotFound
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO board_game_tags (board_game_id, tag_id)
+		SELECT board_game_id, $2 FROM board_game_tags WHERE tag_id = $1
+		ON CONFLICT DO NOTHING`, sourceID, targetID)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM tags WHERE id = $1`, sourceID); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	target, err := scanTag(tx.QueryRow(ctx, `UPDATE tags SET updated_at = NOW() WHERE id = $1 RETURNING `+tagColumns("$2"),
+		targetID, userID))
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return target, nil
+}
+
+func (r *TagRepository) GetForBoardGame(ctx context.Context, userID int64, boardGameID int64) ([]*models.Tag, error) {
+	if err := r.ensureBoardGame(ctx, boardGameID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + tagColumns("$2") + ` FROM tags
+		WHERE id IN (SELECT tag_id FROM board_game_tags WHERE board_game_id = $1)
+		ORDER BY kind, LOWER(name)`
+	return r.getTags(ctx, query, boardGameID, userID)
+}
+
+func (r *TagRepository) ensureBoardGame(ctx context.Context, boardGameID int64) error {
+	var exists bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM board_games WHERE id = $1)`, boardGameID).Scan(&exists)
+	if err != nil {
+		return ErrQueryFailed
+	}
+	if !exists {
+		return ErrBoardGameNotFound
+	}
+	return nil
+}
+
+// Adds the tags to the game, the ones it already has are left as they are.
+// Nothing is added when one of the tags does not exist.
+func (r *TagRepository) Assign(ctx context.Context, boardGameID int64, tagIDs []int64) error {
+	if err := r.ensureBoardGame(ctx, boardGameID); err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return ErrQueryFailed
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	// FOR SHARE keeps the tags from being deleted or merged until the commit
+	var found int
+	err = tx.QueryRow(ctx, `SELECT COUNT(*) FROM (SELECT id FROM tags WHERE id = ANY($1) FOR SHARE) locked`,
+		tagIDs).Scan(&found)
+	if err != nil {
+		return ErrQueryFailed
+	}
+	if found != len(uniqueIDs(tagIDs)) {
+		return ErrTagNotFound
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO board_game_tags (board_game_id, tag_id)
+		SELECT $1, UNNEST($2::integer[])
+		ON CONFLICT DO NOTHING`, boardGameID, tagIDs)
+	if err != nil {
+		return ErrQueryFailed
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ErrQueryFailed
+	}
+
+	return nil
+}
+
+func uniqueIDs(ids []int64) map[int64]bool {
+	unique := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		unique[id] = true
+	}
+	return unique
+}
+
+func (r *TagRepository) Unassign(ctx context.Context, boardGameID int64, tagID int64) error {
+	commandTag, err := r.db.Exec(ctx, `DELETE FROM board_game_tags WHERE board_game_id = $1 AND tag_id = $2`,
+		boardGameID, tagID)
+	if err != nil {
+		return ErrQueryFailed
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrTagNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/eddiarnoldo/my-game-shelf/src/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Accounts, their sessions and the identities they sign in with. Passwords
+// and tokens arrive already hashed.
+type UserRepository struct {
+	db *pgxpool.Pool
+}
+
+type UserRepo interface {
+	Create(ctx context.Context, user *models.User) error
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	GetByID(ctx context.Context, id int64) (*models.User, error)
+	CreateSession(ctx context.Context, userID int64, tokenHash string, ttl time.Duration) (*models.Session, error)
+	GetSessionUser(ctx context.Context, tokenHash string) (*models.User, error)
+	DeleteSession(ctx context.Context, tokenHash string) error
+	SignInIdentity(ctx context.Context, identity *models.Identity) (*models.User, error)
+	CreateOIDCLogin(ctx context.Context, login *models.OIDCLogin, ttl time.Duration) error
+	TakeOIDCLogin(ctx context.Context, stateHash string) (*models.OIDCLogin, error)
+}
+
+func NewUserRepository(db *pgxpool.Pool) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+const userColumns = `users.id, users.email, users.display_name, users.password_hash, users.is_admin,
+	users.created_at, users.updated_at`
+
+func scanUser(row pgx.Row) (*models.User, error) {
+	var user models.User
+	err := row.Scan(&user.ID, &user.Email, &user.DisplayName, &user.PasswordHash, &user.IsAdmin,
+		&user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Emails are unique whatever their case
+func isDuplicateEmail(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_users_email"
+}
+
+// Every user starts as the owner of a household of their own. The first one
+// becomes the admin and gets every game added before accounts existed.
+func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return ErrQueryFailed
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	created, err := insertUser(ctx, tx, user)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ErrQueryFailed
+	}
+
+	*user = *created
+	return nil
+}
+
+// Creates the user with their household, see Create
+func insertUser(ctx context.Context, tx pgx.Tx, user *models.User) (*models.User, error) {
+	// Two first registrations at once would both become admin
+	if _, err := tx.Exec(ctx, `LOCK TABLE users IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	var first bool
+	if err := tx.QueryRow(ctx, `SELECT NOT EXISTS (SELECT 1 FROM users)`).Scan(&first); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	query := `INSERT INTO users (email, display_name, password_hash, is_admin)
+		VALUES ($1, $2, $3, $4) RETURNING ` + userColumns
+
+	created, err := scanUser(tx.QueryRow(ctx, query, user.Email, user.DisplayName, user.PasswordHash, first))
+	if err != nil {
+		if isDuplicateEmail(err) {
+			return nil, ErrDuplicateEmail
+		}
+		return nil, ErrQueryFailed
+	}
+
+	household, err := insertHousehold(ctx, tx, created.ID, shelfName(created.DisplayName))
+	if err != nil {
+		return nil, err
+	}
+
+	if first {
+		if _, err := tx.Exec(ctx, `UPDATE board_games SET household_id = $1 WHERE household_id IS NULL`, household.ID); err != nil {
+			return nil, ErrQueryFailed
+		}
+	}
+
+	return created, nil
+}
+
+// Name of the household a new user starts with, as long as households allow
+func shelfName(displayName string) string {
+	name := []rune(displayName + "'s shelf")
+	if len(name) > 100 {
+		name = name[:100]
+	}
+	return string(name)
+}
+
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE LOWER(email) = LOWER($1)`
+	return r.getUser(ctx, query, email)
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id int64) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	return r.getUser(ctx, query, id)
+}
+
+func (r *UserRepository) getUser(ctx context.Context, query string, arg any) (*models.User, error) {
+	user, err := scanUser(r.db.QueryRow(ctx, query, arg))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrQueryFailed
+	}
+
+	return user, nil
+}
+
+// Starts a session lasting ttl. The user's expired sessions are cleaned up
+// on the way.
+func (r *UserRepository) CreateSession(ctx context.Context, userID int64, tokenHash string, ttl time.Duration) (*models.Session, error) {
+	if _, err := r.db.Exec(ctx, `DELETE FROM sessions WHERE user_id = $1 AND expires_at <= NOW()`, userID); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	query := `INSERT INTO sessions (user_id, token_hash, expires_at)
+		VALUES ($1, $2, NOW() + $3 * INTERVAL '1 second')
+		RETURNING id, user_id, token_hash, created_at, expires_at`
+
+	var session models.Session
+	err := r.db.QueryRow(ctx, query, userID, tokenHash, int64(ttl.Seconds())).Scan(
+		&session.ID, &session.UserID, &session.TokenHash, &session.CreatedAt, &session.ExpiresAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrQueryFailed
+	}
+
+	return &session, nil
+}
+
+// The user signed in with the token, ErrSessionNotFound once it expired
+func (r *UserRepository) GetSessionUser(ctx context.Context, tokenHash string) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM sessions JOIN users ON users.id = sessions.user_id
+		WHERE sessions.token_hash = $1 AND sessions.expires_at > NOW()`
+
+	user, err := scanUser(r.db.QueryRow(ctx, query, tokenHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, ErrQueryFailed
+	}
+
+	return user, nil
+}
+
+func (r *UserRepository) DeleteSession(ctx context.Context, tokenHash string) error {
+	commandTag, err := r.db.Exec(ctx, `DELETE FROM sessions WHERE token_hash = $1`, tokenHash)
+	if err != nil {
+		return ErrQueryFailed
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// The user an OpenID Connect identity signs in as. An unknown identity is
+// linked to the account with its email when the provider verified it and the
+// account was itself created through a provider, or gets a new account
+// without a password. Anyone could have registered a password account with
+// that email first, so linking to one fails with ErrIdentityNotLinked.
+func (r *UserRepository) SignInIdentity(ctx context.Context, identity *models.Identity) (*models.User, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	query := `UPDATE user_identities SET email = $3, last_login_at = NOW()
+		FROM users
+		WHERE user_identities.issuer = $1 AND user_identities.subject = $2 AND users.id = user_identities.user_id
+		RETURNING ` + userColumns
+
+	user, err := scanUser(tx.QueryRow(ctx, query, identity.Issuer, identity.Subject, identity.Email))
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrQueryFailed
+	}
+
+	if user == nil && identity.EmailVerified && identity.Email != "" {
+		var linkable bool
+		err := tx.QueryRow(ctx, `SELECT users.password_hash = ''
+				AND EXISTS (SELECT 1 FROM user_identities WHERE user_identities.user_id = users.id)
+			FROM users WHERE LOWER(email) = LOWER($1)`, identity.Email).Scan(&linkable)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrQueryFailed
+		}
+		if err == nil && !linkable {
+			return nil, ErrIdentityNotLinked
+		}
+		if linkable {
+			user, err = scanUser(tx.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE LOWER(email) = LOWER($1)`, identity.Email))
+			if err != nil {
+				return nil, ErrQueryFailed
+			}
+		}
+	}
+
+	if user == nil {
+		if identity.Email == "" {
+			return nil, ErrIdentityEmailRequired
+		}
+		// An unverified email of an existing account stays a duplicate
+		user, err = insertUser(ctx, tx, &models.User{
+			Email:       identity.Email,
+			DisplayName: identityName(identity),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO user_identities (issuer, subject, user_id, email)
+		VALUES ($1, $2, $3, $4) ON CONFLICT (issuer, subject) DO NOTHING`,
+		identity.Issuer, identity.Subject, user.ID, identity.Email)
+	if err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, ErrQueryFailed
+	}
+
+	return user, nil
+}
+
+// The name the provider knows, else the start of the email
+func identityName(identity *models.Identity) string {
+	name := strings.TrimSpace(identity.Name)
+	if name == "" {
+		name, _, _ = strings.Cut(identity.Email, "@")
+	}
+	runes := []rune(name)
+	if len(runes) > 100 {
+		runes = runes[:100]
+	}
+	return string(runes)
+}
+
+// Remembers a sign in sent to the provider for ttl. Expired ones are
+// cleaned up on the way.
+func (r *UserRepository) CreateOIDCLogin(ctx context.Context, login *models.OIDCLogin, ttl time.Duration) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM oidc_logins WHERE expires_at <= NOW()`); err != nil {
+		return ErrQueryFailed
+	}
+
+	query := `INSERT INTO oidc_logins (state_hash, code_verifier, nonce, expires_at)
+		VALUES ($1, $2, $3, NOW() + $4 * INTERVAL '1 second')
+		RETURNING expires_at`
+
+	err := r.db.QueryRow(ctx, query, login.StateHash, login.CodeVerifier, login.Nonce, int64(ttl.Seconds())).Scan(&login.ExpiresAt)
+	if err != nil {
+		return ErrQueryFailed
+	}
+
+	return nil
+}
+
+// The sign in the provider sent back, it cannot be used twice.
+// ErrOIDCLoginNotFound once it expired.
+func (r *UserRepository) TakeOIDCLogin(ctx context.Context, stateHash string) (*models.OIDCLogin, error) {
+	query := `DELETE FROM oidc_logins WHERE state_hash = $1 AND expires_at > NOW()
+		RETURNING state_hash, code_verifier, nonce, expires_at`
+
+	var login models.OIDCLogin
+	err := r.db.QueryRow(ctx, query, stateHash).Scan(&login.StateHash, &login.CodeVerifier, &login.Nonce, &login.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrOIDCLoginNotFound
+		}
+		return nil, ErrQueryFailed
+	}
+
+	return &login, nil
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLocalStore_PutGetDelete(t *testing.T) {
+	// Arrange
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	ctx := context.Background()
+
+	// Act
+	if err := store.Put(ctx, "boardgames/1/abc/original", []byte("image bytes"), "image/png"); err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+	data, err := store.Get(ctx, "boardgames/1/abc/original")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if string(data) != "image bytes" {
+		t.Errorf("expected 'image bytes', got '%s'", data)
+	}
+
+	if err := store.Delete(ctx, "boardgames/1/abc/original"); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	if _, err := store.Get(ctx, "boardgames/1/abc/original"); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("expected ErrBlobNotFound after delete, got %v", err)
+	}
+
+	// Deleting twice is fine
+	if err := store.Delete(ctx, "boardgames/1/abc/original"); err != nil {
+		t.Errorf("expected deleting a missing key to succeed, got %v", err)
+	}
+}
+
+func TestLocalStore_RejectsKeysOutsideRoot(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	for _, key := range []string{"", "../secret", "/etc/passwd", "a/../../b", "a//b", `a\b`} {
+		if err := store.Put(context.Background(), key, []byte("x"), ""); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("expected ErrInvalidKey for %q, got %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"path"
+	"strings"
+)
+
+var (
+	ErrBlobNotFound = errors.New("Blob not found")
+	ErrInvalidKey   = errors.New("Invalid blob key")
+)
+
+// BlobStore keeps the image bytes out of Postgres, the database only stores the keys.
+// Keys are slash separated paths like "boardgames/12/4f3a.../original".
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error // Deleting a missing key is not an error
+}
+
+// NewKey builds a unique key under prefix
+func NewKey(prefix string) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return path.Join(prefix, hex.EncodeToString(random)), nil
+}
+
+// validateKey rejects keys that could escape the store root
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
@@ -4,10 +4,12 @@ import HomePage from './pages/HomePage';
 import BoardGameDetailPage from './pages/BoardGameDetailPage';
 import AddGamePage from './pages/AddGamePage'; 
 import NotFoundPage from './pages/NotFoundPage';
+import LoginPage from './pages/LoginPage';
 
 function App() {
   return (
     <Routes>
+      <Route path="/login" element={<LoginPage />} />
       <Route path="/" element={<Layout />}>
         <Route index element={<HomePage />} />
         <Route path="boardgame/:id" element={<BoardGameDetailPage />} />
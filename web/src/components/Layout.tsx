@@ -1,6 +1,34 @@
-import { Outlet, NavLink } from 'react-router-dom';
+import { useState, useEffect } from 'react';
+import { Outlet, NavLink, useNavigate } from 'react-router-dom';
+import { apiFetch } from '../lib/api';
+
+interface User {
+  id: number;
+  email: string;
+  display_name: string;
+}
 
 export default function Layout() {
+  const navigate = useNavigate();
+  const [user, setUser] = useState<User | null>(null);
+
+  // Also sends the browser to the sign in page when there is no session
+  useEffect(() => {
+    apiFetch('/api/auth/me')
+      .then(res => (res.ok ? res.json() : null))
+      .then(setUser)
+      .catch(err => console.error(err));
+  }, []);
+
+  const handleSignOut = async () => {
+    try {
+      await apiFetch('/api/auth/logout', { method: 'POST' });
+    } catch (err) {
+      console.error(err);
+    }
+    navigate('/login');
+  };
+
   return (
     <div style={{ display: 'flex', height: '100vh', width: '100vw' }}>
       {/* Sidebar */}
@@ -51,6 +79,25 @@ export default function Layout() {
              </li>
           </ul>
         </nav>
+
+        {user && (
+          <div style={{ marginTop: '30px', borderTop: '1px solid #444', paddingTop: '20px' }}>
+            <div style={{ color: '#ccc', marginBottom: '8px' }}>{user.display_name}</div>
+            <button
+              onClick={handleSignOut}
+              style={{
+                background: 'none',
+                border: 'none',
+                color: '#4a9eff',
+                cursor: 'pointer',
+                fontSize: '14px',
+                padding: 0
+              }}
+            >
+              Sign out
+            </button>
+          </div>
+        )}
       </aside>
 
       {/* Main content area */}
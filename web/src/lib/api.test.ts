@@ -0,0 +1,50 @@
+import { afterEach, describe, expect, it, vi } from 'vitest';
+import { apiFetch, safeNext, SignInRequiredError } from './api';
+
+function stubBrowser(status: number, body: unknown) {
+  const assign = vi.fn();
+  vi.stubGlobal('window', { location: { pathname: '/boardgame/3', search: '?tab=plays', assign } });
+  vi.stubGlobal('fetch', vi.fn(async () => new Response(JSON.stringify(body), { status })));
+  return assign;
+}
+
+afterEach(() => {
+  vi.unstubAllGlobals();
+});
+
+describe('apiFetch', () => {
+  it('returns the response when signed in', async () => {
+    const assign = stubBrowser(200, [{ id: 1, name: 'Catan' }]);
+
+    const res = await apiFetch('/api/boardgames');
+
+    expect(await res.json()).toEqual([{ id: 1, name: 'Catan' }]);
+    expect(assign).not.toHaveBeenCalled();
+  });
+
+  it('sends the browser to sign in on 401 and comes back after', async () => {
+    const assign = stubBrowser(401, { error: 'Sign in required' });
+
+    await expect(apiFetch('/api/boardgames')).rejects.toBeInstanceOf(SignInRequiredError);
+    expect(assign).toHaveBeenCalledWith('/login?next=%2Fboardgame%2F3%3Ftab%3Dplays');
+  });
+
+  it('leaves other errors to the caller', async () => {
+    const assign = stubBrowser(404, { error: 'Board game not found' });
+
+    const res = await apiFetch('/api/boardgames/9');
+
+    expect(res.status).toBe(404);
+    expect(assign).not.toHaveBeenCalled();
+  });
+});
+
+describe('safeNext', () => {
+  it('only keeps paths of the app', () => {
+    expect(safeNext('/boardgame/3')).toBe('/boardgame/3');
+    expect(safeNext(null)).toBe('/');
+    expect(safeNext('https://example.com')).toBe('/');
+    expect(safeNext('//example.com')).toBe('/');
+    expect(safeNext('/\\example.com')).toBe('/');
+  });
+});
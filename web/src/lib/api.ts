@@ -0,0 +1,32 @@
+// Thrown once the browser is on its way to the sign in page
+export class SignInRequiredError extends Error {
+  constructor() {
+    super('Sign in required');
+    this.name = 'SignInRequiredError';
+  }
+}
+
+export function signInPath(next: string): string {
+  return next === '/' ? '/login' : `/login?next=${encodeURIComponent(next)}`;
+}
+
+// Where to go once signed in, only paths of this app so the link can't send
+// the browser to another site
+export function safeNext(next: string | null): string {
+  if (!next || !next.startsWith('/') || next.startsWith('//') || next.startsWith('/\\')) {
+    return '/';
+  }
+  return next;
+}
+
+// fetch for the API routes: the session cookie goes along, and without a
+// session the browser is sent to the sign in page, coming back here after
+export async function apiFetch(input: string, init?: RequestInit): Promise<Response> {
+  const res = await fetch(input, { credentials: 'same-origin', ...init });
+  if (res.status === 401) {
+    const { pathname, search } = window.location;
+    window.location.assign(signInPath(pathname + search));
+    throw new SignInRequiredError();
+  }
+  return res;
+}
@@ -0,0 +1,51 @@
+import { describe, expect, it } from 'vitest';
+import { findCover, type BoardGameImage } from './images';
+
+// Copied from GET /api/boardgame/1/images
+const response = `[
+  {
+    "id": 7,
+    "board_game_id": 1,
+    "mime_type": "image/jpeg",
+    "type": "gameplay",
+    "display_order": 0,
+    "url": "/api/boardgame/images/7?v=9b74c9897bac770f",
+    "thumbnail_url": "/api/boardgame/images/7/thumbnail?v=9b74c9897bac770f",
+    "etag": "9b74c9897bac770ffc029102a200c5de",
+    "uploaded_at": "2024-05-01T12:00:00Z",
+    "variants": [{ "width": 150, "height": 100, "mime_type": "image/jpeg" }],
+    "srcset": "/api/boardgame/images/7?w=150&v=9b74c9897bac770f 150w"
+  },
+  {
+    "id": 5,
+    "board_game_id": 1,
+    "mime_type": "image/jpeg",
+    "type": "cover",
+    "display_order": 1,
+    "url": "/api/boardgame/images/5?v=f00dcafef00dcafe",
+    "thumbnail_url": "/api/boardgame/images/5/thumbnail?v=f00dcafef00dcafe",
+    "etag": "f00dcafef00dcafef00dcafef00dcafe",
+    "uploaded_at": "2024-05-01T12:00:00Z",
+    "variants": [
+      { "width": 150, "height": 150, "mime_type": "image/jpeg" },
+      { "width": 150, "height": 150, "mime_type": "image/webp" },
+      { "width": 300, "height": 300, "mime_type": "image/jpeg" }
+    ],
+    "srcset": "/api/boardgame/images/5?w=150&v=f00dcafef00dcafe 150w, /api/boardgame/images/5?w=300&v=f00dcafef00dcafe 300w"
+  }
+]`;
+
+describe('findCover', () => {
+  it('finds the cover in the images the API returns', () => {
+    const cover = findCover(JSON.parse(response) as BoardGameImage[]);
+
+    expect(cover?.id).toBe(5);
+    expect(cover?.srcset).toContain('300w');
+  });
+
+  it('returns null without a cover', () => {
+    const images = (JSON.parse(response) as BoardGameImage[]).filter(image => image.type !== 'cover');
+
+    expect(findCover(images)).toBeNull();
+  });
+});
@@ -0,0 +1,17 @@
+// An image as GET /api/boardgame/:id/images returns it
+export interface BoardGameImage {
+  id: number;
+  board_game_id: number;
+  mime_type: string;
+  type: 'cover' | 'gameplay' | string;
+  display_order: number;
+  url: string;
+  thumbnail_url?: string;
+  etag?: string;
+  uploaded_at: string;
+  srcset?: string;
+}
+
+export function findCover(images: BoardGameImage[]): BoardGameImage | null {
+  return images.find(image => image.type === 'cover') ?? null;
+}
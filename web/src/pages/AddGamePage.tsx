@@ -1,5 +1,6 @@
 import { useState } from 'react';
 import { Link, useNavigate } from 'react-router-dom';
+import { apiFetch } from '../lib/api';
 
 export default function AddGamePage() {
   const navigate = useNavigate();
@@ -49,7 +50,7 @@ export default function AddGamePage() {
 
     try {
       // Step 1: Create the board game
-      const gameResponse = await fetch('/api/boardgame', {
+      const gameResponse = await apiFetch('/api/boardgame', {
         method: 'POST',
         headers: {
           'Content-Type': 'application/json',
@@ -70,7 +71,7 @@ export default function AddGamePage() {
         formData.append('image', coverImage);
         formData.append('imageType', 'cover');
 
-        const imageResponse = await fetch(`/api/boardgame/${gameId}/images`, {
+        const imageResponse = await apiFetch(`/api/boardgame/${gameId}/images`, {
           method: 'POST',
           body: formData // Don't set Content-Type - browser handles it
         });
@@ -129,7 +130,7 @@ export default function AddGamePage() {
           </label>
           <input
             type="file"
-            accept="image/*"
+            accept="image/jpeg,image/png,image/gif,image/webp"
             onChange={handleImageChange}
             style={{
               width: '100%',
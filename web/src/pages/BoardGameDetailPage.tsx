@@ -1,5 +1,7 @@
 import { useState, useEffect } from 'react';
 import { useParams, Link, useNavigate } from 'react-router-dom';
+import { apiFetch } from '../lib/api';
+import { findCover, type BoardGameImage } from '../lib/images';
 
 interface BoardGame {
   id: number;
@@ -9,10 +11,43 @@ interface BoardGame {
   play_time: number;
   min_age: number;
   description: string;
+  tags?: Tag[];
+  designers?: Credit[];
+  artists?: Credit[];
+  publishers?: Publisher[];
+  base_game?: Expansion;
+  expansions?: Expansion[];
+  combined?: {
+    min_players: number;
+    max_players?: number;
+    min_play_time: number;
+    max_play_time: number;
+  };
   created_at: string;
   updated_at: string;
 }
 
+interface Tag {
+  id: number;
+  name: string;
+  kind: 'category' | 'mechanic' | 'theme';
+}
+
+interface Credit {
+  id: number;
+  name: string;
+}
+
+interface Publisher extends Credit {
+  year_published?: number;
+}
+
+interface Expansion {
+  id: number;
+  name: string;
+  relation: 'expansion' | 'standalone_expansion' | 'promo';
+}
+
 export default function GameDetailPage() {
   const { id } = useParams<{ id: string }>();
   const navigate = useNavigate();
@@ -20,9 +55,10 @@ export default function GameDetailPage() {
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState(false);
   const [deleting, setDeleting] = useState(false);
+  const [cover, setCover] = useState<BoardGameImage | null>(null);
 
   useEffect(() => {
-    fetch(`/api/boardgames/${id}`)
+    apiFetch(`/api/boardgames/${id}`)
       .then(res => {
         if (!res.ok) {
           throw new Error('Game not found');
@@ -40,6 +76,16 @@ export default function GameDetailPage() {
       });
   }, [id]);
 
+  useEffect(() => {
+    // The srcset lets the browser pick a resized copy instead of the original
+    apiFetch(`/api/boardgame/${id}/images`)
+      .then(res => (res.ok ? res.json() : []))
+      .then((images: BoardGameImage[]) => {
+        setCover(findCover(images));
+      })
+      .catch(err => console.error(err));
+  }, [id]);
+
   const handleDelete = async () => {
     if (!window.confirm(`Are you sure you want to delete "${game?.name}"?`)) {
       return;
@@ -48,7 +94,7 @@ export default function GameDetailPage() {
     setDeleting(true);
 
     try {
-      const response = await fetch(`/api/boardgames/${id}`, {
+      const response = await apiFetch(`/api/boardgames/${id}`, {
         method: 'DELETE'
       });
 
@@ -124,9 +170,20 @@ export default function GameDetailPage() {
           alignItems: 'center',
           justifyContent: 'center',
           fontSize: '120px',
-          flexShrink: 0
+          flexShrink: 0,
+          overflow: 'hidden'
         }}>
-          🎲
+          {cover ? (
+            <img
+              src={cover.url}
+              srcSet={cover.srcset || undefined}
+              sizes="400px"
+              alt={`${game.name} cover`}
+              style={{ width: '100%', height: '100%', objectFit: 'cover' }}
+            />
+          ) : (
+            '🎲'
+          )}
         </div>
 
         {/* Game details */}
@@ -147,6 +204,70 @@ export default function GameDetailPage() {
             <p style={{ marginBottom: '12px' }}>
               <strong style={{ color: 'white' }}>Minimum Age:</strong> {game.min_age}+
             </p>
+
+            {game.base_game && (
+              <p style={{ marginBottom: '12px' }}>
+                <strong style={{ color: 'white' }}>Expands:</strong>{' '}
+                <Link to={`/boardgame/${game.base_game.id}`} style={{ color: '#ddd' }}>{game.base_game.name}</Link>
+              </p>
+            )}
+
+            {game.expansions && game.expansions.length > 0 && (
+              <p style={{ marginBottom: '12px' }}>
+                <strong style={{ color: 'white' }}>Expansions:</strong>{' '}
+                {game.expansions.map((expansion, i) => (
+                  <span key={expansion.id}>
+                    {i > 0 && ', '}
+                    <Link to={`/boardgame/${expansion.id}`} style={{ color: '#ddd' }}>{expansion.name}</Link>
+                  </span>
+                ))}
+              </p>
+            )}
+
+            {game.combined && (
+              <p style={{ marginBottom: '12px' }}>
+                <strong style={{ color: 'white' }}>With expansions:</strong>{' '}
+                {game.combined.min_players}
+                {game.combined.max_players ? `-${game.combined.max_players}` : '+'} players,{' '}
+                {game.combined.min_play_time === game.combined.max_play_time
+                  ? game.combined.min_play_time
+                  : `${game.combined.min_play_time}-${game.combined.max_play_time}`}{' '}
+                min
+              </p>
+            )}
+
+            {game.designers && game.designers.length > 0 && (
+              <p style={{ marginBottom: '12px' }}>
+                <strong style={{ color: 'white' }}>Designers:</strong> {game.designers.map((d) => d.name).join(', ')}
+              </p>
+            )}
+
+            {game.artists && game.artists.length > 0 && (
+              <p style={{ marginBottom: '12px' }}>
+                <strong style={{ color: 'white' }}>Artists:</strong> {game.artists.map((a) => a.name).join(', ')}
+              </p>
+            )}
+
+            {game.publishers && game.publishers.length > 0 && (
+              <p style={{ marginBottom: '12px' }}>
+                <strong style={{ color: 'white' }}>Publishers:</strong>{' '}
+                {game.publishers.map((p) => (p.year_published ? `${p.name} (${p.year_published})` : p.name)).join(', ')}
+              </p>
+            )}
+
+            {game.tags && game.tags.length > 0 && (
+              <p style={{ marginBottom: '12px', display: 'flex', flexWrap: 'wrap', gap: '8px' }}>
+                {game.tags.map((tag) => (
+                  <span
+                    key={tag.id}
+                    title={tag.kind}
+                    style={{ background: '#333', color: '#ddd', borderRadius: '12px', padding: '2px 10px', fontSize: '14px' }}
+                  >
+                    {tag.name}
+                  </span>
+                ))}
+              </p>
+            )}
             
             <p style={{ marginBottom: '20px' }}>
               <strong style={{ color: 'white' }}>Description:</strong>
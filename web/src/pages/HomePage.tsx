@@ -1,5 +1,6 @@
 import { useState, useEffect } from 'react';
 import BoardGameCard from '../components/BoardGameCard';
+import { apiFetch } from '../lib/api';
 
 interface BoardGame {
   id: number;
@@ -12,18 +13,34 @@ interface BoardGame {
 export default function HomePage() {
   const [games, setGames] = useState<BoardGame[]>([]);
   const [loading, setLoading] = useState(true);
+  const [nextCursor, setNextCursor] = useState<string | null>(null);
+  const [total, setTotal] = useState(0);
 
-  useEffect(() => {
-    fetch('/api/boardgames')
-      .then(res => res.json())
-      .then(data => {
-        setGames(data || []);
-        setLoading(false);
+  // The API returns one page at a time, the next cursor comes in a header
+  const loadGames = (cursor?: string) => {
+    const params = new URLSearchParams({ sort: 'name' });
+    if (cursor) {
+      params.set('cursor', cursor);
+    }
+
+    return apiFetch(`/api/boardgames?${params}`)
+      .then(res => {
+        if (!res.ok) {
+          throw new Error('Failed to load games');
+        }
+        setNextCursor(res.headers.get('X-Next-Cursor'));
+        setTotal(Number(res.headers.get('X-Total-Count') || 0));
+        return res.json();
       })
-      .catch(err => {
-        console.error(err);
-        setLoading(false);
+      .then(data => {
+        setGames(current => cursor ? [...current, ...(data || [])] : (data || []));
       });
+  };
+
+  useEffect(() => {
+    loadGames()
+      .catch(err => console.error(err))
+      .finally(() => setLoading(false));
   }, []);
 
   if (loading) {
@@ -65,6 +82,24 @@ export default function HomePage() {
           ))}
         </div>
       )}
+
+      {nextCursor && (
+        <div style={{ textAlign: 'center', marginTop: '30px' }}>
+          <button
+            onClick={() => loadGames(nextCursor).catch(err => console.error(err))}
+            style={{
+              backgroundColor: '#4a9eff',
+              border: 'none',
+              color: 'white',
+              padding: '10px 20px',
+              borderRadius: '8px',
+              cursor: 'pointer'
+            }}
+          >
+            Load more ({games.length} of {total})
+          </button>
+        </div>
+      )}
     </div>
   );
 }
\ No newline at end of file
@@ -0,0 +1,198 @@
+import { useState, useEffect } from 'react';
+import { useNavigate, useSearchParams } from 'react-router-dom';
+import { safeNext } from '../lib/api';
+
+type Mode = 'login' | 'register';
+
+const inputStyle = {
+  width: '100%',
+  padding: '10px',
+  borderRadius: '6px',
+  border: '1px solid #444',
+  backgroundColor: '#2d2d2d',
+  color: 'white',
+  fontSize: '16px'
+};
+
+const labelStyle = { display: 'block', color: 'white', marginBottom: '8px' };
+
+export default function LoginPage() {
+  const navigate = useNavigate();
+  const [searchParams] = useSearchParams();
+  const next = safeNext(searchParams.get('next'));
+  const [mode, setMode] = useState<Mode>('login');
+  const [email, setEmail] = useState('');
+  const [password, setPassword] = useState('');
+  const [displayName, setDisplayName] = useState('');
+  const [error, setError] = useState('');
+  const [submitting, setSubmitting] = useState(false);
+  const [oidc, setOidc] = useState(false);
+
+  // Only offer the identity provider when the server has one configured
+  useEffect(() => {
+    fetch('/api/auth/providers')
+      .then(res => (res.ok ? res.json() : { oidc: false }))
+      .then(providers => setOidc(Boolean(providers.oidc)))
+      .catch(err => console.error(err));
+  }, []);
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setSubmitting(true);
+    setError('');
+
+    const body = mode === 'login'
+      ? { email, password }
+      : { email, password, display_name: displayName };
+
+    try {
+      // The answer sets the session cookie, the API calls after it are signed in
+      const response = await fetch(`/api/auth/${mode}`, {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+        },
+        credentials: 'same-origin',
+        body: JSON.stringify(body)
+      });
+
+      if (!response.ok) {
+        const data = await response.json().catch(() => ({}));
+        throw new Error(data.error || 'Failed to sign in');
+      }
+
+      navigate(next, { replace: true });
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Failed to sign in. Please try again.');
+      setSubmitting(false);
+    }
+  };
+
+  const switchMode = () => {
+    setMode(mode === 'login' ? 'register' : 'login');
+    setError('');
+  };
+
+  return (
+    <div style={{
+      minHeight: '100vh',
+      width: '100vw',
+      display: 'flex',
+      alignItems: 'center',
+      justifyContent: 'center',
+      backgroundColor: '#1a1a1a'
+    }}>
+      <div style={{ width: '360px', backgroundColor: '#2d2d2d', borderRadius: '8px', padding: '30px' }}>
+        <h1 style={{ color: 'white', marginBottom: '30px', fontSize: '28px' }}>
+          🎲 {mode === 'login' ? 'Sign in' : 'Create an account'}
+        </h1>
+
+        {error && (
+          <div style={{
+            backgroundColor: '#ff4444',
+            color: 'white',
+            padding: '12px',
+            borderRadius: '6px',
+            marginBottom: '20px'
+          }}>
+            {error}
+          </div>
+        )}
+
+        <form onSubmit={handleSubmit}>
+          {mode === 'register' && (
+            <div style={{ marginBottom: '20px' }}>
+              <label style={labelStyle}>Name</label>
+              <input
+                type="text"
+                value={displayName}
+                onChange={e => setDisplayName(e.target.value)}
+                required
+                maxLength={100}
+                autoComplete="name"
+                style={inputStyle}
+              />
+            </div>
+          )}
+
+          <div style={{ marginBottom: '20px' }}>
+            <label style={labelStyle}>Email</label>
+            <input
+              type="email"
+              value={email}
+              onChange={e => setEmail(e.target.value)}
+              required
+              autoComplete="email"
+              style={inputStyle}
+            />
+          </div>
+
+          <div style={{ marginBottom: '20px' }}>
+            <label style={labelStyle}>Password</label>
+            <input
+              type="password"
+              value={password}
+              onChange={e => setPassword(e.target.value)}
+              required
+              minLength={mode === 'register' ? 8 : undefined}
+              autoComplete={mode === 'login' ? 'current-password' : 'new-password'}
+              style={inputStyle}
+            />
+          </div>
+
+          <button
+            type="submit"
+            disabled={submitting}
+            style={{
+              width: '100%',
+              padding: '12px 24px',
+              backgroundColor: submitting ? '#666' : '#4a9eff',
+              color: 'white',
+              border: 'none',
+              borderRadius: '6px',
+              fontSize: '16px',
+              cursor: submitting ? 'not-allowed' : 'pointer'
+            }}
+          >
+            {mode === 'login' ? 'Sign in' : 'Create account'}
+          </button>
+        </form>
+
+        {/* A full page load, the server sends the browser on to the provider */}
+        {oidc && (
+          <a
+            href="/api/auth/oidc/login"
+            style={{
+              display: 'block',
+              textAlign: 'center',
+              marginTop: '12px',
+              padding: '12px 24px',
+              border: '1px solid #4a9eff',
+              borderRadius: '6px',
+              color: '#4a9eff',
+              textDecoration: 'none'
+            }}
+          >
+            Sign in with your identity provider
+          </a>
+        )}
+
+        <button
+          type="button"
+          onClick={switchMode}
+          style={{
+            marginTop: '20px',
+            background: 'none',
+            border: 'none',
+            color: '#4a9eff',
+            cursor: 'pointer',
+            fontSize: '14px',
+            padding: 0
+          }}
+        >
+          {mode === 'login' ? 'No account yet? Create one' : 'Already have an account? Sign in'}
+        </button>
+      </div>
+    </div>
+  );
+}